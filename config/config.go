@@ -56,8 +56,48 @@ type DiceDBConfig struct {
 	LogLevel string `mapstructure:"log-level" default:"info" description:"the log level"`
 
 	EnableWatch bool `mapstructure:"enable-watch" default:"false" description:"enable support for .WATCH commands and real-time reactivity"`
-	MaxClients  int  `mapstructure:"max-clients" default:"20000" description:"the maximum number of clients to accept"`
-	NumShards   int  `mapstructure:"num-shards" default:"-1" description:"number of shards to create. defaults to number of cores"`
+
+	WatchReplayBufferSize int `mapstructure:"watch-replay-buffer-size" default:"64" description:"the number of recent updates retained per watch subscription to replay to the clients resuming it"`
+
+	WatchRetentionSec int `mapstructure:"watch-retention-sec" default:"60" description:"the number of seconds the subscriptions left without clients by disconnects are retained for the clients to resume them"`
+
+	WatchQueueSize   int    `mapstructure:"watch-queue-size" default:"1024" description:"the maximum number of updates queued for a watch connection"`
+	WatchQueuePolicy string `mapstructure:"watch-queue-policy" default:"drop-oldest" description:"the policy applied when the queue of a watch connection is full: drop-oldest, disconnect or coalesce-latest"`
+
+	KeyspaceEvents string `mapstructure:"keyspace-events" default:"set,del,expired,evicted" description:"the key events sent to the KEYS.WATCH subscribers, a comma separated list of: set, del, expired, evicted"`
+	MaxClients     int    `mapstructure:"max-clients" default:"20000" description:"the maximum number of clients to accept"`
+	NumShards      int    `mapstructure:"num-shards" default:"-1" description:"number of shards to create. defaults to number of cores"`
+
+	NumEventLoops    int `mapstructure:"num-event-loops" default:"-1" description:"number of event loops serving the client connections. defaults to number of cores"`
+	EventLoopWorkers int `mapstructure:"event-loop-workers" default:"64" description:"the number of goroutines of every event loop executing the commands of its connections, beyond which the event loop waits for one of them to be done"`
+
+	ShutdownTimeoutSec int `mapstructure:"shutdown-timeout-sec" default:"10" description:"the time (in seconds) to wait for in-flight commands to finish on shutdown"`
+
+	IdleTimeoutSec       int `mapstructure:"idle-timeout-sec" default:"1800" description:"the time (in seconds) after which a connection without any command is closed, 0 to disable"`
+	WatchPingIntervalSec int `mapstructure:"watch-ping-interval-sec" default:"300" description:"the interval (in seconds) at which idle watch connections are pinged to keep them alive, 0 to disable"`
+	WriteTimeoutMillis   int `mapstructure:"write-timeout-ms" default:"5000" description:"the time (in milliseconds) after which a client not reading its responses is disconnected, 0 to disable"`
+
+	MaxKeys   int `mapstructure:"max-keys" default:"200000000" description:"the maximum number of keys of all the shards together, every shard holding up to its share of them whatever the others hold, beyond which the shards over their share evict keys as per the eviction policy, 0 for no limit. It is a soft limit, the keys exceeding it for a while until the shards over their share evict on their next write or cron run"`
+	MaxMemory int `mapstructure:"max-memory" default:"0" description:"the maximum number of bytes the keys and values may use, split evenly across the shards, beyond which keys are evicted as per the eviction policy, 0 for no limit"`
+
+	EvictionPolicy  string `mapstructure:"eviction-policy" default:"allkeys-lru" description:"the keys evicted once a shard is full: allkeys-lru, allkeys-lfu, volatile-lru, volatile-lfu, volatile-ttl, or noeviction to reject the writes instead"`
+	EvictionSamples int    `mapstructure:"eviction-samples" default:"5" description:"the number of keys sampled to pick every key to evict, more samples evict closer to the exact policy at a higher cost"`
+	LFULogFactor    int    `mapstructure:"lfu-log-factor" default:"10" description:"the logarithmic factor of the LFU access counters, the higher the factor the more accesses it takes to grow a counter"`
+	LFUDecayTime    int    `mapstructure:"lfu-decay-time" default:"1" description:"the number of minutes of idleness after which an LFU access counter is decremented, 0 to never decay"`
+
+	HotKeysSampleRate int `mapstructure:"hotkeys-sample-rate" default:"16" description:"one in how many accesses to the keys are sampled to estimate the hot keys of HOTKEYS, 0 not to track the hot keys"`
+
+	LazyFreeLazyUserDel   bool `mapstructure:"lazyfree-lazy-user-del" default:"false" description:"free the large values deleted by DEL in the background, the way UNLINK does"`
+	LazyFreeLazyUserFlush bool `mapstructure:"lazyfree-lazy-user-flush" default:"false" description:"free the keys deleted by FLUSHDB in the background, the way FLUSHDB ASYNC does"`
+	LazyFreeLazyEviction  bool `mapstructure:"lazyfree-lazy-eviction" default:"false" description:"free the large values of the evicted keys in the background"`
+
+	KeyspaceTable string `mapstructure:"keyspace-table" default:"map" description:"the hash table the keys of a shard are held in: map, a Go map scanned through a sorted snapshot of its keys, or swiss, an open-addressing table resized a few hundred keys at a time and scanned in the order of the hashes of the keys without a snapshot"`
+
+	HashMaxListpackEntries int `mapstructure:"hash-max-listpack-entries" default:"128" description:"the number of fields up to which a hash is stored as a listpack, a packed list taking less memory than a hashtable"`
+	HashMaxListpackValue   int `mapstructure:"hash-max-listpack-value" default:"64" description:"the length in bytes of the longest field or value a hash stored as a listpack may hold"`
+	SetMaxIntsetEntries    int `mapstructure:"set-max-intset-entries" default:"512" description:"the number of members up to which a set of integers is stored as an intset"`
+	SetMaxListpackEntries  int `mapstructure:"set-max-listpack-entries" default:"128" description:"the number of members up to which a set is stored as a listpack"`
+	SetMaxListpackValue    int `mapstructure:"set-max-listpack-value" default:"64" description:"the length in bytes of the longest member a set stored as a listpack may hold"`
 
 	Engine string `mapstructure:"engine" default:"ironhawk" description:"the engine to use, values: ironhawk"`
 
@@ -11,17 +11,21 @@ const (
 	DefaultKeysLimit                 int           = 200000000
 	WatchChanBufSize                 int           = 20000
 	ShardCronFrequency               time.Duration = 1 * time.Second
+	ExpiryCronBudget                 time.Duration = 25 * time.Millisecond
+	EvictionStepSize                 int           = 16
+	EvictionCronBudget               time.Duration = 25 * time.Millisecond
+	LazyFreeThreshold                int           = 64
+	LazyFreeChunkSize                int           = 1024
+	LazyFreeQueueSize                int           = 1024
 	AdhocReqChanBufSize              int           = 20
 	EnableProfile                    bool          = false
 	WebSocketWriteResponseTimeout    time.Duration = 10 * time.Second
 	WebSocketMaxWriteResponseRetries int           = 3
 
 	KeepAlive int32 = 300
-	Timeout   int32 = 300
 
 	DefaultConnBacklogSize = 128
 
 	MaxRequestSize = 32 * 1024 * 1024 // 32 MB
 	IoBufferSize   = 16 * 1024        // 16 KB
-	IdleTimeout    = 30 * time.Minute
 )
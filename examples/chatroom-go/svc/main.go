@@ -4,50 +4,111 @@
 package svc
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
+	"net"
+	"strconv"
 
 	"github.com/dicedb/dicedb-go"
 	"github.com/dicedb/dicedb-go/wire"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
 )
 
+const (
+	host = "localhost"
+	port = 7379
+)
+
+// channel is the pub/sub channel the messages of the chat room are published to.
+const channel = "chatroom"
+
 var (
 	client *dicedb.Client
+	// clientID identifies both the command and the watch connections,
+	// the messages of the channels subscribed to with the former being
+	// delivered over the latter.
+	clientID = uuid.New().String()
 )
 
 func init() {
 	var err error
-	client, err = dicedb.NewClient("localhost", 7379)
+	client, err = dicedb.NewClient(host, port, dicedb.WithID(clientID))
 	if err != nil {
 		panic(err)
 	}
 }
-
 func SendMessage(username, message string) {
 	resp := client.Fire(&wire.Command{
-		Cmd:  "SET",
-		Args: []string{"last_message", fmt.Sprintf("%s:%s", username, message)},
+		Cmd:  "PUBLISH",
+		Args: []string{channel, fmt.Sprintf("%s:%s", username, message)},
 	})
 	if resp.Err != "" {
 		fmt.Println("error sending message:", resp.Err)
 	}
 }
 
+// Subscribe subscribes the client to the chat room. The messages
+// are delivered over the watch connection of the client.
 func Subscribe() {
 	resp := client.Fire(&wire.Command{
-		Cmd:  "GET.WATCH",
-		Args: []string{"last_message"},
+		Cmd:  "SUBSCRIBE",
+		Args: []string{channel},
 	})
 	if resp.Err != "" {
 		fmt.Println("error subscribing:", resp.Err)
 	}
 }
 
+// ListenForMessages calls onMessage with every message of the chat room. The
+// watch connection is framed, for the messages published at the same time to
+// be told apart, and carries the pings of the server along with the messages.
 func ListenForMessages(onMessage func(message string)) {
-	ch, err := client.WatchCh()
+	r, err := watch()
 	if err != nil {
 		panic(err)
 	}
-	for resp := range ch {
+	for {
+		resp := &wire.Response{}
+		if err := protodelim.UnmarshalFrom(r, resp); err != nil {
+			panic(err)
+		}
+		if resp.GetAttrs().GetFields()["channel"].GetStringValue() != channel {
+			continue
+		}
 		onMessage(resp.GetVStr())
 	}
 }
+
+// watch opens the framed watch connection of the client and returns the
+// reader of its frames.
+func watch() (*bufio.Reader, error) {
+	conn, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := proto.Marshal(&wire.Command{Cmd: "HANDSHAKE", Args: []string{clientID, "watch", "FRAMED"}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(b); err != nil {
+		return nil, err
+	}
+
+	// The response to the HANDSHAKE is not framed, the
+	// framing applies to everything after it.
+	ok, _ := proto.Marshal(&wire.Response{Value: &wire.Response_VStr{VStr: "OK"}})
+	r := bufio.NewReader(conn)
+	buf := make([]byte, len(ok))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(buf, ok) {
+		return nil, fmt.Errorf("could not complete the handshake of the watch connection")
+	}
+	return r, nil
+}
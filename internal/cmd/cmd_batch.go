@@ -0,0 +1,364 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const ATOMIC = "ATOMIC"
+
+var cBATCH = &CommandMeta{
+	Name:      "BATCH",
+	Syntax:    "BATCH [ATOMIC] numargs command [arg ...] [numargs command [arg ...] ...]",
+	HelpShort: "BATCH executes a list of commands in one request and returns the result of each command",
+	HelpLong: `
+BATCH executes a list of commands in one request and returns one response holding
+the result of every command, in the order the commands were specified.
+
+Every command in the batch is prefixed with numargs, the number of arguments the command takes,
+followed by the command name and its arguments.
+
+The result of each command is a map holding the "value" returned by the command and,
+if the command failed, the "err" describing the failure. A failing command does not stop
+the execution of the other commands in the batch.
+
+The commands are grouped by the shard owning their keys and each shard executes its
+group in one go. The commands operating on the keys of several shards, such as MGET or UNLINK,
+are executed on their own once the commands preceding them are. The commands operating on the
+same key are always executed in order.
+
+- ATOMIC: Execute the batch as a transaction. The batch is rejected as a whole if any of the
+  commands is invalid, its arguments included, and no other command is executed on the shards
+  touched by the batch until all of its commands are executed.
+
+The commands handled along with the connection, i.e. HANDSHAKE, CLIENT, the watch commands,
+UNWATCH, WATCH.LIST, WATCH.STATS, the pub/sub commands and CDC.SUBSCRIBE, as well as DEBUG,
+MEMORY and BATCH itself, cannot be executed as part of a batch.
+	`,
+	Examples: `
+localhost:7379> BATCH 2 SET k1 v1 1 GET k1 1 INCR k1
+OK
+0) value=OK
+1) value=v1
+2) err=wrongtype operation against a key holding the wrong kind of value
+localhost:7379> BATCH ATOMIC 2 SET k1 1 1 INCR k1
+OK
+0) value=OK
+1) value=2
+	`,
+	IsWrite: true,
+	Eval:    evalBATCH,
+	Execute: executeBATCH,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cBATCH)
+}
+
+// Batch is a list of commands executed as part of one BATCH request.
+type Batch struct {
+	Cmds   []*Cmd
+	Atomic bool
+
+	// wal is the log the writes of the batch are appended to. The writes
+	// of an atomic batch are appended as one entry, for them to be replayed
+	// all or none, the ones of other batches one at a time.
+	wal *CommandLog
+}
+
+// batchResult is the outcome of a single command executed in a batch.
+type batchResult struct {
+	res *CmdRes
+	err error
+}
+
+// ParseBatch parses the arguments of the BATCH command into the
+// list of the commands it holds.
+func ParseBatch(c *Cmd) (*Batch, error) {
+	b := &Batch{wal: c.WAL}
+	args := c.C.Args
+	if len(args) > 0 && strings.ToUpper(args[0]) == ATOMIC {
+		b.Atomic = true
+		args = args[1:]
+	}
+
+	for len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 || len(args) < n+2 {
+			return nil, errors.ErrInvalidSyntax("BATCH")
+		}
+
+		sub := &Cmd{
+			C:        &wire.Command{Cmd: args[1], Args: args[2 : n+2]},
+			IsReplay: c.IsReplay,
+			ClientID: c.ClientID,
+			Mode:     c.Mode,
+		}
+		if !b.Atomic {
+			sub.WAL = c.WAL
+		}
+		b.Cmds = append(b.Cmds, sub)
+		args = args[n+2:]
+	}
+
+	if len(b.Cmds) == 0 {
+		return nil, errors.ErrWrongArgumentCount("BATCH")
+	}
+	return b, nil
+}
+
+// nonBatchCmds are the commands which cannot be executed as part of a batch:
+// the ones the io-threads handle along with the connection, which would have
+// no effect in a batch, and the ones acquiring the shard locks on their own.
+var nonBatchCmds = map[string]bool{
+	"BATCH":         true,
+	"CDC.SUBSCRIBE": true,
+	"CLIENT":        true,
+	"DEBUG":         true,
+	"HANDSHAKE":     true,
+	"MEMORY":        true,
+	"PSUBSCRIBE":    true,
+	"PUBLISH":       true,
+	"PUBSUB":        true,
+	"PUNSUBSCRIBE":  true,
+	"SUBSCRIBE":     true,
+	"UNSUBSCRIBE":   true,
+	"UNWATCH":       true,
+	"WATCH.LIST":    true,
+	"WATCH.STATS":   true,
+}
+
+// validateBatchCmd checks if the command can be executed as part of a batch.
+func validateBatchCmd(c *Cmd) error {
+	if err := c.resolveMeta(); err != nil {
+		return err
+	}
+
+	name := c.Meta.Name
+	if nonBatchCmds[name] || strings.HasSuffix(name, ".WATCH") {
+		return fmt.Errorf("'%s' command cannot be executed in a batch", name)
+	}
+	return nil
+}
+
+// scratchStores are the empty stores the commands of the atomic batches are
+// checked against, pooled for a batch not to allocate a store of its own.
+var scratchStores = sync.Pool{
+	New: func() any {
+		return dstore.NewStore(nil, nil, 0)
+	},
+}
+
+// checkBatchArgs checks the arguments of the command by evaluating it alone
+// against the empty scratch store, where the command finds none of its keys
+// and hence fails only for its arguments. The scratch store is emptied after
+// the commands storing keys, for it to be reused.
+func checkBatchArgs(c *Cmd, scratch *dstore.Store) error {
+	_, err := c.Meta.Eval(c, scratch)
+	if scratch.GetKeyCount() > 0 {
+		dstore.Reset(scratch)
+	}
+	return err
+}
+
+// execute executes all the commands in the batch and returns
+// their results in the order of the commands.
+func (b *Batch) execute(sm *shardmanager.ShardManager) ([]batchResult, error) {
+	results := make([]batchResult, len(b.Cmds))
+	if b.Atomic {
+		if err := b.executeAtomic(sm, results); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	b.executeGrouped(sm, results)
+	return results, nil
+}
+
+// executeGrouped groups the commands by the shard owning their keys, as
+// described by their key spec, and executes every group in one go,
+// concurrently with the other groups. The commands operating on the keys of
+// several shards, or on none, act as a barrier: the groups collected before
+// them are executed before they are, and they are executed on their own
+// holding the locks of their shards.
+func (b *Batch) executeGrouped(sm *shardmanager.ShardManager, results []batchResult) {
+	groups := make(map[*shard.Shard][]int)
+
+	flush := func() {
+		var wg sync.WaitGroup
+		for sh, idxs := range groups {
+			wg.Add(1)
+			go func(sh *shard.Shard, idxs []int) {
+				defer wg.Done()
+				write := slices.ContainsFunc(idxs, func(i int) bool { return b.Cmds[i].Meta.IsWrite })
+				lockShard(sh, write)
+				defer unlockShard(sh, write)
+				for _, i := range idxs {
+					res, err := b.Cmds[i].execute(sm)
+					results[i] = batchResult{res, err}
+				}
+			}(sh, idxs)
+		}
+		wg.Wait()
+		groups = make(map[*shard.Shard][]int)
+	}
+
+	for i, c := range b.Cmds {
+		if err := validateBatchCmd(c); err != nil {
+			results[i] = batchResult{err: err}
+			continue
+		}
+
+		shards := c.shards(sm)
+		if len(shards) != 1 {
+			flush()
+			res, err := c.Execute(sm)
+			results[i] = batchResult{res, err}
+			continue
+		}
+
+		groups[shards[0]] = append(groups[shards[0]], i)
+	}
+	flush()
+}
+
+// executeAtomic validates all the commands upfront, their arguments
+// included, and then executes them in order while holding the write lock on
+// every shard owning their keys, as described by their key spec. Locks are acquired in the order of the shard IDs to
+// avoid deadlocks between concurrent atomic batches.
+func (b *Batch) executeAtomic(sm *shardmanager.ShardManager, results []batchResult) error {
+	involved := make(map[*shard.Shard]bool)
+	scratch := scratchStores.Get().(*dstore.Store)
+	defer scratchStores.Put(scratch)
+	for _, c := range b.Cmds {
+		if err := validateBatchCmd(c); err != nil {
+			return err
+		}
+		if err := checkBatchArgs(c, scratch); err != nil {
+			return err
+		}
+
+		for _, sh := range c.shards(sm) {
+			involved[sh] = true
+		}
+	}
+
+	shards := make([]*shard.Shard, 0, len(involved))
+	for sh := range involved {
+		shards = append(shards, sh)
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		return shards[i].ID < shards[j].ID
+	})
+
+	for _, sh := range shards {
+		sh.Lock()
+	}
+	defer func() {
+		for i := len(shards) - 1; i >= 0; i-- {
+			shards[i].Unlock()
+		}
+	}()
+
+	if err := b.wal.ready(); err != nil {
+		return err
+	}
+	for i, c := range b.Cmds {
+		res, err := c.execute(sm)
+		results[i] = batchResult{res, err}
+	}
+	return b.wal.append(b.writes(results))
+}
+
+// writes returns the atomic batch of the writes executed successfully, which
+// is the entry of the batch in the WAL, nil if there is none.
+func (b *Batch) writes(results []batchResult) *wire.Command {
+	args := []string{ATOMIC}
+	for i, c := range b.Cmds {
+		if c.Meta.IsWrite && results[i].err == nil {
+			args = append(args, strconv.Itoa(len(c.C.Args)), c.C.Cmd)
+			args = append(args, c.C.Args...)
+		}
+	}
+	if len(args) == 1 {
+		return nil
+	}
+	return &wire.Command{Cmd: "BATCH", Args: args}
+}
+
+// Note: BATCH spans shards and hence is never evaluated against a single store.
+func evalBATCH(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	return cmdResNil, errors.ErrGeneral("BATCH cannot be evaluated against a single shard")
+}
+
+func executeBATCH(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	b, err := ParseBatch(c)
+	if err != nil {
+		return cmdResNil, err
+	}
+
+	results, err := b.execute(sm)
+	if err != nil {
+		return cmdResNil, err
+	}
+
+	values := make([]*structpb.Value, len(results))
+	for i, r := range results {
+		fields := map[string]*structpb.Value{}
+		if r.err != nil {
+			fields["err"] = structpb.NewStringValue(r.err.Error())
+		}
+		if r.res != nil && r.err == nil {
+			fields["value"] = responseValue(r.res.R)
+		}
+		values[i] = structpb.NewStructValue(&structpb.Struct{Fields: fields})
+	}
+
+	return &CmdRes{R: &wire.Response{
+		VList: values,
+	}}, nil
+}
+
+// responseValue converts the value held by the response into a structpb.Value.
+func responseValue(r *wire.Response) *structpb.Value {
+	if r.VList != nil {
+		return structpb.NewListValue(&structpb.ListValue{Values: r.VList})
+	}
+
+	if r.VSsMap != nil {
+		fields := make(map[string]*structpb.Value, len(r.VSsMap))
+		for k, v := range r.VSsMap {
+			fields[k] = structpb.NewStringValue(v)
+		}
+		return structpb.NewStructValue(&structpb.Struct{Fields: fields})
+	}
+
+	switch v := r.Value.(type) {
+	case *wire.Response_VInt:
+		return structpb.NewNumberValue(float64(v.VInt))
+	case *wire.Response_VFloat:
+		return structpb.NewNumberValue(v.VFloat)
+	case *wire.Response_VStr:
+		return structpb.NewStringValue(v.VStr)
+	case *wire.Response_VBytes:
+		return structpb.NewStringValue(base64.StdEncoding.EncodeToString(v.VBytes))
+	default:
+		return structpb.NewNullValue()
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var cBIGKEYS = &CommandMeta{
+	Name:      "BIGKEYS",
+	Syntax:    "BIGKEYS [COUNT n]",
+	HelpShort: "BIGKEYS returns the largest keys of every type",
+	HelpLong: `
+BIGKEYS returns the n largest keys of every type of all the shards, 1 by default, by type and
+by decreasing size. Every key is returned along with
+- type: the type of the value of the key
+- key: the key
+- size: the estimated number of bytes of the key and its value, see MEMORY USAGE
+- shard: the shard holding the key
+
+The largest keys of every type are kept by the shards as the keys are written, hence BIGKEYS
+does not scan the keys. A key deleted leaves room for the keys written afterwards, such that a
+key left untouched since a larger key of its type was deleted may be missing.
+	`,
+	Examples: `
+localhost:7379> SET k1 v1
+OK OK
+localhost:7379> SET k2 vvvvvvvvvvvvvvvvvvvv
+OK OK
+localhost:7379> HSET k3 f1 v1
+OK 1
+localhost:7379> BIGKEYS
+OK
+0) key=k3 shard=0 size=122 type=ssmap
+1) key=k2 shard=0 size=118 type=string
+	`,
+	KeySpec: allShards,
+	Eval:    evalBIGKEYS,
+	Execute: executeBIGKEYS,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cBIGKEYS)
+}
+
+// shardBigKey is a large key along with the shard holding it.
+type shardBigKey struct {
+	dstore.BigKey
+	shard int
+}
+
+// bigKeys returns the response to BIGKEYS for the stores of the shards.
+func bigKeys(c *Cmd, stores []*dstore.Store) (*CmdRes, error) {
+	n, err := parseCount(c, 1)
+	if err != nil {
+		return cmdResNil, err
+	}
+
+	var keys []shardBigKey
+	for _, s := range stores {
+		for _, k := range s.BigKeys(n) {
+			keys = append(keys, shardBigKey{BigKey: k, shard: s.ShardID})
+		}
+	}
+	slices.SortFunc(keys, func(a, b shardBigKey) int {
+		return cmp.Or(cmp.Compare(a.Type.String(), b.Type.String()), cmp.Compare(b.Size, a.Size), cmp.Compare(a.Key, b.Key))
+	})
+
+	values := []*structpb.Value{}
+	for i, k := range keys {
+		// The keys of every type are the n largest of all the shards.
+		if i >= n && keys[i-n].Type == k.Type {
+			continue
+		}
+		values = append(values, structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+			"type":  structpb.NewStringValue(k.Type.String()),
+			"key":   structpb.NewStringValue(k.Key),
+			"size":  structpb.NewNumberValue(float64(k.Size)),
+			"shard": structpb.NewNumberValue(float64(k.shard)),
+		}}))
+	}
+	return &CmdRes{R: &wire.Response{VList: values}}, nil
+}
+
+func evalBIGKEYS(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	return bigKeys(c, []*dstore.Store{s})
+}
+
+func executeBIGKEYS(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	stores := make([]*dstore.Store, 0, len(sm.Shards()))
+	for _, shard := range sm.Shards() {
+		stores = append(stores, shard.Thread.Store())
+	}
+	return bigKeys(c, stores)
+}
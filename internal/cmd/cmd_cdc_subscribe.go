@@ -0,0 +1,123 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+const (
+	FROM      = "FROM"
+	POSTIMAGE = "POSTIMAGE"
+)
+
+var cCDCSUBSCRIBE = &CommandMeta{
+	Name:      "CDC.SUBSCRIBE",
+	Syntax:    "CDC.SUBSCRIBE [FROM lsn] [POSTIMAGE]",
+	HelpShort: "CDC.SUBSCRIBE streams the committed mutations as change records",
+	HelpLong: `
+CDC.SUBSCRIBE streams every mutation committed to the database, on a connection in watch mode,
+as a change record. The change stream is read from the write-ahead log, hence it requires the WAL
+to be enabled, see enable-wal.
+
+Every record is sent as a response whose attributes are
+
+- lsn: the log sequence number of the mutation in the WAL, as a string
+- timestamp: the time the mutation was logged, in milliseconds since the epoch
+- command and args: the command of the mutation and its arguments
+- key: the key mutated, the commands mutating several keys, including BATCH, are sent as one record per key
+- shard: the ID of the shard holding the key
+
+With POSTIMAGE, the value of the key is sent along with every live record, as returned by GET, or
+HGETALL for hashes, once the mutation is committed. The records replayed from the WAL have no value.
+
+With FROM, the stream starts after the record with the given LSN, replaying the records retained in
+the WAL before streaming the live ones, so that a consumer can resume from the last LSN it processed
+after a disconnect or a downtime. The WAL retains the records as per its retention settings, see
+wal-retention-mode; if the records following the given LSN are no longer retained, the stream
+starts with an error record holding the oldest LSN retained. Without FROM, only the records of the
+mutations committed after the command are sent.
+
+A consumer not keeping up with the stream such that watch-queue-size records are waiting to be
+written to its connection is disconnected, and is expected to resume from the last LSN it received.
+So is a consumer replaying the WAL while 65536 new entries are logged, and so are all the consumers
+if the server falls behind the WAL by 65536 entries.
+	`,
+	Examples: `
+localhost:7379> CDC.SUBSCRIBE FROM 41 POSTIMAGE
+OK
+lsn=42 timestamp=1735689600000 command=SET args=["k1","v1"] key=k1 shard=2 OK v1
+	`,
+	KeySpec: noKeys,
+	Eval:    evalCDCSUBSCRIBE,
+	Execute: executeCDCSUBSCRIBE,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cCDCSUBSCRIBE)
+}
+
+// CDCSubscription is the position and the options of a CDC.SUBSCRIBE stream.
+type CDCSubscription struct {
+	// From is the LSN the stream starts after, and HasFrom is false if
+	// the stream starts with the mutations committed after the command.
+	From    uint64
+	HasFrom bool
+	// PostImage is true if the records hold the value of their key.
+	PostImage bool
+}
+
+// ParseCDCSubscribe parses the arguments of the CDC.SUBSCRIBE command.
+func ParseCDCSubscribe(c *Cmd) (*CDCSubscription, error) {
+	s := &CDCSubscription{}
+	args := c.C.Args
+	for i := 0; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case FROM:
+			if s.HasFrom || i+1 >= len(args) {
+				return nil, errors.ErrInvalidSyntax("CDC.SUBSCRIBE")
+			}
+			lsn, err := strconv.ParseUint(args[i+1], 10, 64)
+			if err != nil {
+				return nil, errors.ErrInvalidValue("CDC.SUBSCRIBE", FROM)
+			}
+			s.From, s.HasFrom = lsn, true
+			i++
+		case POSTIMAGE:
+			if s.PostImage {
+				return nil, errors.ErrInvalidSyntax("CDC.SUBSCRIBE")
+			}
+			s.PostImage = true
+		default:
+			return nil, errors.ErrInvalidSyntax("CDC.SUBSCRIBE")
+		}
+	}
+	return s, nil
+}
+
+// Note: We only validate the command here, because the CDC.SUBSCRIBE
+// command is handled by the iothread.
+func evalCDCSUBSCRIBE(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if c.Mode != "watch" {
+		return cmdResNil, errors.ErrWatchModeRequired
+	}
+	if !config.Config.EnableWAL {
+		return cmdResNil, errors.ErrWALDisabled
+	}
+	if _, err := ParseCDCSubscribe(c); err != nil {
+		return cmdResNil, err
+	}
+	return cmdResOK, nil
+}
+
+func executeCDCSUBSCRIBE(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	shard := sm.GetShardForKey("-")
+	return evalCDCSUBSCRIBE(c, shard.Thread.Store())
+}
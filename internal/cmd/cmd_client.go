@@ -0,0 +1,74 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+const NOEVICT = "NO-EVICT"
+
+var cCLIENT = &CommandMeta{
+	Name:      "CLIENT",
+	Syntax:    "CLIENT NO-EVICT ON|OFF",
+	HelpShort: "CLIENT manages the properties of the current connection",
+	HelpLong: `
+CLIENT manages the properties of the connection the command is sent on.
+
+- NO-EVICT ON|OFF: When turned ON, the connection is never closed for being idle.
+  By default, a connection that does not send any command for idle-timeout-sec
+  seconds is closed by the server. Watch connections are never considered idle
+  given that the server keeps them alive by pinging them.
+
+The command returns OK.
+	`,
+	Examples: `
+localhost:7379> CLIENT NO-EVICT ON
+OK OK
+localhost:7379> CLIENT NO-EVICT OFF
+OK OK
+	`,
+	KeySpec: noKeys,
+	Eval:    evalCLIENT,
+	Execute: executeCLIENT,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cCLIENT)
+}
+
+func evalCLIENT(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) != 2 {
+		return cmdResNil, errors.ErrWrongArgumentCount("CLIENT")
+	}
+	if _, ok := ClientNoEvict(c.C); !ok {
+		return cmdResNil, errors.ErrInvalidSyntax("CLIENT")
+	}
+	return cmdResOK, nil
+}
+
+func executeCLIENT(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	shard := sm.GetShardForKey("-")
+	return evalCLIENT(c, shard.Thread.Store())
+}
+
+// ClientNoEvict returns the value of the NO-EVICT flag set by the
+// CLIENT command and false if the command does not set the flag.
+func ClientNoEvict(c *wire.Command) (on, ok bool) {
+	if len(c.Args) != 2 || strings.ToUpper(c.Args[0]) != NOEVICT {
+		return false, false
+	}
+	switch strings.ToUpper(c.Args[1]) {
+	case "ON":
+		return true, true
+	case "OFF":
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+const (
+	OBJECT = "OBJECT"
+	SLEEP  = "SLEEP"
+	RELOAD = "RELOAD"
+)
+
+var cDEBUG = &CommandMeta{
+	Name:      "DEBUG",
+	Syntax:    "DEBUG OBJECT key | DEBUG SLEEP seconds | DEBUG RELOAD",
+	HelpShort: "DEBUG inspects the keys and exercises the server, for testing",
+	HelpLong: `
+DEBUG OBJECT returns the details of the value of the key, as space-separated fields
+- type: the type of the value
+- encoding: the encoding the value is stored as, see OBJECT ENCODING
+- serializedlength: the number of bytes of the value once serialized
+- lru_seconds_idle: the number of seconds since the key was last accessed
+- ttl: the number of seconds before the key expires, -1 if it has no expiry
+
+Returns (nil) if the key does not exist.
+
+DEBUG SLEEP blocks the client for the given number of seconds, a fraction of a second allowed.
+The other clients are not held up, the client sleeping without holding any shard.
+
+DEBUG RELOAD serializes all the keys of all the shards, then empties the shards and restores the
+keys from their serialized form, along with their expiry. The keys are left untouched if a value
+fails to serialize. The time of the last access and the access counter of the keys are reset.
+	`,
+	Examples: `
+localhost:7379> HSET k1 f1 v1
+OK 1
+localhost:7379> DEBUG OBJECT k1
+OK type:ssmap encoding:listpack serializedlength:31 lru_seconds_idle:0 ttl:-1
+localhost:7379> DEBUG SLEEP 0.5
+OK OK
+localhost:7379> DEBUG RELOAD
+OK OK
+localhost:7379> HGET k1 f1
+OK v1
+	`,
+	KeySpec: noKeys,
+	Eval:    evalDEBUG,
+	Execute: executeDEBUG,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cDEBUG)
+}
+
+// debug returns the response to DEBUG for the stores of the shards, the
+// store holding the key for DEBUG OBJECT.
+func debug(c *Cmd, stores []*dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) == 0 {
+		return cmdResNil, errors.ErrWrongArgumentCount("DEBUG")
+	}
+
+	switch strings.ToUpper(c.C.Args[0]) {
+	case OBJECT:
+		if len(c.C.Args) != 2 {
+			return cmdResNil, errors.ErrWrongArgumentCount("DEBUG OBJECT")
+		}
+		return debugObject(c.C.Args[1], stores[0])
+	case SLEEP:
+		if len(c.C.Args) != 2 {
+			return cmdResNil, errors.ErrWrongArgumentCount("DEBUG SLEEP")
+		}
+		seconds, err := strconv.ParseFloat(c.C.Args[1], 64)
+		if err != nil || seconds < 0 {
+			return cmdResNil, errors.ErrInvalidValue("DEBUG SLEEP", "seconds")
+		}
+		time.Sleep(time.Duration(seconds * float64(time.Second)))
+		return cmdResOK, nil
+	case RELOAD:
+		if len(c.C.Args) != 1 {
+			return cmdResNil, errors.ErrWrongArgumentCount("DEBUG RELOAD")
+		}
+		if err := debugReload(stores); err != nil {
+			return cmdResNil, err
+		}
+		return cmdResOK, nil
+	default:
+		return cmdResNil, errors.ErrInvalidSyntax("DEBUG")
+	}
+}
+
+func debugObject(key string, s *dstore.Store) (*CmdRes, error) {
+	obj := s.GetNoTouch(key)
+	if obj == nil {
+		return cmdResNil, nil
+	}
+	data, err := dumpObj(obj)
+	if err != nil {
+		return cmdResNil, err
+	}
+
+	ttl := INFINITE_EXPIRATION
+	if exp, ok := dstore.GetExpiry(obj, s); ok {
+		ttl = 0
+		if now := uint64(utils.GetCurrentTime().UnixMilli()); exp > now {
+			ttl = int64(exp-now) / 1000
+		}
+	}
+	return &CmdRes{R: &wire.Response{
+		Value: &wire.Response_VStr{VStr: fmt.Sprintf("type:%s encoding:%s serializedlength:%d lru_seconds_idle:%d ttl:%d",
+			obj.Type, object.GetEncoding(obj), len(data), dstore.GetIdleTime(dstore.LastAccessedAt(obj)), ttl)},
+	}}, nil
+}
+
+// dumpedKey is a key serialized by DEBUG RELOAD, along with the time left
+// before it expires, -1 for none.
+type dumpedKey struct {
+	key   string
+	data  []byte
+	expMs int64
+}
+
+// debugReload serializes the keys of all the stores, then resets the stores
+// and restores the keys, such that a value failing to serialize leaves all
+// the stores untouched. The caller holds the write locks of the shards of the stores.
+func debugReload(stores []*dstore.Store) error {
+	now := uint64(utils.GetCurrentTime().UnixMilli())
+	dumped := make([][]dumpedKey, len(stores))
+	for i, s := range stores {
+		var err error
+		s.GetStore().All(func(k string, obj *object.Obj) bool {
+			expMs := INFINITE_EXPIRATION
+			if exp, ok := dstore.GetExpiry(obj, s); ok {
+				if exp <= now {
+					return true
+				}
+				expMs = int64(exp - now)
+			}
+			var data []byte
+			if data, err = dumpObj(obj); err != nil {
+				return false
+			}
+			dumped[i] = append(dumped[i], dumpedKey{key: k, data: data, expMs: expMs})
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, s := range stores {
+		dstore.Reset(s)
+		for _, d := range dumped[i] {
+			obj, err := restoreObj(d.data)
+			if err != nil {
+				return err
+			}
+			s.Put(d.key, s.NewObj(obj.Value, d.expMs, obj.Type))
+		}
+	}
+	return nil
+}
+
+func evalDEBUG(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	return debug(c, []*dstore.Store{s})
+}
+
+// executeDEBUG acquires the shard locks on its own, DEBUG having no key spec
+// for its key to be told from its subcommand: DEBUG OBJECT holds the read
+// lock of the shard of the key, DEBUG RELOAD the write locks of all the
+// shards, in the order of their IDs, and DEBUG SLEEP no lock at all.
+func executeDEBUG(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	if len(c.C.Args) == 0 {
+		return debug(c, nil)
+	}
+
+	switch strings.ToUpper(c.C.Args[0]) {
+	case OBJECT:
+		if len(c.C.Args) != 2 {
+			return debug(c, nil)
+		}
+		shard := sm.GetShardForKey(c.C.Args[1])
+		shard.RLock()
+		defer shard.RUnlock()
+		return evalDEBUG(c, shard.Thread.Store())
+	case RELOAD:
+		shards := sm.Shards()
+		for _, shard := range shards {
+			shard.Lock()
+		}
+		defer func() {
+			for i := len(shards) - 1; i >= 0; i-- {
+				shards[i].Unlock()
+			}
+		}()
+	}
+
+	stores := make([]*dstore.Store, 0, len(sm.Shards()))
+	for _, shard := range sm.Shards() {
+		stores = append(stores, shard.Thread.Store())
+	}
+	return debug(c, stores)
+}
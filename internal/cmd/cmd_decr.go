@@ -25,6 +25,8 @@ OK OK
 localhost:7379> DECR k
 OK 42
 	`,
+	IsWrite: true,
+	DenyOOM: true,
 	Eval:    evalDECR,
 	Execute: executeDECR,
 }
@@ -27,6 +27,8 @@ OK OK
 localhost:7379> DECRBY k 10
 OK 33
 	`,
+	IsWrite: true,
+	DenyOOM: true,
 	Eval:    evalDECRBY,
 	Execute: executeDECRBY,
 }
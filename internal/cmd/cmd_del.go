@@ -4,6 +4,7 @@
 package cmd
 
 import (
+	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/shardmanager"
 	dstore "github.com/dicedb/dice/internal/store"
@@ -14,7 +15,12 @@ var cDEL = &CommandMeta{
 	Name:      "DEL",
 	Syntax:    "DEL key [key ...]",
 	HelpShort: "DEL deletes all the specified keys",
-	HelpLong:  `DEL command deletes all the specified keys and returns the number of keys deleted on success.`,
+	HelpLong: `
+DEL command deletes all the specified keys and returns the number of keys deleted on success.
+
+The values are freed on the command path, unless lazyfree-lazy-user-del is set, in which case DEL
+frees the large values in the background the way UNLINK does.
+	`,
 	Examples: `
 	localhost:7379> SET k1 v1
 OK OK
@@ -22,6 +28,8 @@ localhost:7379> SET k2 v2
 OK OK
 localhost:7379> DEL k1 k2 k3
 OK 2`,
+	IsWrite: true,
+	KeySpec: KeySpec{Last: -1},
 	Eval:    evalDEL,
 	Execute: executeDEL,
 }
@@ -49,7 +57,7 @@ func evalDEL(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 
 	var count int
 	for _, key := range c.C.Args {
-		if ok := s.Del(key); ok {
+		if ok := s.Del(key, dstore.WithLazyFree(config.Config.LazyFreeLazyUserDel)); ok {
 			count++
 		}
 	}
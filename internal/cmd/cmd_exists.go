@@ -24,8 +24,10 @@ OK
 localhost:7379> EXISTS k1 k2 k3
 OK 2
 	`,
-	Eval:    evalEXISTS,
-	Execute: executeEXISTS,
+	KeySpec:     KeySpec{Last: -1},
+	IsWatchable: true,
+	Eval:        evalEXISTS,
+	Execute:     executeEXISTS,
 }
 
 func init() {
@@ -37,7 +39,7 @@ func init() {
 
 func evalEXISTS(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 	if len(c.C.Args) < 1 {
-		return cmdResNil, errors.ErrWrongArgumentCount("EXISTS")
+		return cmdResNil, errors.ErrWrongArgumentCount(c.C.Cmd)
 	}
 
 	var count int64
@@ -57,7 +59,7 @@ func evalEXISTS(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 
 func executeEXISTS(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
 	if len(c.C.Args) < 1 {
-		return cmdResNil, errors.ErrWrongArgumentCount("EXISTS")
+		return cmdResNil, errors.ErrWrongArgumentCount(c.C.Cmd)
 	}
 	var count int64
 	var shardMap = make(map[*shard.Shard][]string)
@@ -67,8 +69,7 @@ func executeEXISTS(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
 	}
 
 	for shard, keys := range shardMap {
-		c.C.Args = keys
-		r, err := evalEXISTS(c, shard.Thread.Store())
+		r, err := evalEXISTS(&Cmd{C: &wire.Command{Cmd: c.C.Cmd, Args: keys}}, shard.Thread.Store())
 		if err != nil {
 			return nil, err
 		}
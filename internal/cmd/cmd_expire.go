@@ -40,6 +40,7 @@ OK 1
 locahost:7379> EXPIRE k2 20 NX
 OK 0
 	`,
+	IsWrite: true,
 	Eval:    evalEXPIRE,
 	Execute: executeEXPIRE,
 }
@@ -40,6 +40,7 @@ OK 0
 locahost:7379> EXPIREAT k1 1740829942 LT
 OK 1
 	`,
+	IsWrite: true,
 	Eval:    evalEXPIREAT,
 	Execute: executeEXPIREAT,
 }
@@ -28,8 +28,10 @@ OK 1
 locahost:7379> EXPIRETIME k1
 OK 1740829178
 	`,
-	Eval:    evalEXPIRETIME,
-	Execute: executeEXPIRETIME,
+	IsWatchable: true,
+	Arity:       1,
+	Eval:        evalEXPIRETIME,
+	Execute:     executeEXPIRETIME,
 }
 
 func init() {
@@ -66,7 +68,7 @@ func evalEXPIRETIME(c *Cmd, dst *dstore.Store) (*CmdRes, error) {
 
 func executeEXPIRETIME(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
 	if len(c.C.Args) != 1 {
-		return cmdResNil, errors.ErrWrongArgumentCount("EXPIRETIME")
+		return cmdResNil, errors.ErrWrongArgumentCount(c.C.Cmd)
 	}
 	shard := sm.GetShardForKey(c.C.Args[0])
 	return evalEXPIRETIME(c, shard.Thread.Store())
@@ -4,17 +4,30 @@
 package cmd
 
 import (
+	"strings"
+
+	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/shardmanager"
 	"github.com/dicedb/dice/internal/store"
 )
 
+const (
+	ASYNC = "ASYNC"
+	SYNC  = "SYNC"
+)
+
 var cFLUSHDB = &CommandMeta{
 	Name:      "FLUSHDB",
-	Syntax:    "FLUSHDB",
+	Syntax:    "FLUSHDB [ASYNC | SYNC]",
 	HelpShort: "FLUSHDB deletes all keys.",
 	HelpLong: `
 FLUSHDB deletes all keys present in the database.
+
+With ASYNC, the keys are removed from the database at once and their values are freed in the
+background, in chunks. With SYNC, they are freed on the command path. Without either, FLUSHDB is
+asynchronous if lazyfree-lazy-user-flush is set. The values not freed yet are reported by INFO
+memory.
 	`,
 	Examples: `
 locahost:7379> SET k1 v1
@@ -27,7 +40,11 @@ localhost:7379> GET k1
 OK (nil)
 localhost:7379> GET k2
 OK (nil)
+localhost:7379> FLUSHDB ASYNC
+OK OK
 	`,
+	IsWrite: true,
+	KeySpec: allShards,
 	Eval:    evalFLUSHDB,
 	Execute: executeFLUSHDB,
 }
@@ -37,7 +54,7 @@ func init() {
 }
 
 // FLUSHDB deletes all keys.
-// The function expects no arguments
+// The function expects either no argument, ASYNC or SYNC
 //
 // Parameters:
 //   - c *Cmd: The command context containing the arguments
@@ -45,13 +62,29 @@ func init() {
 //
 // Returns:
 //   - *CmdRes: OK or nil
-//   - error: Error if wrong number of arguments
+//   - error: Error if wrong number of arguments or an invalid option
 func evalFLUSHDB(c *Cmd, s *store.Store) (*CmdRes, error) {
-	if len(c.C.Args) != 0 {
+	if len(c.C.Args) > 1 {
 		return cmdResNil, errors.ErrWrongArgumentCount("FLUSHDB")
 	}
 
-	store.Reset(s)
+	async := config.Config.LazyFreeLazyUserFlush
+	if len(c.C.Args) == 1 {
+		switch strings.ToUpper(c.C.Args[0]) {
+		case ASYNC:
+			async = true
+		case SYNC:
+			async = false
+		default:
+			return cmdResNil, errors.ErrInvalidSyntax("FLUSHDB")
+		}
+	}
+
+	if async {
+		store.ResetAsync(s)
+	} else {
+		store.Reset(s)
+	}
 	return cmdResOK, nil
 }
 
@@ -29,6 +29,23 @@ localhost:7379> GET k1
 OK v1
 localhost:7379> GET k2
 (nil)
+	`,
+	IsWatchable: true,
+	Arity:       1,
+	WatchExamples: `
+client1:7379> SET k1 v1
+OK OK
+client1:7379> GET.WATCH k1
+entered the watch mode for GET.WATCH k1
+
+
+client2:7379> SET k1 v2
+OK OK
+
+
+client1:7379> ...
+entered the watch mode for GET.WATCH k1
+OK [fingerprint=2356444921] v2
 	`,
 	Eval:    evalGET,
 	Execute: executeGET,
@@ -40,7 +57,7 @@ func init() {
 
 func evalGET(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 	if len(c.C.Args) != 1 {
-		return cmdResNil, errors.ErrWrongArgumentCount("GET")
+		return cmdResNil, errors.ErrWrongArgumentCount(c.C.Cmd)
 	}
 	key := c.C.Args[0]
 	obj := s.Get(key)
@@ -50,7 +67,7 @@ func evalGET(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 
 func executeGET(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
 	if len(c.C.Args) != 1 {
-		return cmdResNil, errors.ErrWrongArgumentCount("GET")
+		return cmdResNil, errors.ErrWrongArgumentCount(c.C.Cmd)
 	}
 	shard := sm.GetShardForKey(c.C.Args[0])
 	return evalGET(c, shard.Thread.Store())
@@ -28,6 +28,7 @@ OK v
 localhost:7379> GET k
 (nil)
 	`,
+	IsWrite: true,
 	Eval:    evalGETDEL,
 	Execute: executeGETDEL,
 }
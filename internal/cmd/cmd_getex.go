@@ -50,6 +50,7 @@ OK v
 localhost:7379> GET k
 (nil)
 	`,
+	IsWrite: true,
 	Eval:    evalGETEX,
 	Execute: executeGETEX,
 }
@@ -161,7 +162,7 @@ func evalGETEX(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 	if params[PERSIST] != "" {
 		dstore.DelExpiry(existingObj, s)
 	} else if exDurationMs != -1 {
-		s.SetExpiry(existingObj, exDurationMs)
+		s.SetKeyExpiry(key, existingObj, exDurationMs)
 	}
 
 	return resp, nil
@@ -4,14 +4,23 @@
 package cmd
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/shardmanager"
 	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+const (
+	FRAMED = "FRAMED"
+	RESUME = "RESUME"
 )
 
 var cHANDSHAKE = &CommandMeta{
 	Name:      "HANDSHAKE",
-	Syntax:    "HANDSHAKE client_id execution_mode",
+	Syntax:    "HANDSHAKE client_id execution_mode [FRAMED] [RESUME fingerprint:seq [fingerprint:seq ...]]",
 	HelpShort: "HANDSHAKE tells the server the purpose of the connection",
 	HelpLong: `
 HANDSHAKE is used to tell the DiceDB server the purpose of the connection. It
@@ -25,11 +34,46 @@ The execution_mode is the mode of the connection, it can be one of the following
 1. "command" - The client will send commands to the server and receive responses.
 2. "watch" - The connection in the watch mode will be used to receive the responses of query subscriptions.
 
+A connection in the command mode that does not send any command for idle-timeout-sec seconds
+is closed by the server, see CLIENT NO-EVICT. A connection in the watch mode is never closed
+for being idle, instead the server sends it a PING response, without a fingerprint and with the type
+attribute set to ping, whenever nothing was sent on it for watch-ping-interval-sec seconds.
+
+If FRAMED is passed, then every command and response exchanged on the connection after
+the HANDSHAKE response is prefixed with its length encoded as a varint. This allows clients
+to pipeline commands, i.e. send multiple commands without waiting for their responses.
+The server processes pipelined commands in order and responds in the same order.
+The HANDSHAKE command and its response are always unframed. The client must wait for the
+HANDSHAKE response before sending the first framed command, as the server reads anything sent
+along with the HANDSHAKE as part of it.
+
+Every update sent on a connection in the watch mode carries the fingerprint of the subscription and
+a sequence number, increasing with every update of the subscription, in its attributes. A client
+reconnecting after losing its watch connection can pass RESUME along with the fingerprint and the
+sequence number of the last update it received for each of its subscriptions. The server then
+resubscribes the connection and sends the updates the client missed, in order. If the missed updates
+are no longer retained, see watch-replay-buffer-size, the server instead sends the current output of
+the subscription with the refresh attribute set, or an error if the subscription is unknown, in
+which case the client needs to re-issue the .WATCH command. A subscription left without clients by
+disconnects is retained for watch-retention-sec seconds for its clients to resume it. As the updates
+follow the HANDSHAKE response right away, RESUME is meant to be used along with FRAMED, the client
+reading exactly the bytes of the OK response before reading the frames.
+
+The updates are queued for every watch connection, up to watch-queue-size of them. A client not
+keeping up with the updates fills its queue, in which case the server applies watch-queue-policy:
+drop-oldest drops the oldest update, coalesce-latest replaces the queued update of the same
+subscription, if any, and disconnect closes the connection. A gap in the sequence numbers of a
+subscription tells the client it missed updates.
+
 If you use DiceDB SDK or CLI then this HANDSHAKE command is automatically sent when the connection is established
 or when you establish a subscription.
 	`,
 	Examples: `
 localhost:7379> HANDSHAKE 4c9d0411-6b28-4ee5-b78a-e7e258afa52f command
+OK OK
+localhost:7379> HANDSHAKE 4c9d0411-6b28-4ee5-b78a-e7e258afa52f command FRAMED
+OK OK
+localhost:7379> HANDSHAKE 4c9d0411-6b28-4ee5-b78a-e7e258afa52f watch RESUME 2356444921:42
 OK OK
 	`,
 	Eval:    evalHANDSHAKE,
@@ -41,11 +85,12 @@ func init() {
 }
 
 func evalHANDSHAKE(c *Cmd, s *dstore.Store) (*CmdRes, error) {
-	if len(c.C.Args) != 2 {
-		return cmdResNil, errors.ErrWrongArgumentCount("HANDSHAKE")
+	h, err := ParseHandshake(c.C)
+	if err != nil {
+		return cmdResNil, err
 	}
-	c.ClientID = c.C.Args[0]
-	c.Mode = c.C.Args[1]
+	c.ClientID = h.ClientID
+	c.Mode = h.Mode
 	return cmdResOK, nil
 }
 
@@ -53,3 +98,77 @@ func executeHANDSHAKE(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
 	shard := sm.GetShardForKey("-")
 	return evalHANDSHAKE(c, shard.Thread.Store())
 }
+
+// Handshake holds the options of the HANDSHAKE command.
+type Handshake struct {
+	ClientID string
+	Mode     string
+	Framed   bool
+
+	// Resume holds the subscriptions the watch connection resumes.
+	Resume []ResumePoint
+}
+
+// ResumePoint identifies the last update of a subscription a client received.
+type ResumePoint struct {
+	Fingerprint uint32
+	Seq         uint64
+}
+
+// ParseHandshake parses the options of the HANDSHAKE command.
+func ParseHandshake(c *wire.Command) (*Handshake, error) {
+	args := c.Args
+	if len(args) < 2 {
+		return nil, errors.ErrWrongArgumentCount("HANDSHAKE")
+	}
+	h := &Handshake{ClientID: args[0], Mode: args[1]}
+
+	i := 2
+	if i < len(args) && strings.ToUpper(args[i]) == FRAMED {
+		h.Framed = true
+		i++
+	}
+	if i < len(args) && strings.ToUpper(args[i]) == RESUME {
+		if h.Mode != "watch" {
+			return nil, errors.ErrGeneral("RESUME is only supported in the watch mode")
+		}
+		if i++; i == len(args) {
+			return nil, errors.ErrWrongArgumentCount("HANDSHAKE")
+		}
+		for ; i < len(args); i++ {
+			p, ok := parseResumePoint(args[i])
+			if !ok {
+				return nil, errors.ErrInvalidSyntax("HANDSHAKE")
+			}
+			h.Resume = append(h.Resume, p)
+		}
+	}
+	if i != len(args) {
+		return nil, errors.ErrInvalidSyntax("HANDSHAKE")
+	}
+	return h, nil
+}
+
+// parseResumePoint parses the resume point given as fingerprint:seq.
+func parseResumePoint(s string) (ResumePoint, bool) {
+	fp, seq, ok := strings.Cut(s, ":")
+	if !ok {
+		return ResumePoint{}, false
+	}
+	_fp, err := strconv.ParseUint(fp, 10, 32)
+	if err != nil {
+		return ResumePoint{}, false
+	}
+	_seq, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return ResumePoint{}, false
+	}
+	return ResumePoint{Fingerprint: uint32(_fp), Seq: _seq}, true
+}
+
+// IsFramedHandshake returns true if the HANDSHAKE command
+// negotiates the length-prefixed framing for the connection.
+func IsFramedHandshake(c *wire.Command) bool {
+	h, err := ParseHandshake(c)
+	return err == nil && h.Framed
+}
@@ -28,6 +28,23 @@ localhost:7379> HGET k2 f1
 OK (nil)
 localhost:7379> HGET k1 f2
 OK (nil)
+	`,
+	IsWatchable: true,
+	Arity:       2,
+	WatchExamples: `
+client1:7379>  HSET k1 f1 v1
+OK 1
+client1:7379> HGET.WATCH k1 f1
+entered the watch mode for HGET.WATCH k1 f1
+
+
+client2:7379>  HSET k1 f1 v2
+OK 0
+
+
+client1:7379> ...
+entered the watch mode for HGET.WATCH k1 f1
+OK [fingerprint=3432795955] v2
 	`,
 	Eval:    evalHGET,
 	Execute: executeHGET,
@@ -45,7 +62,7 @@ func evalHGET(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 		return cmdResNil, nil
 	}
 
-	m, ok := obj.Value.(SSMap)
+	m, ok := obj.Value.(*SSMap)
 	if !ok {
 		return cmdResNil, errors.ErrWrongTypeOperation
 	}
@@ -62,7 +79,7 @@ func evalHGET(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 
 func executeHGET(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
 	if len(c.C.Args) != 2 {
-		return cmdResNil, errors.ErrWrongArgumentCount("HGET")
+		return cmdResNil, errors.ErrWrongArgumentCount(c.C.Cmd)
 	}
 	shard := sm.GetShardForKey(c.C.Args[0])
 	return evalHGET(c, shard.Thread.Store())
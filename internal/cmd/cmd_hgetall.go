@@ -30,6 +30,25 @@ f2=v2
 f3=v3
 localhost:7379> HGETALL k2
 OK (nil)
+	`,
+	IsWatchable: true,
+	Arity:       1,
+	WatchExamples: `
+client1:7379> HSET k f1 v1
+OK 1
+client1:7379> HGETALL.WATCH k
+entered the watch mode for HGETALL.WATCH k
+
+
+client2:7379> HSET k f2 v2
+OK 1
+
+
+client1:7379> ...
+entered the watch mode for HGETALL.WATCH k
+OK [fingerprint=4237011426]
+f1=v1
+f2=v2
 	`,
 	Eval:    evalHGETALL,
 	Execute: executeHGETALL,
@@ -41,28 +60,28 @@ func init() {
 
 func evalHGETALL(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 	key := c.C.Args[0]
-	var m SSMap
+	var m *SSMap
 
 	obj := s.Get(key)
 	if obj != nil {
 		if err := object.AssertType(obj.Type, object.ObjTypeSSMap); err != nil {
 			return cmdResNil, errors.ErrWrongTypeOperation
 		}
-		m = obj.Value.(SSMap)
+		m = obj.Value.(*SSMap)
 	}
 
-	if len(m) == 0 {
+	if m == nil || m.Len() == 0 {
 		return cmdResNil, nil
 	}
 
 	return &CmdRes{R: &wire.Response{
-		VSsMap: m,
+		VSsMap: m.Map(),
 	}}, nil
 }
 
 func executeHGETALL(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
 	if len(c.C.Args) != 1 {
-		return cmdResNil, errors.ErrWrongArgumentCount("HGETALL")
+		return cmdResNil, errors.ErrWrongArgumentCount(c.C.Cmd)
 	}
 	shard := sm.GetShardForKey(c.C.Args[0])
 	return evalHGETALL(c, shard.Thread.Store())
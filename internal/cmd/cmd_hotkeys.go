@@ -0,0 +1,114 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const COUNT = "COUNT"
+
+// hotKeysDefaultCount is the number of hot keys returned per shard by
+// HOTKEYS without COUNT.
+const hotKeysDefaultCount = 10
+
+var cHOTKEYS = &CommandMeta{
+	Name:      "HOTKEYS",
+	Syntax:    "HOTKEYS [COUNT n]",
+	HelpShort: "HOTKEYS returns the keys accessed the most recently of every shard",
+	HelpLong: `
+HOTKEYS returns the n keys of every shard accessed the most recently, 10 by default, by shard
+and by decreasing number of accesses. Every key is returned along with
+- shard: the shard holding the key
+- key: the key
+- frequency: the estimated number of recent accesses to the key
+
+The accesses are sampled as the keys are read and written, see hotkeys-sample-rate, and counted
+by a Count-Min Sketch, which may overestimate the frequencies of the keys seldom accessed. The
+counts are halved every so often, for the frequencies to be the ones of the recent accesses.
+The hot keys are kept as the keys are accessed, hence HOTKEYS does not scan the keys.
+
+Returns an error if the hot keys are not tracked, hotkeys-sample-rate being 0.
+	`,
+	Examples: `
+localhost:7379> SET k1 v1
+OK OK
+localhost:7379> GET k1
+OK v1
+... GET k1 99 more times
+localhost:7379> HOTKEYS COUNT 1
+OK
+0) frequency=80 key=k1 shard=0
+	`,
+	KeySpec: allShards,
+	Eval:    evalHOTKEYS,
+	Execute: executeHOTKEYS,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cHOTKEYS)
+}
+
+// parseCount returns the n of the [COUNT n] arguments of the command, or
+// defaultCount if they are not given.
+func parseCount(c *Cmd, defaultCount int) (int, error) {
+	switch len(c.C.Args) {
+	case 0:
+		return defaultCount, nil
+	case 2:
+		if strings.ToUpper(c.C.Args[0]) != COUNT {
+			return 0, errors.ErrInvalidSyntax(c.C.Cmd)
+		}
+		n, err := strconv.Atoi(c.C.Args[1])
+		if err != nil || n <= 0 {
+			return 0, errors.ErrInvalidValue(c.C.Cmd, COUNT)
+		}
+		return n, nil
+	default:
+		return 0, errors.ErrWrongArgumentCount(c.C.Cmd)
+	}
+}
+
+// hotKeys returns the response to HOTKEYS for the stores of the shards.
+func hotKeys(c *Cmd, stores []*dstore.Store) (*CmdRes, error) {
+	n, err := parseCount(c, hotKeysDefaultCount)
+	if err != nil {
+		return cmdResNil, err
+	}
+
+	values := []*structpb.Value{}
+	for _, s := range stores {
+		keys, ok := s.HotKeys(n)
+		if !ok {
+			return cmdResNil, errors.ErrGeneral("the hot keys are not tracked, see hotkeys-sample-rate")
+		}
+		for _, k := range keys {
+			values = append(values, structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+				"shard":     structpb.NewNumberValue(float64(s.ShardID)),
+				"key":       structpb.NewStringValue(k.Key),
+				"frequency": structpb.NewNumberValue(float64(k.Frequency)),
+			}}))
+		}
+	}
+	return &CmdRes{R: &wire.Response{VList: values}}, nil
+}
+
+func evalHOTKEYS(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	return hotKeys(c, []*dstore.Store{s})
+}
+
+func executeHOTKEYS(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	stores := make([]*dstore.Store, 0, len(sm.Shards()))
+	for _, shard := range sm.Shards() {
+		stores = append(stores, shard.Thread.Store())
+	}
+	return hotKeys(c, stores)
+}
@@ -4,6 +4,9 @@
 package cmd
 
 import (
+	"maps"
+
+	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/object"
 	"github.com/dicedb/dice/internal/shardmanager"
@@ -11,7 +14,13 @@ import (
 	"github.com/dicedb/dicedb-go/wire"
 )
 
-type SSMap map[string]string
+// SSMap is a map of strings, the value of a hash. It is stored as a
+// listpack while it is small and as a hashtable once it grows past the
+// thresholds set in the config.
+type SSMap struct {
+	packed *object.PackedList
+	m      map[string]string
+}
 
 var cHSET = &CommandMeta{
 	Name:      "HSET",
@@ -33,6 +42,8 @@ OK v1
 localhost:7379> HGET k2 f1
 OK (nil)
 	`,
+	IsWrite: true,
+	DenyOOM: true,
 	Eval:    evalHSET,
 	Execute: executeHSET,
 }
@@ -41,36 +52,117 @@ func init() {
 	CommandRegistry.AddCommand(cHSET)
 }
 
+// NewSSMap returns an empty SSMap, stored as a listpack.
+func NewSSMap() *SSMap {
+	return &SSMap{packed: &object.PackedList{}}
+}
+
+// Encoding returns the encoding the SSMap is stored as.
+func (h *SSMap) Encoding() object.ObjectEncoding {
+	if h.packed != nil {
+		return object.ObjEncodingListpack
+	}
+	return object.ObjEncodingHashtable
+}
+
 // Get returns the value for the key in the SSMap.
 // Returns false if the key does not exist.
 // Returns the value if the key exists.
-func (h SSMap) Get(k string) (string, bool) {
-	value, ok := h[k]
-	if !ok {
-		return "", false
+func (h *SSMap) Get(k string) (string, bool) {
+	if h.packed != nil {
+		return h.packed.GetPair(k)
 	}
-	return value, true
+	value, ok := h.m[k]
+	return value, ok
 }
 
-// Set sets the value v for the key k in the SSMap.
+// Set sets the value v for the key k in the SSMap, moving the SSMap from a
+// listpack to a hashtable once the pair does not fit in the listpack.
 // Returns the old value if the key exists.
 // The bool return value indicates if the key was already present in the SSMap.
-func (h SSMap) Set(k, v string) (string, bool) {
-	value, ok := h[k]
-	if ok {
-		oldValue := value
-		h[k] = v
-		return oldValue, true
+func (h *SSMap) Set(k, v string) (string, bool) {
+	if h.packed != nil {
+		if len(k) <= config.Config.HashMaxListpackValue && len(v) <= config.Config.HashMaxListpackValue {
+			if old, ok := h.packed.GetPair(k); ok || h.Len() < config.Config.HashMaxListpackEntries {
+				h.packed.SetPair(k, v)
+				return old, ok
+			}
+		}
+		h.m = h.Map()
+		h.packed = nil
+	}
+
+	value, ok := h.m[k]
+	h.m[k] = v
+	return value, ok
+}
+
+// Len returns the number of fields in the SSMap.
+func (h *SSMap) Len() int {
+	if h.packed != nil {
+		return h.packed.Len() / 2
+	}
+	return len(h.m)
+}
+
+// Map returns a copy of the fields and values of the SSMap.
+func (h *SSMap) Map() map[string]string {
+	if h.packed == nil {
+		return maps.Clone(h.m)
 	}
+	m := make(map[string]string, h.Len())
+	h.packed.Pairs(func(k, v string) bool {
+		m[k] = v
+		return true
+	})
+	return m
+}
+
+// Size returns the estimated size of the SSMap in memory, in bytes.
+func (h *SSMap) Size() int {
+	if h.packed != nil {
+		return h.packed.Size()
+	}
+	return object.MapSize(h.m)
+}
 
-	h[k] = v
-	return "", false
+// SampledSize returns the estimated size of the SSMap in memory, in bytes, the
+// size of a hashtable being extrapolated from at most samples of its fields.
+func (h *SSMap) SampledSize(samples int) int {
+	if h.packed != nil {
+		return h.packed.Size()
+	}
+	return object.SampledMapSize(h.m, samples)
+}
+
+// FreeChunk removes at most n fields of the SSMap, a listpack being released
+// at once, and returns true once the SSMap is empty.
+func (h *SSMap) FreeChunk(n int) bool {
+	if h.packed != nil {
+		h.packed = nil
+		return true
+	}
+	for k := range h.m {
+		if n--; n < 0 {
+			break
+		}
+		delete(h.m, k)
+	}
+	return len(h.m) == 0
+}
+
+// DeepCopy returns a copy of the SSMap, in the same encoding.
+func (h *SSMap) DeepCopy() interface{} {
+	if h.packed != nil {
+		return &SSMap{packed: h.packed.DeepCopy().(*object.PackedList)}
+	}
+	return &SSMap{m: maps.Clone(h.m)}
 }
 
 func evalHSET(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 	key := c.C.Args[0]
 
-	var m SSMap
+	var m *SSMap
 	var newFields int64
 
 	obj := s.Get(key)
@@ -78,9 +170,9 @@ func evalHSET(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 		if err := object.AssertType(obj.Type, object.ObjTypeSSMap); err != nil {
 			return cmdResNil, errors.ErrWrongTypeOperation
 		}
-		m = obj.Value.(SSMap)
+		m = obj.Value.(*SSMap)
 	} else {
-		m = make(SSMap)
+		m = NewSSMap()
 	}
 
 	// kvs is the list of key-value pairs to set in the SSMap
@@ -91,11 +183,9 @@ func evalHSET(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 	}
 
 	for i := 0; i < len(kvs); i += 2 {
-		k, v := kvs[i], kvs[i+1]
-		if _, ok := m[k]; !ok {
+		if _, ok := m.Set(kvs[i], kvs[i+1]); !ok {
 			newFields++
 		}
-		m[k] = v
 	}
 
 	obj = s.NewObj(m, -1, object.ObjTypeSSMap)
@@ -25,6 +25,8 @@ OK OK
 localhost:7379> INCR k
 OK 44
 	`,
+	IsWrite: true,
+	DenyOOM: true,
 	Eval:    evalINCR,
 	Execute: executeINCR,
 }
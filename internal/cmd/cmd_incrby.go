@@ -29,6 +29,8 @@ OK OK
 localhost:7379> INCRBY k 10
 OK 53
 	`,
+	IsWrite: true,
+	DenyOOM: true,
 	Eval:    evalINCRBY,
 	Execute: executeINCRBY,
 }
@@ -0,0 +1,174 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+var cINFO = &CommandMeta{
+	Name:      "INFO",
+	Syntax:    "INFO [section]",
+	HelpShort: "INFO returns the statistics of the database",
+	HelpLong: `
+INFO returns the statistics of the database as a map of fields, those of the given section or
+of all the sections if none is given. The sections are
+
+keyspace: the keys of all the shards
+- keys: the number of keys
+- expires: the number of keys with a TTL
+- avg_ttl: the average time left before the keys with a TTL expire, in milliseconds
+- expired_keys: the number of keys expired since the server started
+- expired_per_sec: the number of keys expired per second, as of the last shard cron run
+
+memory: the estimated memory of the keys and the values of all the shards
+- used_memory: the number of bytes used, including the values not freed yet
+- used_memory_dataset: the number of bytes used by the keys in the database
+- lazyfree_pending_memory: the number of bytes of the values being freed in the background
+- lazyfree_pending_objects: the number of values being freed in the background
+- lazyfreed_objects: the number of values freed in the background since the server started
+
+limits: the limits of the shards and the usage of them, 0 for no limit
+- max_keys: the maximum number of keys of all the shards together, see max-keys
+- keys: the number of keys of all the shards, as accounted for against max_keys
+- shard_N_keys: the number of keys of the shard N
+- shard_N_max_keys: the share of max_keys of the shard N, which the shard may exceed while
+  the shards together are under max_keys, and which no key is evicted from the shard under
+- shard_N_used_memory: the number of bytes used by the keys of the shard N
+- shard_N_max_memory: the maximum number of bytes of the shard N, see max-memory
+	`,
+	Examples: `
+localhost:7379> SET k1 v1 EX 100
+OK OK
+localhost:7379> INFO keyspace
+OK
+avg_ttl=99998
+expired_keys=0
+expired_per_sec=0.00
+expires=1
+keys=1
+	`,
+	KeySpec: allShards,
+	Eval:    evalINFO,
+	Execute: executeINFO,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cINFO)
+}
+
+// infoSections return the fields of the sections of INFO for the stores of the shards.
+var infoSections = map[string]func(stores []*dstore.Store) map[string]string{
+	"keyspace": infoKeyspace,
+	"memory":   infoMemory,
+	"limits":   infoLimits,
+}
+
+func infoKeyspace(stores []*dstore.Store) map[string]string {
+	var keys, expires int
+	var expired uint64
+	var ttlSum, expiredPerSec float64
+	for _, s := range stores {
+		stats := s.ExpiryStats()
+		keys += s.GetKeyCount()
+		expires += stats.Keys
+		ttlSum += float64(stats.AvgTTLMs) * float64(stats.Keys)
+		expired += stats.Expired
+		expiredPerSec += stats.ExpiredPerSec
+	}
+
+	avgTTL := 0
+	if expires > 0 {
+		avgTTL = int(ttlSum / float64(expires))
+	}
+	return map[string]string{
+		"keys":            strconv.Itoa(keys),
+		"expires":         strconv.Itoa(expires),
+		"avg_ttl":         strconv.Itoa(avgTTL),
+		"expired_keys":    strconv.FormatUint(expired, 10),
+		"expired_per_sec": strconv.FormatFloat(expiredPerSec, 'f', 2, 64),
+	}
+}
+
+func infoMemory(stores []*dstore.Store) map[string]string {
+	var dataset, pendingBytes, pendingObjects int64
+	var freed uint64
+	for _, s := range stores {
+		stats := s.LazyFreeStats()
+		dataset += s.UsedMemory()
+		pendingBytes += stats.PendingBytes
+		pendingObjects += stats.PendingObjects
+		freed += stats.Freed
+	}
+
+	return map[string]string{
+		"used_memory":              strconv.FormatInt(dataset+pendingBytes, 10),
+		"used_memory_dataset":      strconv.FormatInt(dataset, 10),
+		"lazyfree_pending_memory":  strconv.FormatInt(pendingBytes, 10),
+		"lazyfree_pending_objects": strconv.FormatInt(pendingObjects, 10),
+		"lazyfreed_objects":        strconv.FormatUint(freed, 10),
+	}
+}
+
+func infoLimits(stores []*dstore.Store) map[string]string {
+	fields := map[string]string{}
+	var maxKeys, keys int64
+	for i, s := range stores {
+		shard := "shard_" + strconv.Itoa(i) + "_"
+		stats, ok := s.KeyLimitStats()
+		if ok {
+			maxKeys, keys = stats.Max, stats.Used
+		} else {
+			keys += int64(s.GetKeyCount())
+		}
+		fields[shard+"keys"] = strconv.Itoa(s.GetKeyCount())
+		fields[shard+"max_keys"] = strconv.FormatInt(stats.Soft, 10)
+		fields[shard+"used_memory"] = strconv.FormatInt(s.UsedMemory(), 10)
+		fields[shard+"max_memory"] = strconv.Itoa(config.Config.MaxMemory / len(stores))
+	}
+	fields["max_keys"] = strconv.FormatInt(maxKeys, 10)
+	fields["keys"] = strconv.FormatInt(keys, 10)
+	return fields
+}
+
+// info returns the response to INFO for the stores of the shards.
+func info(c *Cmd, stores []*dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) > 1 {
+		return cmdResNil, errors.ErrWrongArgumentCount("INFO")
+	}
+
+	fields := map[string]string{}
+	if len(c.C.Args) == 0 {
+		for _, section := range infoSections {
+			maps.Copy(fields, section(stores))
+		}
+	} else {
+		section, ok := infoSections[strings.ToLower(c.C.Args[0])]
+		if !ok {
+			return cmdResNil, errors.ErrInvalidValue("INFO", "section")
+		}
+		fields = section(stores)
+	}
+	return &CmdRes{R: &wire.Response{VSsMap: fields}}, nil
+}
+
+func evalINFO(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	return info(c, []*dstore.Store{s})
+}
+
+func executeINFO(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	stores := make([]*dstore.Store, 0, len(sm.Shards()))
+	for _, shard := range sm.Shards() {
+		stores = append(stores, shard.Thread.Store())
+	}
+	return info(c, stores)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+var cKEYSWATCH = &CommandMeta{
+	Name:      "KEYS.WATCH",
+	Syntax:    "KEYS.WATCH pattern",
+	HelpShort: "KEYS.WATCH creates a subscription over all the keys matching the pattern",
+	HelpLong: `
+KEYS.WATCH creates a subscription over all the keys matching the glob-style pattern. The client
+invoking the command receives an update for every command modifying a key that matches the pattern,
+including the keys created after the subscription.
+
+Supported glob-style patterns:
+- h?llo matches hello, hallo and hxllo
+- h*llo matches hllo and heeeello
+- user:*:profile matches user:1:profile and user:alice:profile
+
+The value of the update is the value of the key after the modification, (nil) if the key no longer
+exists. The attributes of the update carry the fingerprint of the subscription, the key that was
+modified and the operation that modified it. The operation is the command that modified the key,
+or one of the following for the keys modified by the server on its own:
+
+- EXPIRED: the key expired
+- EVICT: the key was evicted
+
+The keyspace-events config selects the events that are sent, out of set, del, expired and evicted.
+By default, all of them are sent.
+
+The command returns OK with the fingerprint of the subscription in the attributes. Use the
+fingerprint to UNWATCH the subscription.
+	`,
+	Examples: `
+client1:7379> KEYS.WATCH user:*:profile
+entered the watch mode for KEYS.WATCH user:*:profile
+
+
+client2:7379> SET user:1:profile alice
+OK OK
+client2:7379> DEL user:1:profile
+OK 1
+
+
+client1:7379> ...
+entered the watch mode for KEYS.WATCH user:*:profile
+OK [fingerprint=1914826491 key=user:1:profile operation=SET] alice
+OK [fingerprint=1914826491 key=user:1:profile operation=DEL] (nil)
+	`,
+	KeySpec: noKeys,
+	Eval:    evalKEYSWATCH,
+	Execute: executeKEYSWATCH,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cKEYSWATCH)
+}
+
+// Note: The subscription is created by the iothread,
+// the command only acknowledges it.
+func evalKEYSWATCH(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) != 1 {
+		return cmdResNil, errors.ErrWrongArgumentCount("KEYS.WATCH")
+	}
+	return withFingerprint(c)(cmdResOK, nil)
+}
+
+func executeKEYSWATCH(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	if len(c.C.Args) != 1 {
+		return cmdResNil, errors.ErrWrongArgumentCount("KEYS.WATCH")
+	}
+	shard := sm.GetShardForKey("-")
+	return evalKEYSWATCH(c, shard.Thread.Store())
+}
+
+// KeyValue returns the response holding the value for the key, the way
+// GET or HGETALL return it, and (nil) if the key does not exist.
+func KeyValue(sm *shardmanager.ShardManager, key string) *wire.Response {
+	shard := sm.GetShardForKey(key)
+	shard.RLock()
+	defer shard.RUnlock()
+
+	obj := shard.Thread.Store().GetNoTouch(key)
+	if obj == nil {
+		return GetNilRes().R
+	}
+	if obj.Type == object.ObjTypeSSMap {
+		return &wire.Response{VSsMap: obj.Value.(*SSMap).Map()}
+	}
+	res, err := cmdResFromObject(obj)
+	if err != nil {
+		return GetNilRes().R
+	}
+	return res.R
+}
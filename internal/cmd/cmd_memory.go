@@ -0,0 +1,246 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+const (
+	USAGE   = "USAGE"
+	SAMPLES = "SAMPLES"
+	STATS   = "STATS"
+	DOCTOR  = "DOCTOR"
+)
+
+// doctorMinBytes is the size of the dataset below which MEMORY DOCTOR does
+// not report the shards as unbalanced, a small dataset being unbalanced by
+// a few keys.
+const doctorMinBytes = 1 << 20
+
+var cMEMORY = &CommandMeta{
+	Name:      "MEMORY",
+	Syntax:    "MEMORY USAGE key [SAMPLES count] | MEMORY STATS | MEMORY DOCTOR",
+	HelpShort: "MEMORY reports the memory used by a key, by the shards and by the types of the keys",
+	HelpLong: `
+MEMORY USAGE returns the estimated number of bytes the key and its value use in the store, the
+one accounted for against max-memory. The estimate is computed from the type and the content of
+the value when the key is set, and includes the overhead of the key in the store. With SAMPLES,
+the estimate is computed anew, the size of the hashes and the sets stored as hashtables being
+extrapolated from count of their elements, or computed from all of them for a count of 0.
+
+Returns (nil) if the key does not exist.
+
+MEMORY STATS returns the memory used by the server as a map of fields
+- total.allocated: the number of bytes allocated on the heap of the server
+- dataset.bytes: the number of bytes used by the keys, as accounted for against max-memory
+- dataset.percentage: the share of the heap used by the keys
+- keys.count: the number of keys
+- keys.bytes-per-key: the average number of bytes used by a key
+- lazyfree.pending.bytes: the number of bytes of the values being freed in the background
+- shard.<id>.keys and shard.<id>.dataset.bytes: the keys of every shard and the bytes they use
+- type.<type>.keys and type.<type>.bytes: the keys of every type and the bytes they use
+
+The usage by type is accounted for as the keys are set and deleted, such that no key is visited.
+
+MEMORY DOCTOR returns a report of the memory issues found, such as a dataset close to max-memory,
+shards holding a much larger share of the dataset than the others, or a large number of bytes
+still being freed in the background.
+	`,
+	Examples: `
+localhost:7379> SET k1 v1
+OK OK
+localhost:7379> MEMORY USAGE k1
+OK 100
+localhost:7379> MEMORY USAGE k2
+OK (nil)
+localhost:7379> MEMORY STATS
+OK
+dataset.bytes=100
+dataset.percentage=0.01
+keys.bytes-per-key=100
+keys.count=1
+lazyfree.pending.bytes=0
+shard.0.dataset.bytes=100
+shard.0.keys=1
+total.allocated=1032040
+type.string.bytes=100
+type.string.keys=1
+localhost:7379> MEMORY DOCTOR
+OK no memory issue found
+	`,
+	KeySpec: noKeys,
+	Eval:    evalMEMORY,
+	Execute: executeMEMORY,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cMEMORY)
+}
+
+// memory returns the response to MEMORY for the stores of the shards, the
+// store holding the key for MEMORY USAGE.
+func memory(c *Cmd, stores []*dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) == 0 {
+		return cmdResNil, errors.ErrWrongArgumentCount("MEMORY")
+	}
+
+	switch strings.ToUpper(c.C.Args[0]) {
+	case USAGE:
+		return memoryUsage(c, stores[0])
+	case STATS:
+		if len(c.C.Args) != 1 {
+			return cmdResNil, errors.ErrWrongArgumentCount("MEMORY STATS")
+		}
+		return &CmdRes{R: &wire.Response{VSsMap: memoryStats(stores)}}, nil
+	case DOCTOR:
+		if len(c.C.Args) != 1 {
+			return cmdResNil, errors.ErrWrongArgumentCount("MEMORY DOCTOR")
+		}
+		return &CmdRes{R: &wire.Response{
+			Value: &wire.Response_VStr{VStr: memoryDoctor(stores)},
+		}}, nil
+	default:
+		return cmdResNil, errors.ErrInvalidSyntax("MEMORY")
+	}
+}
+
+func memoryUsage(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) != 2 && len(c.C.Args) != 4 {
+		return cmdResNil, errors.ErrWrongArgumentCount("MEMORY USAGE")
+	}
+	samples := -1
+	if len(c.C.Args) == 4 {
+		if strings.ToUpper(c.C.Args[2]) != SAMPLES {
+			return cmdResNil, errors.ErrInvalidSyntax("MEMORY USAGE")
+		}
+		n, err := strconv.Atoi(c.C.Args[3])
+		if err != nil || n < 0 {
+			return cmdResNil, errors.ErrInvalidValue("MEMORY USAGE", SAMPLES)
+		}
+		samples = n
+	}
+
+	key := c.C.Args[1]
+	obj := s.GetNoTouch(key)
+	if obj == nil {
+		return cmdResNil, nil
+	}
+	size := int64(obj.Size)
+	if samples >= 0 {
+		size = int64(object.ObjOverhead + object.StringSize(key) + object.EstimateSampledSize(obj, samples))
+	}
+	return &CmdRes{R: &wire.Response{
+		Value: &wire.Response_VInt{VInt: size},
+	}}, nil
+}
+
+func memoryStats(stores []*dstore.Store) map[string]string {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	fields := map[string]string{}
+	byType := map[object.ObjectType]dstore.TypeUsage{}
+	var keys int
+	var dataset, pending int64
+	for i, s := range stores {
+		keys += s.GetKeyCount()
+		dataset += s.UsedMemory()
+		pending += s.LazyFreeStats().PendingBytes
+		fields[fmt.Sprintf("shard.%d.keys", i)] = strconv.Itoa(s.GetKeyCount())
+		fields[fmt.Sprintf("shard.%d.dataset.bytes", i)] = strconv.FormatInt(s.UsedMemory(), 10)
+		for t, u := range s.MemoryByType() {
+			total := byType[t]
+			total.Keys += u.Keys
+			total.Bytes += u.Bytes
+			byType[t] = total
+		}
+	}
+	for t, u := range byType {
+		fields["type."+t.String()+".keys"] = strconv.Itoa(u.Keys)
+		fields["type."+t.String()+".bytes"] = strconv.FormatInt(u.Bytes, 10)
+	}
+
+	perKey, percentage := int64(0), 0.0
+	if keys > 0 {
+		perKey = dataset / int64(keys)
+	}
+	if ms.HeapAlloc > 0 {
+		percentage = 100 * float64(dataset) / float64(ms.HeapAlloc)
+	}
+	fields["total.allocated"] = strconv.FormatUint(ms.HeapAlloc, 10)
+	fields["dataset.bytes"] = strconv.FormatInt(dataset, 10)
+	fields["dataset.percentage"] = strconv.FormatFloat(percentage, 'f', 2, 64)
+	fields["keys.count"] = strconv.Itoa(keys)
+	fields["keys.bytes-per-key"] = strconv.FormatInt(perKey, 10)
+	fields["lazyfree.pending.bytes"] = strconv.FormatInt(pending, 10)
+	return fields
+}
+
+func memoryDoctor(stores []*dstore.Store) string {
+	var dataset, pending, largest int64
+	largestShard := 0
+	for i, s := range stores {
+		dataset += s.UsedMemory()
+		pending += s.LazyFreeStats().PendingBytes
+		if s.UsedMemory() > largest {
+			largest, largestShard = s.UsedMemory(), i
+		}
+	}
+	if dataset == 0 && pending == 0 {
+		return "the dataset is empty, no memory issue found"
+	}
+
+	var issues []string
+	if maxMemory := int64(config.Config.MaxMemory); maxMemory > 0 && dataset*10 >= maxMemory*9 {
+		issues = append(issues, fmt.Sprintf("the keys use %d%% of max-memory, past which keys are evicted or writes rejected as per eviction-policy",
+			dataset*100/maxMemory))
+	}
+	if len(stores) > 1 && dataset >= doctorMinBytes && largest*int64(len(stores)) >= 2*dataset {
+		issues = append(issues, fmt.Sprintf("shard %d holds %d%% of the dataset, the keys being unevenly spread across the %d shards",
+			largestShard, largest*100/dataset, len(stores)))
+	}
+	if pending*4 >= dataset {
+		issues = append(issues, fmt.Sprintf("%d bytes of deleted values are still being freed in the background", pending))
+	}
+
+	if len(issues) == 0 {
+		return "no memory issue found"
+	}
+	return strings.Join(issues, "\n")
+}
+
+func evalMEMORY(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	return memory(c, []*dstore.Store{s})
+}
+
+// executeMEMORY acquires the shard locks on its own, MEMORY having no key
+// spec for its key to be told from its subcommand: MEMORY USAGE holds the
+// read lock of the shard of the key, and MEMORY STATS and MEMORY DOCTOR the
+// read locks of all the shards, in the order of their IDs.
+func executeMEMORY(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	if len(c.C.Args) >= 2 && strings.ToUpper(c.C.Args[0]) == USAGE {
+		shard := sm.GetShardForKey(c.C.Args[1])
+		shard.RLock()
+		defer shard.RUnlock()
+		return evalMEMORY(c, shard.Thread.Store())
+	}
+	stores := make([]*dstore.Store, 0, len(sm.Shards()))
+	for _, shard := range sm.Shards() {
+		shard.RLock()
+		defer shard.RUnlock()
+		stores = append(stores, shard.Thread.Store())
+	}
+	return memory(c, stores)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var cMGET = &CommandMeta{
+	Name:      "MGET",
+	Syntax:    "MGET key [key ...]",
+	HelpShort: "MGET returns the values for all the specified keys",
+	HelpLong: `
+MGET returns the values for all the specified keys, in the order of the keys.
+
+The value is (nil) for the keys that do not exist or do not hold a string or an integer.
+	`,
+	Examples: `
+localhost:7379> SET k1 v1
+OK OK
+localhost:7379> SET k2 2
+OK OK
+localhost:7379> MGET k1 k2 k3
+OK
+0) v1
+1) 2
+2) (nil)
+	`,
+	KeySpec:     KeySpec{Last: -1},
+	IsWatchable: true,
+	WatchExamples: `
+client1:7379> SET k1 v1
+OK OK
+client1:7379> MGET.WATCH k1 k2
+entered the watch mode for MGET.WATCH k1 k2
+
+
+client2:7379> SET k2 v2
+OK OK
+
+
+client1:7379> ...
+entered the watch mode for MGET.WATCH k1 k2
+OK [fingerprint=1371183464]
+0) v1
+1) v2
+	`,
+	Eval:    evalMGET,
+	Execute: executeMGET,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cMGET)
+}
+
+func evalMGET(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) < 1 {
+		return cmdResNil, errors.ErrWrongArgumentCount(c.C.Cmd)
+	}
+
+	values := make([]*structpb.Value, len(c.C.Args))
+	for i, key := range c.C.Args {
+		values[i] = mgetValue(s, key)
+	}
+	return &CmdRes{R: &wire.Response{
+		VList: values,
+	}}, nil
+}
+
+func executeMGET(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	if len(c.C.Args) < 1 {
+		return cmdResNil, errors.ErrWrongArgumentCount(c.C.Cmd)
+	}
+
+	values := make([]*structpb.Value, len(c.C.Args))
+	for i, key := range c.C.Args {
+		values[i] = mgetValue(sm.GetShardForKey(key).Thread.Store(), key)
+	}
+	return &CmdRes{R: &wire.Response{
+		VList: values,
+	}}, nil
+}
+
+// mgetValue returns the value for the key, null if the key does
+// not exist or its value is not of a type GET can return.
+func mgetValue(s *dstore.Store, key string) *structpb.Value {
+	obj := s.Get(key)
+	if obj == nil {
+		return structpb.NewNullValue()
+	}
+
+	switch obj.Type {
+	case object.ObjTypeInt, object.ObjTypeString, object.ObjTypeByteArray, object.ObjTypeHLL:
+		r, err := cmdResFromObject(obj)
+		if err != nil {
+			return structpb.NewNullValue()
+		}
+		return responseValue(r.R)
+	default:
+		return structpb.NewNullValue()
+	}
+}
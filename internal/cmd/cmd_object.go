@@ -0,0 +1,79 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+const (
+	ENCODING = "ENCODING"
+)
+
+var cOBJECT = &CommandMeta{
+	Name:      "OBJECT",
+	Syntax:    "OBJECT ENCODING key",
+	HelpShort: "OBJECT ENCODING returns the encoding the value of a key is stored as",
+	HelpLong: `
+OBJECT ENCODING returns the encoding the value of the key is stored as in memory. The small
+hashes are stored as a listpack, a packed list of their fields and values, until they hold more
+than hash-max-listpack-entries fields or a field or a value longer than hash-max-listpack-value
+bytes, after which they are stored as a hashtable.
+
+The encodings are raw, int, float, json, listpack, intset, hashtable and skiplist.
+
+Returns (nil) if the key does not exist.
+	`,
+	Examples: `
+localhost:7379> HSET k1 f1 v1
+OK 1
+localhost:7379> OBJECT ENCODING k1
+OK listpack
+localhost:7379> SET k2 v2
+OK OK
+localhost:7379> OBJECT ENCODING k2
+OK raw
+	`,
+	KeySpec: KeySpec{First: 1, Last: 1},
+	Eval:    evalOBJECT,
+	Execute: executeOBJECT,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cOBJECT)
+}
+
+func evalOBJECT(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) == 0 {
+		return cmdResNil, errors.ErrWrongArgumentCount("OBJECT")
+	}
+	if strings.ToUpper(c.C.Args[0]) != ENCODING {
+		return cmdResNil, errors.ErrInvalidSyntax("OBJECT")
+	}
+	if len(c.C.Args) != 2 {
+		return cmdResNil, errors.ErrWrongArgumentCount("OBJECT ENCODING")
+	}
+
+	obj := s.GetNoTouch(c.C.Args[1])
+	if obj == nil {
+		return cmdResNil, nil
+	}
+	return &CmdRes{R: &wire.Response{
+		Value: &wire.Response_VStr{VStr: object.GetEncoding(obj).String()},
+	}}, nil
+}
+
+func executeOBJECT(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	if len(c.C.Args) < 2 {
+		return evalOBJECT(c, sm.GetShardForKey("-").Thread.Store())
+	}
+	shard := sm.GetShardForKey(c.C.Args[1])
+	return evalOBJECT(c, shard.Thread.Store())
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+var cPSUBSCRIBE = &CommandMeta{
+	Name:      "PSUBSCRIBE",
+	Syntax:    "PSUBSCRIBE pattern [pattern ...]",
+	HelpShort: "PSUBSCRIBE subscribes the client to the channels matching the patterns",
+	HelpLong: `
+PSUBSCRIBE subscribes the client to the channels matching the glob-style patterns, as supported by
+KEYS.WATCH, and returns the number of channels and patterns the client is subscribed to.
+
+The messages published to the matching channels are sent to the watch connection of the client as
+responses holding the message along with the attributes channel and pattern. A client subscribed
+to a channel through both SUBSCRIBE and PSUBSCRIBE, or through several patterns, gets the message
+once for every subscription.
+	`,
+	Examples: `
+localhost:7379> PSUBSCRIBE news.*
+OK 1
+	`,
+	KeySpec: noKeys,
+	Eval:    evalPSUBSCRIBE,
+	Execute: executePSUBSCRIBE,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cPSUBSCRIBE)
+}
+
+// Note: We do not do anything here, because the PSUBSCRIBE command
+// is handled by the iothread.
+func evalPSUBSCRIBE(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) < 1 {
+		return cmdResNil, errors.ErrWrongArgumentCount("PSUBSCRIBE")
+	}
+	return cmdResOK, nil
+}
+
+func executePSUBSCRIBE(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	shard := sm.GetShardForKey("-")
+	return evalPSUBSCRIBE(c, shard.Thread.Store())
+}
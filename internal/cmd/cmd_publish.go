@@ -0,0 +1,49 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+var cPUBLISH = &CommandMeta{
+	Name:      "PUBLISH",
+	Syntax:    "PUBLISH channel message",
+	HelpShort: "PUBLISH sends a message to the subscribers of a channel",
+	HelpLong: `
+PUBLISH sends the message to the clients subscribed to the channel, with SUBSCRIBE, or to a pattern
+matching it, with PSUBSCRIBE, and returns the number of clients it was sent to.
+
+The message is sent to the watch connection of every subscribed client, and is neither stored nor
+replayed: a client subscribed without a watch connection, or not keeping up with the messages, as
+per watch-queue-policy, misses it.
+	`,
+	Examples: `
+localhost:7379> PUBLISH chat hello
+OK 2
+	`,
+	KeySpec: noKeys,
+	Eval:    evalPUBLISH,
+	Execute: executePUBLISH,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cPUBLISH)
+}
+
+// Note: We do not do anything here, because the PUBLISH command
+// is handled by the iothread.
+func evalPUBLISH(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) != 2 {
+		return cmdResNil, errors.ErrWrongArgumentCount("PUBLISH")
+	}
+	return cmdResOK, nil
+}
+
+func executePUBLISH(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	shard := sm.GetShardForKey("-")
+	return evalPUBLISH(c, shard.Thread.Store())
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+const (
+	CHANNELS = "CHANNELS"
+	NUMSUB   = "NUMSUB"
+)
+
+var cPUBSUB = &CommandMeta{
+	Name:      "PUBSUB",
+	Syntax:    "PUBSUB CHANNELS [pattern] | PUBSUB NUMSUB [channel ...]",
+	HelpShort: "PUBSUB returns the state of the pub/sub channels",
+	HelpLong: `
+PUBSUB CHANNELS returns the channels having at least one subscriber through SUBSCRIBE, or only the
+ones matching the glob-style pattern if given, in lexicographical order. The subscribers through
+PSUBSCRIBE are not counted.
+
+PUBSUB NUMSUB returns the number of subscribers through SUBSCRIBE of every channel given.
+	`,
+	Examples: `
+localhost:7379> PUBSUB CHANNELS
+OK
+0) chat
+1) news
+localhost:7379> PUBSUB NUMSUB chat
+OK
+chat=2
+	`,
+	KeySpec: noKeys,
+	Eval:    evalPUBSUB,
+	Execute: executePUBSUB,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cPUBSUB)
+}
+
+// Note: We only validate the command here, because the PUBSUB
+// command is handled by the iothread.
+func evalPUBSUB(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) < 1 {
+		return cmdResNil, errors.ErrWrongArgumentCount("PUBSUB")
+	}
+	switch strings.ToUpper(c.C.Args[0]) {
+	case CHANNELS:
+		if len(c.C.Args) > 2 {
+			return cmdResNil, errors.ErrWrongArgumentCount("PUBSUB CHANNELS")
+		}
+	case NUMSUB:
+	default:
+		return cmdResNil, errors.ErrInvalidSyntax("PUBSUB")
+	}
+	return cmdResOK, nil
+}
+
+func executePUBSUB(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	shard := sm.GetShardForKey("-")
+	return evalPUBSUB(c, shard.Thread.Store())
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+var cPUNSUBSCRIBE = &CommandMeta{
+	Name:      "PUNSUBSCRIBE",
+	Syntax:    "PUNSUBSCRIBE [pattern ...]",
+	HelpShort: "PUNSUBSCRIBE unsubscribes the client from the patterns",
+	HelpLong: `
+PUNSUBSCRIBE unsubscribes the client from the patterns, or from all of them if none is given,
+and returns the number of channels and patterns the client is still subscribed to.
+	`,
+	Examples: `
+localhost:7379> PUNSUBSCRIBE news.*
+OK 0
+	`,
+	KeySpec: noKeys,
+	Eval:    evalPUNSUBSCRIBE,
+	Execute: executePUNSUBSCRIBE,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cPUNSUBSCRIBE)
+}
+
+// Note: We do not do anything here, because the PUNSUBSCRIBE command
+// is handled by the iothread.
+func evalPUNSUBSCRIBE(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	return cmdResOK, nil
+}
+
+func executePUNSUBSCRIBE(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	shard := sm.GetShardForKey("-")
+	return evalPUNSUBSCRIBE(c, shard.Thread.Store())
+}
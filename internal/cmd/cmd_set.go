@@ -68,6 +68,8 @@ OK OK
 localhost:7379> SET k 43 GET
 OK 43
 	`,
+	IsWrite: true,
+	DenyOOM: true,
 	Eval:    evalSET,
 	Execute: executeSET,
 }
@@ -0,0 +1,52 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+var cSUBSCRIBE = &CommandMeta{
+	Name:      "SUBSCRIBE",
+	Syntax:    "SUBSCRIBE channel [channel ...]",
+	HelpShort: "SUBSCRIBE subscribes the client to the channels",
+	HelpLong: `
+SUBSCRIBE subscribes the client to the channels and returns the number of channels and patterns
+the client is subscribed to.
+
+The messages published to the channels are sent to the watch connection of the client, the one
+established with HANDSHAKE in the watch mode, as responses holding the message along with the
+attribute channel. The command can be sent on any connection of the client, and the subscriptions
+are removed when the watch connection of the client is closed.
+	`,
+	Examples: `
+localhost:7379> SUBSCRIBE chat news
+OK 2
+localhost:7379> PUBLISH chat hello
+OK hello [channel=chat]
+	`,
+	KeySpec: noKeys,
+	Eval:    evalSUBSCRIBE,
+	Execute: executeSUBSCRIBE,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cSUBSCRIBE)
+}
+
+// Note: We do not do anything here, because the SUBSCRIBE command
+// is handled by the iothread.
+func evalSUBSCRIBE(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) < 1 {
+		return cmdResNil, errors.ErrWrongArgumentCount("SUBSCRIBE")
+	}
+	return cmdResOK, nil
+}
+
+func executeSUBSCRIBE(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	shard := sm.GetShardForKey("-")
+	return evalSUBSCRIBE(c, shard.Thread.Store())
+}
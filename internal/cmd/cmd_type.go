@@ -30,8 +30,10 @@ int
 localhost:7379> TYPE kn
 none
 	`,
-	Eval:    evalTYPE,
-	Execute: executeTYPE,
+	IsWatchable: true,
+	Arity:       1,
+	Eval:        evalTYPE,
+	Execute:     executeTYPE,
 }
 
 func init() {
@@ -40,7 +42,7 @@ func init() {
 
 func evalTYPE(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 	if len(c.C.Args) != 1 {
-		return cmdResNil, errors.ErrWrongArgumentCount("TYPE")
+		return cmdResNil, errors.ErrWrongArgumentCount(c.C.Cmd)
 	}
 
 	key := c.C.Args[0]
@@ -64,7 +66,7 @@ func evalTYPE(c *Cmd, s *dstore.Store) (*CmdRes, error) {
 
 func executeTYPE(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
 	if len(c.C.Args) == 0 {
-		return cmdResNil, errors.ErrWrongArgumentCount("TYPE")
+		return cmdResNil, errors.ErrWrongArgumentCount(c.C.Cmd)
 	}
 
 	shard := sm.GetShardForKey(c.C.Args[0])
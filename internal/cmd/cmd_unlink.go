@@ -0,0 +1,74 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+var cUNLINK = &CommandMeta{
+	Name:      "UNLINK",
+	Syntax:    "UNLINK key [key ...]",
+	HelpShort: "UNLINK deletes all the specified keys, freeing their values in the background",
+	HelpLong: `
+UNLINK deletes all the specified keys the way DEL does, and returns the number of keys deleted.
+
+The keys are removed from the database at once, while the large values, such as the hashes with
+many fields, are freed in the background, in chunks, rather than on the command path. The values
+not freed yet are reported by INFO memory.
+	`,
+	Examples: `
+localhost:7379> SET k1 v1
+OK OK
+localhost:7379> HSET k2 f1 v1
+OK 1
+localhost:7379> UNLINK k1 k2 k3
+OK 2
+	`,
+	IsWrite: true,
+	KeySpec: KeySpec{Last: -1},
+	Eval:    evalUNLINK,
+	Execute: executeUNLINK,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cUNLINK)
+}
+
+func evalUNLINK(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) < 1 {
+		return cmdResNil, errors.ErrWrongArgumentCount("UNLINK")
+	}
+
+	var count int
+	for _, key := range c.C.Args {
+		if ok := s.Del(key, dstore.WithLazyFree(true)); ok {
+			count++
+		}
+	}
+
+	return &CmdRes{R: &wire.Response{
+		Value: &wire.Response_VInt{VInt: int64(count)},
+	}}, nil
+}
+
+func executeUNLINK(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	if len(c.C.Args) < 1 {
+		return cmdResNil, errors.ErrWrongArgumentCount("UNLINK")
+	}
+
+	var count int64
+	for _, key := range c.C.Args {
+		shard := sm.GetShardForKey(key)
+		if shard.Thread.Store().Del(key, dstore.WithLazyFree(true)) {
+			count++
+		}
+	}
+	return &CmdRes{R: &wire.Response{
+		Value: &wire.Response_VInt{VInt: count},
+	}}, nil
+}
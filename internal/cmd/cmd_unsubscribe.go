@@ -0,0 +1,41 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+var cUNSUBSCRIBE = &CommandMeta{
+	Name:      "UNSUBSCRIBE",
+	Syntax:    "UNSUBSCRIBE [channel ...]",
+	HelpShort: "UNSUBSCRIBE unsubscribes the client from the channels",
+	HelpLong: `
+UNSUBSCRIBE unsubscribes the client from the channels, or from all of them if none is given,
+and returns the number of channels and patterns the client is still subscribed to.
+	`,
+	Examples: `
+localhost:7379> UNSUBSCRIBE chat
+OK 1
+	`,
+	KeySpec: noKeys,
+	Eval:    evalUNSUBSCRIBE,
+	Execute: executeUNSUBSCRIBE,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cUNSUBSCRIBE)
+}
+
+// Note: We do not do anything here, because the UNSUBSCRIBE command
+// is handled by the iothread.
+func evalUNSUBSCRIBE(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	return cmdResOK, nil
+}
+
+func executeUNSUBSCRIBE(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	shard := sm.GetShardForKey("-")
+	return evalUNSUBSCRIBE(c, shard.Thread.Store())
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+var cWATCHLIST = &CommandMeta{
+	Name:      "WATCH.LIST",
+	Syntax:    "WATCH.LIST",
+	HelpShort: "WATCH.LIST lists the live query subscriptions",
+	HelpLong: `
+WATCH.LIST lists the live query subscriptions, in the order of their fingerprints. For every
+subscription, it returns its fingerprint, its command, the IDs of the clients subscribed to it,
+the sequence number of its last update and whether it is orphaned, i.e. left without clients by
+disconnects and retained for watch-retention-sec seconds for the clients to resume it.
+	`,
+	Examples: `
+localhost:7379> WATCH.LIST
+OK
+0) {"clients":["4c9d0411-6b28-4ee5-b78a-e7e258afa52f"],"command":"GET.WATCH k1","fingerprint":"2356444921","orphaned":false,"seq":3}
+	`,
+	KeySpec: noKeys,
+	Eval:    evalWATCHLIST,
+	Execute: executeWATCHLIST,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cWATCHLIST)
+}
+
+// Note: We do not do anything here, because the WATCH.LIST command
+// is handled by the iothread.
+func evalWATCHLIST(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) != 0 {
+		return cmdResNil, errors.ErrWrongArgumentCount("WATCH.LIST")
+	}
+	return cmdResOK, nil
+}
+
+func executeWATCHLIST(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	shard := sm.GetShardForKey("-")
+	return evalWATCHLIST(c, shard.Thread.Store())
+}
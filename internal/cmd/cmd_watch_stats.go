@@ -0,0 +1,61 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+var cWATCHSTATS = &CommandMeta{
+	Name:      "WATCH.STATS",
+	Syntax:    "WATCH.STATS",
+	HelpShort: "WATCH.STATS returns the metrics of the query subscriptions",
+	HelpLong: `
+WATCH.STATS returns the metrics of the query subscriptions and of the watch connections:
+
+- subscriptions: the number of live subscriptions, orphaned: the ones left without clients
+- keys and patterns: the number of keys and KEYS.WATCH patterns watched
+- clients: the number of watch connections
+- queue_depth and max_queue_depth: the total and the largest number of updates queued for the
+  watch connections, see watch-queue-size
+- dropped and disconnected: the number of updates dropped and of clients disconnected because
+  of a full queue, see watch-queue-policy
+	`,
+	Examples: `
+localhost:7379> WATCH.STATS
+OK
+clients=1
+disconnected=0
+dropped=0
+keys=1
+max_queue_depth=0
+orphaned=0
+patterns=0
+queue_depth=0
+subscriptions=1
+	`,
+	KeySpec: noKeys,
+	Eval:    evalWATCHSTATS,
+	Execute: executeWATCHSTATS,
+}
+
+func init() {
+	CommandRegistry.AddCommand(cWATCHSTATS)
+}
+
+// Note: We do not do anything here, because the WATCH.STATS command
+// is handled by the iothread.
+func evalWATCHSTATS(c *Cmd, s *dstore.Store) (*CmdRes, error) {
+	if len(c.C.Args) != 0 {
+		return cmdResNil, errors.ErrWrongArgumentCount("WATCH.STATS")
+	}
+	return cmdResOK, nil
+}
+
+func executeWATCHSTATS(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+	shard := sm.GetShardForKey("-")
+	return evalWATCHSTATS(c, shard.Thread.Store())
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/wal"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/proto"
+)
+
+// CommandLog appends the write commands to the WAL. The commands are appended
+// while the locks of their shards are held, for the order of the writes to a
+// key in the WAL to be their order of execution.
+//
+// A command failing to be appended has already modified the data, hence it is
+// kept pending and appended before the next write, which is rejected without
+// being executed until the pending commands are appended, such that the data
+// held in memory never gets more than the failed writes ahead of the WAL.
+type CommandLog struct {
+	wl wal.AbstractWAL
+
+	mu sync.Mutex
+	// pending are the commands executed and not appended to the WAL yet,
+	// in their order of execution.
+	pending [][]byte
+}
+
+// NewCommandLog returns the log of the write commands to the WAL.
+func NewCommandLog(wl wal.AbstractWAL) *CommandLog {
+	return &CommandLog{wl: wl}
+}
+
+// ready appends the pending commands to the WAL, and returns an error if the
+// WAL still cannot be appended to, in which case no write is to be executed.
+func (l *CommandLog) ready() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for len(l.pending) > 0 {
+		if err := l.wl.LogCommand(l.pending[0]); err != nil {
+			slog.Error("could not append the pending commands to the WAL", slog.Int("pending", len(l.pending)), slog.Any("error", err))
+			return errors.ErrWALFailed
+		}
+		l.pending = l.pending[1:]
+	}
+	return nil
+}
+
+// append appends the command executed to the WAL, the command being kept
+// pending if it cannot be appended.
+func (l *CommandLog) append(c *wire.Command) error {
+	if l == nil || c == nil {
+		return nil
+	}
+	b, err := proto.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// A command executed on another shard may have failed to be appended
+	// since this one was checked, in which case it comes first.
+	if len(l.pending) == 0 {
+		if err = l.wl.LogCommand(b); err == nil {
+			return nil
+		}
+		slog.Error("could not append the command to the WAL", slog.Any("error", err))
+	}
+	l.pending = append(l.pending, b)
+	return errors.ErrWALFailed
+}
@@ -6,15 +6,21 @@ package cmd
 import (
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/dgryski/go-farm"
 	"github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/shard"
 	"github.com/dicedb/dice/internal/shardmanager"
 	"github.com/dicedb/dice/internal/store"
 	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // nolint: stylecheck
@@ -26,6 +32,9 @@ type Cmd struct {
 	ClientID string
 	Mode     string
 	Meta     *CommandMeta
+	// WAL is the log the command is appended to if it is a write, nil for
+	// the commands not to be logged, such as the ones replayed.
+	WAL *CommandLog
 }
 
 func (c *Cmd) String() string {
@@ -43,24 +52,131 @@ func (c *Cmd) Key() string {
 	return ""
 }
 
+// Keys returns all the keys the command reads or writes,
+// as described by the key spec of the command.
+func (c *Cmd) Keys() []string {
+	if err := c.resolveMeta(); err != nil {
+		return []string{c.Key()}
+	}
+	return c.Meta.KeySpec.Keys(c.args())
+}
+
+// IsWrite returns true if the command may modify the data.
+func (c *Cmd) IsWrite() bool {
+	return c.resolveMeta() == nil && c.Meta.IsWrite
+}
+
 func (c *Cmd) Execute(sm *shardmanager.ShardManager) (*CmdRes, error) {
-	res := GetNilRes()
+	if err := c.resolveMeta(); err != nil {
+		return GetNilRes(), err
+	}
 
-	err := errors.ErrUnknownCmd(c.C.Cmd)
-	start := time.Now()
-	if c.Meta == nil {
-		meta, ok := CommandRegistry.CommandMetas[c.C.Cmd]
-		if !ok {
-			return res, err
+	// BATCH acquires the shard locks for its sub-commands on its own.
+	// The writes hold the write locks, for the writes to a shard to be
+	// executed and logged to the WAL one at a time.
+	if c.Meta.Name != "BATCH" {
+		shards := c.shards(sm)
+		for _, sh := range shards {
+			lockShard(sh, c.Meta.IsWrite)
+		}
+		defer func() {
+			for i := len(shards) - 1; i >= 0; i-- {
+				unlockShard(shards[i], c.Meta.IsWrite)
+			}
+		}()
+	}
+	return c.execute(sm)
+}
+
+// shards returns the shards owning the keys of the command in the order
+// of their IDs, which is the order their locks are to be acquired in.
+func (c *Cmd) shards(sm *shardmanager.ShardManager) []*shard.Shard {
+	if c.Meta.KeySpec.AllShards {
+		return sm.Shards()
+	}
+	if c.Meta.KeySpec.single() {
+		return []*shard.Shard{sm.GetShardForKey(c.Key())}
+	}
+
+	var shards []*shard.Shard
+	for _, key := range c.Keys() {
+		sh := sm.GetShardForKey(key)
+		if !slices.Contains(shards, sh) {
+			shards = append(shards, sh)
+		}
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		return shards[i].ID < shards[j].ID
+	})
+	return shards
+}
+
+// resolveMeta looks up the command in the registry, if not already done.
+func (c *Cmd) resolveMeta() error {
+	if c.Meta != nil {
+		return nil
+	}
+	meta, ok := CommandRegistry.CommandMetas[c.C.Cmd]
+	if !ok {
+		return errors.ErrUnknownCmd(c.C.Cmd)
+	}
+	c.Meta = meta
+	return nil
+}
+
+// lockShard acquires the write lock of the shard for a write, and the read
+// lock otherwise.
+func lockShard(sh *shard.Shard, write bool) {
+	if write {
+		sh.Lock()
+	} else {
+		sh.RLock()
+	}
+}
+
+func unlockShard(sh *shard.Shard, write bool) {
+	if write {
+		sh.Unlock()
+	} else {
+		sh.RUnlock()
+	}
+}
+
+// execute runs the command without acquiring any shard lock, and appends it
+// to its WAL if it is a write executed successfully. The caller is
+// responsible for the isolation of the execution, the writes requiring the
+// write locks of their shards.
+func (c *Cmd) execute(sm *shardmanager.ShardManager) (*CmdRes, error) {
+	logged := c.Meta.IsWrite && c.Meta.Name != "BATCH"
+	if logged {
+		if err := c.WAL.ready(); err != nil {
+			return cmdResNil, err
 		}
-		c.Meta = meta
 	}
-	res, err = c.Meta.Execute(c, sm)
+
+	if c.Meta.DenyOOM && !c.IsReplay {
+		for _, key := range c.Keys() {
+			if s := sm.GetShardForKey(key).Thread.Store(); s.RejectsWrites() {
+				if s.KeyLimitReached() {
+					return cmdResNil, errors.ErrOutOfKeys
+				}
+				return cmdResNil, errors.ErrOutOfMemory
+			}
+		}
+	}
+
+	start := time.Now()
+	res, err := c.Meta.Execute(c, sm)
 	slog.Debug("command executed",
 		slog.Any("cmd", c.String()),
 		slog.String("client_id", c.ClientID),
 		slog.String("mode", c.Mode),
 		slog.Any("took_ns", time.Since(start).Nanoseconds()))
+	if err == nil && logged {
+		if err := c.WAL.append(c.C); err != nil {
+			return cmdResNil, err
+		}
+	}
 	return res, err
 }
 
@@ -75,8 +191,35 @@ type CommandMeta struct {
 	Syntax    string
 	Examples  string
 	HelpLong  string
-	Eval      func(c *Cmd, s *store.Store) (*CmdRes, error)
-	Execute   func(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error)
+
+	// IsWrite marks the commands that may modify the data.
+	// These are the commands logged to the WAL.
+	IsWrite bool
+
+	// DenyOOM marks the write commands that may grow the data, which
+	// are rejected once the shard of their key is full and no key can be
+	// evicted, see eviction-policy.
+	DenyOOM bool
+
+	// KeySpec describes which of the arguments are keys.
+	KeySpec KeySpec
+
+	// IsWatchable makes the read command available as <Name>.WATCH,
+	// which creates a query subscription over the command. WatchExamples
+	// are the examples shown in the documentation of the .WATCH command.
+	IsWatchable   bool
+	WatchExamples string
+	// Arity is the number of arguments of the watchable command taking a
+	// fixed number of them, after which its .WATCH command takes the
+	// delivery options. The .WATCH command of the one taking any number of
+	// arguments takes them after --.
+	Arity int
+
+	// hasWatchOptions marks the .WATCH commands accepting the delivery options.
+	hasWatchOptions bool
+
+	Eval    func(c *Cmd, s *store.Store) (*CmdRes, error)
+	Execute func(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error)
 }
 
 type CmdRegistry struct {
@@ -89,6 +232,152 @@ func Total() int {
 
 func (r *CmdRegistry) AddCommand(cmd *CommandMeta) {
 	r.CommandMetas[cmd.Name] = cmd
+	if cmd.IsWatchable {
+		w := watchCommand(cmd)
+		r.CommandMetas[w.Name] = w
+	}
+}
+
+// KeySpec describes the positions of the keys in the arguments of a command.
+// The zero value describes a command with a single key, its first argument,
+// and a First greater than Last a command without any key.
+type KeySpec struct {
+	// First and Last are the positions of the first and the last key.
+	// A negative Last counts from the end of the arguments.
+	First, Last int
+	// Step is the distance between two consecutive keys, 1 if not set.
+	Step int
+	// AllShards marks the commands operating on all the shards, which
+	// hold the locks of all the shards rather than the ones of their keys.
+	AllShards bool
+}
+
+// Keys returns the keys in the arguments.
+func (k KeySpec) Keys(args []string) []string {
+	last := k.Last
+	if last < 0 {
+		last += len(args)
+	}
+	last = min(last, len(args)-1)
+	if k.First > last {
+		return nil
+	}
+
+	step := max(k.Step, 1)
+	keys := make([]string, 0, (last-k.First)/step+1)
+	for i := k.First; i <= last; i += step {
+		keys = append(keys, args[i])
+	}
+	return keys
+}
+
+// noKeys is the key spec of the commands without any key.
+var noKeys = KeySpec{First: 1, Last: 0}
+
+// allShards is the key spec of the commands without any key operating on all the shards.
+var allShards = KeySpec{First: 1, Last: 0, AllShards: true}
+
+func (k KeySpec) single() bool {
+	return k.First == 0 && k.Last == 0
+}
+
+// watchCommand derives the <Name>.WATCH command from the watchable command.
+// The .WATCH command responds with the response of the command along with
+// the fingerprint of the subscription, which the watch manager re-executes
+// whenever any of the keys read by the command is modified.
+func watchCommand(m *CommandMeta) *CommandMeta {
+	name := m.Name + ".WATCH"
+	options := "[DEBOUNCE duration] [THROTTLE count/duration] [ONLYCHANGED] [WHERE [path] op value [EDGE | LEVEL]]"
+	note := ""
+	if m.Arity == 0 {
+		options = "[" + watchOptionsMarker + " " + options + "]"
+		note = fmt.Sprintf("\n\nAs %[1]s takes any number of arguments, the options follow --, e.g. %[1]s.WATCH k1 k2 -- ONLYCHANGED.", m.Name)
+	}
+	return &CommandMeta{
+		Name:      name,
+		Syntax:    name + strings.TrimPrefix(m.Syntax, m.Name) + " " + options,
+		HelpShort: fmt.Sprintf("%s creates a query subscription over the %s command", name, m.Name),
+		HelpLong: fmt.Sprintf(`
+%[1]s creates a query subscription over the %[2]s command. The client invoking the command
+will receive the output of the %[2]s command (not just the notification) whenever any of the
+keys read by the command is updated.
+
+You can update the keys in any other client. The %[1]s client will receive the updated output.
+
+The response carries the fingerprint of the subscription in its attributes. Use it to UNWATCH
+the subscription.
+
+The delivery of the updates can be tuned with the following options, passed after the arguments
+of the %[2]s command. The options are part of the subscription, i.e. the same command with
+different options creates a different subscription.%[3]s
+
+- DEBOUNCE duration: sends the update only once the keys are left unmodified for the duration, e.g. 100ms.
+- THROTTLE count/duration: sends at most count updates per duration, e.g. 10/s or 1/500ms. The
+  modifications made in between are coalesced into a single update sent at the end of the interval.
+- ONLYCHANGED: does not send the update if the output is the same as the one of the last update.
+- WHERE [path] op value [EDGE | LEVEL]: sends the update only if the output satisfies the predicate.
+  The operator is one of ==, !=, <, <=, > and >=. The output and the value are compared as numbers
+  if both are numbers, and as strings otherwise. Given a JSON path starting with $, e.g. $.status,
+  the value at the path in the output, holding JSON or the fields of a hash, is compared instead.
+  With EDGE, the default, the update is sent only when the output starts satisfying the predicate.
+  With LEVEL, every update satisfying the predicate is sent.
+
+With DEBOUNCE and THROTTLE the command is re-executed once per update sent, not per modification.
+	`, name, m.Name, note),
+		Examples:        m.WatchExamples,
+		KeySpec:         m.KeySpec,
+		Arity:           m.Arity,
+		hasWatchOptions: true,
+		Eval: func(c *Cmd, s *store.Store) (*CmdRes, error) {
+			base, err := c.withoutWatchOptions()
+			if err != nil {
+				return cmdResNil, err
+			}
+			return withFingerprint(c)(m.Eval(base, s))
+		},
+		Execute: func(c *Cmd, sm *shardmanager.ShardManager) (*CmdRes, error) {
+			base, err := c.withoutWatchOptions()
+			if err != nil {
+				return cmdResNil, err
+			}
+			return withFingerprint(c)(m.Execute(base, sm))
+		},
+	}
+}
+
+// withoutWatchOptions returns a copy of the .WATCH command without the delivery options.
+func (c *Cmd) withoutWatchOptions() (*Cmd, error) {
+	args, _, err := parseWatchOptions(c.C.Cmd, c.Meta.Arity, c.C.Args)
+	if err != nil {
+		return nil, err
+	}
+	base := *c
+	base.C = &wire.Command{Cmd: c.C.Cmd, Args: args}
+	return &base, nil
+}
+
+// withFingerprint adds the fingerprint of the command to the attributes
+// of the response. The response is copied as it may be a shared one.
+func withFingerprint(c *Cmd) func(res *CmdRes, err error) (*CmdRes, error) {
+	return func(res *CmdRes, err error) (*CmdRes, error) {
+		if err != nil {
+			return res, err
+		}
+
+		attrs := &structpb.Struct{Fields: make(map[string]*structpb.Value)}
+		if res.R.Attrs != nil {
+			maps.Copy(attrs.Fields, res.R.Attrs.Fields)
+		}
+		attrs.Fields["fingerprint"] = structpb.NewStringValue(strconv.FormatUint(uint64(c.Fingerprint()), 10))
+
+		return &CmdRes{R: &wire.Response{
+			Err:    res.R.Err,
+			Value:  res.R.Value,
+			Attrs:  attrs,
+			VList:  res.R.VList,
+			VSsMap: res.R.VSsMap,
+		}, ClientID: res.ClientID}, nil
+	}
 }
 
 var CommandRegistry CmdRegistry = CmdRegistry{
@@ -0,0 +1,168 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc64"
+	"io"
+	"math"
+
+	"github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+)
+
+// The serialized form of an object follows the one of DUMP, see
+// eval/dump_restore.go: a version byte and a type byte, the value, an end
+// marker and the CRC-64 of all of the above. The values are serialized here
+// for the types the commands of this package store, the hashes and the sets
+// being serialized as their number of elements followed by the elements.
+const (
+	dumpVersion   byte = 0x09
+	dumpEndMarker byte = 0xFF
+)
+
+var dumpCRCTable = crc64.MakeTable(crc64.ECMA)
+
+// dumpObj returns the serialized form of the object.
+func dumpObj(obj *object.Obj) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(dumpVersion)
+	buf.WriteByte(byte(obj.Type))
+	if err := writeDumpValue(&buf, obj); err != nil {
+		return nil, err
+	}
+	buf.WriteByte(dumpEndMarker)
+	return binary.BigEndian.AppendUint64(buf.Bytes(), crc64.Checksum(buf.Bytes(), dumpCRCTable)), nil
+}
+
+func writeDumpValue(buf *bytes.Buffer, obj *object.Obj) error {
+	// A JSON document may decode to a string or a number, hence the type
+	// of the object is checked before the one of the value.
+	if obj.Type == object.ObjTypeJSON {
+		b, err := json.Marshal(obj.Value)
+		if err != nil {
+			return err
+		}
+		writeDumpString(buf, string(b))
+		return nil
+	}
+
+	switch v := obj.Value.(type) {
+	case string:
+		writeDumpString(buf, v)
+	case int64:
+		writeDumpUint(buf, uint64(v))
+	case float64:
+		writeDumpUint(buf, math.Float64bits(v))
+	case *SSMap:
+		m := v.Map()
+		writeDumpUint(buf, uint64(len(m)))
+		for k, fv := range m {
+			writeDumpString(buf, k)
+			writeDumpString(buf, fv)
+		}
+	case *object.Set:
+		writeDumpUint(buf, uint64(v.Len()))
+		v.All(func(member string) bool {
+			writeDumpString(buf, member)
+			return true
+		})
+	default:
+		return errors.ErrUnknownObjectType
+	}
+	return nil
+}
+
+// restoreObj returns the object of the serialized form, without an expiry.
+func restoreObj(data []byte) (*object.Obj, error) {
+	if len(data) < 11 || data[0] != dumpVersion || data[len(data)-9] != dumpEndMarker ||
+		crc64.Checksum(data[:len(data)-8], dumpCRCTable) != binary.BigEndian.Uint64(data[len(data)-8:]) {
+		return nil, errors.ErrGeneral("invalid serialized value")
+	}
+	obj := &object.Obj{Type: object.ObjectType(data[1])}
+	r := bytes.NewReader(data[2 : len(data)-9])
+
+	var err error
+	switch obj.Type {
+	case object.ObjTypeString:
+		obj.Value, err = readDumpString(r)
+	case object.ObjTypeInt:
+		var v uint64
+		v, err = readDumpUint(r)
+		obj.Value = int64(v)
+	case object.ObjTypeFloat:
+		var v uint64
+		v, err = readDumpUint(r)
+		obj.Value = math.Float64frombits(v)
+	case object.ObjTypeSSMap:
+		m := NewSSMap()
+		err = readDumpElements(r, 2, func(e []string) { m.Set(e[0], e[1]) })
+		obj.Value = m
+	case object.ObjTypeSet:
+		set := object.NewSet()
+		err = readDumpElements(r, 1, func(e []string) { set.Add(e[0]) })
+		obj.Value = set
+	case object.ObjTypeJSON:
+		var s string
+		if s, err = readDumpString(r); err == nil {
+			err = json.Unmarshal([]byte(s), &obj.Value)
+		}
+	default:
+		return nil, errors.ErrUnknownObjectType
+	}
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func writeDumpUint(buf *bytes.Buffer, v uint64) {
+	buf.Write(binary.BigEndian.AppendUint64(nil, v))
+}
+
+func writeDumpString(buf *bytes.Buffer, s string) {
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(len(s))))
+	buf.WriteString(s)
+}
+
+func readDumpUint(r *bytes.Reader) (uint64, error) {
+	var v uint64
+	err := binary.Read(r, binary.BigEndian, &v)
+	return v, err
+}
+
+func readDumpString(r *bytes.Reader) (string, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return "", err
+	}
+	if int(n) > r.Len() {
+		return "", errors.ErrGeneral("invalid serialized value")
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return string(b), err
+}
+
+// readDumpElements reads the number of elements, then calls f with each of
+// them, an element being made of size strings.
+func readDumpElements(r *bytes.Reader, size int, f func(e []string)) error {
+	n, err := readDumpUint(r)
+	if err != nil {
+		return err
+	}
+	e := make([]string, size)
+	for ; n > 0; n-- {
+		for i := range e {
+			if e[i], err = readDumpString(r); err != nil {
+				return err
+			}
+		}
+		f(e)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dicedb/dice/internal/errors"
+)
+
+const (
+	DEBOUNCE    = "DEBOUNCE"
+	THROTTLE    = "THROTTLE"
+	ONLYCHANGED = "ONLYCHANGED"
+	WHERE       = "WHERE"
+	EDGE        = "EDGE"
+	LEVEL       = "LEVEL"
+)
+
+// WatchOptions are the delivery options of a query subscription,
+// passed after the arguments of the .WATCH command.
+type WatchOptions struct {
+	// Debounce delays an update until the keys read by
+	// the command are left unmodified for the duration.
+	Debounce time.Duration
+	// Throttle is the minimum interval between two updates.
+	Throttle time.Duration
+	// OnlyChanged suppresses the updates with the same output as the last one sent.
+	OnlyChanged bool
+
+	// Where suppresses the updates whose output does not satisfy the predicate.
+	// Unless Level is set, an update is sent only when the output starts
+	// satisfying the predicate, not while it keeps satisfying it.
+	Where *Predicate
+	Level bool
+}
+
+// watchOptionsMarker separates the delivery options from the arguments of
+// the .WATCH commands of the commands taking any number of arguments.
+const watchOptionsMarker = "--"
+
+// WatchOptions returns the delivery options of the .WATCH command.
+func (c *Cmd) WatchOptions() (WatchOptions, error) {
+	if err := c.resolveMeta(); err != nil || !c.Meta.hasWatchOptions {
+		return WatchOptions{}, err
+	}
+	_, opts, err := parseWatchOptions(c.C.Cmd, c.Meta.Arity, c.C.Args)
+	return opts, err
+}
+
+// args returns the arguments of the command without the delivery options, if any.
+func (c *Cmd) args() []string {
+	if !c.Meta.hasWatchOptions {
+		return c.C.Args
+	}
+	args, _, _ := parseWatchOptions(c.C.Cmd, c.Meta.Arity, c.C.Args)
+	return args
+}
+
+// parseWatchOptions splits the delivery options off the arguments of the
+// .WATCH command and returns the arguments of the command. The options
+// follow the arity arguments of the command, or the marker if the command
+// takes any number of arguments, hence a key or a field named after an
+// option is never taken for one.
+func parseWatchOptions(command string, arity int, args []string) ([]string, WatchOptions, error) {
+	var (
+		opts    WatchOptions
+		trigger bool
+		tail    []string
+	)
+	if arity > 0 {
+		if len(args) <= arity {
+			return args, opts, nil
+		}
+		args, tail = args[:arity], args[arity:]
+	} else {
+		i := slices.Index(args, watchOptionsMarker)
+		if i < 0 {
+			return args, opts, nil
+		}
+		args, tail = args[:i], args[i+1:]
+	}
+
+	for len(tail) > 0 {
+		option := strings.ToUpper(tail[0])
+		switch option {
+		case ONLYCHANGED:
+			opts.OnlyChanged = true
+			tail = tail[1:]
+			continue
+		case EDGE, LEVEL:
+			opts.Level = option == LEVEL
+			trigger = true
+			tail = tail[1:]
+			continue
+		case WHERE:
+			// WHERE [path] op value, the path being a JSON path starting with $.
+			path, rest := "", tail[1:]
+			if len(rest) > 0 && strings.HasPrefix(rest[0], "$") {
+				path, rest = rest[0], rest[1:]
+			}
+			if len(rest) < 2 {
+				return args, opts, errors.ErrInvalidSyntax(command)
+			}
+			p, ok := parsePredicate(path, rest[0], rest[1])
+			if !ok {
+				return args, opts, errors.ErrInvalidValue(command, WHERE)
+			}
+			opts.Where = p
+			tail = rest[2:]
+			continue
+		case DEBOUNCE, THROTTLE:
+		default:
+			return args, opts, errors.ErrInvalidSyntax(command)
+		}
+
+		if len(tail) < 2 {
+			return args, opts, errors.ErrInvalidSyntax(command)
+		}
+		if option == DEBOUNCE {
+			d, err := time.ParseDuration(tail[1])
+			if err != nil || d <= 0 {
+				return args, opts, errors.ErrInvalidValue(command, DEBOUNCE)
+			}
+			opts.Debounce = d
+		} else {
+			d, ok := parseRate(tail[1])
+			if !ok {
+				return args, opts, errors.ErrInvalidValue(command, THROTTLE)
+			}
+			opts.Throttle = d
+		}
+		tail = tail[2:]
+	}
+	return args, opts, checkTrigger(command, opts, trigger)
+}
+
+// checkTrigger rejects EDGE and LEVEL without a WHERE clause.
+func checkTrigger(command string, opts WatchOptions, trigger bool) error {
+	if trigger && opts.Where == nil {
+		return errors.ErrInvalidSyntax(command)
+	}
+	return nil
+}
+
+// parseRate parses a rate given as count/duration, e.g. 10/s or 1/500ms,
+// and returns the interval between two consecutive events.
+func parseRate(s string) (time.Duration, bool) {
+	count, per, ok := strings.Cut(s, "/")
+	if !ok || per == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	// The duration may be a bare unit, e.g. s for 1s.
+	if per[0] < '0' || per[0] > '9' {
+		per = "1" + per
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+	return d / time.Duration(n), true
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package cmd
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/dicedb/dicedb-go/wire"
+	"github.com/ohler55/ojg/jp"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The operators of the WHERE predicates.
+var predicateOps = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+}
+
+// Predicate is the condition of the WHERE clause of a .WATCH command,
+// comparing the output of the command, or the value at a JSON path in
+// it, with a constant.
+type Predicate struct {
+	Path  string
+	Op    string
+	Value string
+
+	expr jp.Expr
+	// num is the value as a number, if it is one.
+	num   float64
+	isNum bool
+}
+
+// parsePredicate parses the predicate, the path being empty if not given.
+func parsePredicate(path, op, value string) (*Predicate, bool) {
+	if !predicateOps[op] {
+		return nil, false
+	}
+
+	p := &Predicate{Path: path, Op: op, Value: value}
+	if path != "" {
+		expr, err := jp.ParseString(path)
+		if err != nil {
+			return nil, false
+		}
+		p.expr = expr
+	}
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		p.num, p.isNum = n, true
+	}
+	return p, true
+}
+
+// Eval returns true if the output of the command satisfies the predicate.
+// An error, a nil output and a path not found in the output never do. With
+// a path matching multiple values, any of them satisfying it is enough.
+func (p *Predicate) Eval(r *wire.Response) bool {
+	if r.Err != "" {
+		return false
+	}
+	if p.expr == nil {
+		return p.compare(responseOperand(r))
+	}
+
+	doc := jsonDocument(r)
+	if doc == nil {
+		return false
+	}
+	for _, v := range p.expr.Get(doc) {
+		if p.compare(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare compares the operand with the value of the predicate, as numbers
+// if both are numbers, as strings otherwise.
+func (p *Predicate) compare(operand any) bool {
+	var s string
+	switch v := operand.(type) {
+	case nil:
+		return false
+	case int64:
+		return p.compareNum(float64(v), strconv.FormatInt(v, 10))
+	case float64:
+		return p.compareNum(v, strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return p.compareNum(n, v)
+		}
+		s = v
+	case bool:
+		s = strconv.FormatBool(v)
+	default:
+		b, err := sonic.Marshal(v)
+		if err != nil {
+			return false
+		}
+		s = string(b)
+	}
+	return holds(p.Op, strings.Compare(s, p.Value))
+}
+
+func (p *Predicate) compareNum(n float64, s string) bool {
+	if !p.isNum {
+		return holds(p.Op, strings.Compare(s, p.Value))
+	}
+	switch {
+	case n < p.num:
+		return holds(p.Op, -1)
+	case n > p.num:
+		return holds(p.Op, 1)
+	default:
+		return holds(p.Op, 0)
+	}
+}
+
+// holds returns true if the operator holds for the result of the comparison.
+func holds(op string, cmp int) bool {
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// responseOperand returns the scalar output of the command, nil if none.
+func responseOperand(r *wire.Response) any {
+	switch v := r.Value.(type) {
+	case *wire.Response_VInt:
+		return v.VInt
+	case *wire.Response_VFloat:
+		return v.VFloat
+	case *wire.Response_VStr:
+		return v.VStr
+	}
+	return nil
+}
+
+// jsonDocument returns the output of the command as a JSON document: a
+// string holding JSON, a map of fields, e.g. of HGETALL, or a list.
+func jsonDocument(r *wire.Response) any {
+	switch {
+	case r.VSsMap != nil:
+		doc := make(map[string]any, len(r.VSsMap))
+		for k, v := range r.VSsMap {
+			doc[k] = v
+		}
+		return doc
+	case r.VList != nil:
+		return (&structpb.ListValue{Values: r.VList}).AsSlice()
+	}
+
+	s, ok := r.Value.(*wire.Response_VStr)
+	if !ok {
+		return nil
+	}
+	var doc any
+	if err := sonic.UnmarshalString(s.VStr, &doc); err != nil {
+		return nil
+	}
+	return doc
+}
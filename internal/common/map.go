@@ -9,4 +9,9 @@ type ITable[K comparable, V any] interface {
 	Delete(key K)
 	Len() int
 	All(func(k K, obj V) bool)
+	// Scan calls f with count keys or so, in the order of the high 32 bits
+	// of their hashes, starting with the keys with these bits not below
+	// cursor, and returns the cursor of the next keys, 0 once all the keys
+	// are scanned.
+	Scan(cursor uint64, count int, f func(k K, obj V)) uint64
 }
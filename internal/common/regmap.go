@@ -3,11 +3,19 @@
 
 package common
 
-import "sync"
+import (
+	"hash/maphash"
+	"math"
+	"sync"
+)
 
 type RegMap[K comparable, V any] struct {
 	M  map[K]V
 	mu sync.RWMutex
+
+	// seed is the seed of the hashes the keys are scanned in the order of.
+	seed     maphash.Seed
+	seedOnce sync.Once
 }
 
 func (t *RegMap[K, V]) Put(key K, value V) {
@@ -44,3 +52,29 @@ func (t *RegMap[K, V]) All(f func(k K, obj V) bool) {
 		}
 	}
 }
+
+// Scan calls f with count keys of the map or so, in the order of the high 32
+// bits of their hashes, starting with the keys with these bits not below
+// cursor, and returns the cursor of the next keys, 0 once all the keys are
+// scanned, the way SwissTable.Scan does. The map having no order of its own,
+// every call sorts a snapshot of the keys past the cursor.
+func (t *RegMap[K, V]) Scan(cursor uint64, count int, f func(k K, obj V)) uint64 {
+	t.seedOnce.Do(func() { t.seed = maphash.MakeSeed() })
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var entries []scanEntry[K, V]
+	for k, v := range t.M {
+		if hi := maphash.Comparable(t.seed, k) >> 32; hi >= cursor {
+			entries = append(entries, scanEntry[K, V]{hi, k, v})
+		}
+	}
+	entries, cursor = scanPage(entries, math.MaxUint32+1, max(count, 1))
+	for _, e := range entries {
+		f(e.key, e.value)
+	}
+	if cursor > math.MaxUint32 {
+		return 0
+	}
+	return cursor
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package common
+
+import (
+	"hash/maphash"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestRegMapScan(t *testing.T) {
+	m := &RegMap[string, int]{M: map[string]int{}}
+	for i := 0; i < 1000; i++ {
+		m.Put("key"+strconv.Itoa(i), i)
+	}
+
+	// The keys held all along are scanned exactly once, in the order of
+	// their hashes, while keys are added and deleted.
+	seen := map[string]int{}
+	var hashes []uint64
+	cursor, added := uint64(0), 0
+	for {
+		cursor = m.Scan(cursor, 10, func(k string, _ int) {
+			seen[k]++
+			hashes = append(hashes, maphash.Comparable(m.seed, k)>>32)
+		})
+		m.Put("new"+strconv.Itoa(added), added)
+		m.Delete("new" + strconv.Itoa(added-1))
+		added++
+		if cursor == 0 {
+			break
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		if n := seen["key"+strconv.Itoa(i)]; n != 1 {
+			t.Fatalf("expected key%d to be scanned once, got %d", i, n)
+		}
+	}
+	if !slices.IsSorted(hashes) {
+		t.Fatal("expected the keys to be scanned in the order of their hashes")
+	}
+}
@@ -0,0 +1,462 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package common
+
+import (
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"math/rand"
+	"slices"
+	"sync"
+)
+
+const (
+	// swissGroupSize is the number of slots of a group, one control byte each.
+	swissGroupSize = 8
+	// swissMaxGroups is the number of groups of a table beyond which the table
+	// is split in two rather than grown, which bounds the work of a resize.
+	swissMaxGroups = 128
+
+	// A control byte is either empty, deleted, or holds the 7 low bits of
+	// the hash of the key of a full slot.
+	ctrlEmpty   uint8 = 0x80
+	ctrlDeleted uint8 = 0xFE
+
+	lsbs uint64 = 0x0101010101010101
+	msbs uint64 = 0x8080808080808080
+
+	// emptyCtrl is the control word of an empty group.
+	emptyCtrl = lsbs * uint64(ctrlEmpty)
+)
+
+// swissSlot holds a key along with its value, inline in the slots of a group.
+type swissSlot[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+type swissGroup[K comparable, V any] struct {
+	// ctrl are the control bytes of the slots, packed in a word such that
+	// the slots of a group are matched at once. The word is stored XORed with
+	// emptyCtrl, such that the groups of a new table are empty as allocated.
+	ctrl  uint64
+	slots [swissGroupSize]swissSlot[K, V]
+}
+
+// matchH2 returns the bits set for the control bytes equal to h2, with
+// rare false positives, which the comparison of the keys rules out.
+func matchH2(ctrl uint64, h2 uint8) uint64 {
+	x := ctrl ^ (lsbs * uint64(h2))
+	return (x - lsbs) &^ x & msbs
+}
+
+func matchEmpty(ctrl uint64) uint64 {
+	return ctrl &^ (ctrl << 6) & msbs
+}
+
+func matchEmptyOrDeleted(ctrl uint64) uint64 {
+	return ctrl & msbs
+}
+
+func matchFull(ctrl uint64) uint64 {
+	return ^ctrl & msbs
+}
+
+func (g *swissGroup[K, V]) ctrlWord() uint64 {
+	return g.ctrl ^ emptyCtrl
+}
+
+func (g *swissGroup[K, V]) setCtrl(i int, c uint8) {
+	shift := uint(i) * 8
+	g.ctrl = (g.ctrlWord()&^(0xFF<<shift) | uint64(c)<<shift) ^ emptyCtrl
+}
+
+// swissTable holds the keys of a range of hashes, the keys the high depth
+// bits of the hashes of which are the same. A key is stored in the first
+// group with a free slot from its home group on, the home group being
+// picked by the bits of the hash that follow, such that the groups follow
+// the order of the hashes. A slot is never emptied once full, only marked
+// deleted, such that no key is stored past a group with an empty slot from
+// its home group, which the lookups and the scans rely on.
+type swissTable[K comparable, V any] struct {
+	groups []swissGroup[K, V]
+	depth  uint
+	// groupShift is the shift of the high 32 bits of a hash, its high depth
+	// bits cleared, to the index of its home group.
+	groupShift uint
+	// used is the number of the slots either full or deleted, n of them full.
+	used int
+	n    int
+}
+
+func newSwissTable[K comparable, V any](numGroups int, depth uint) *swissTable[K, V] {
+	return &swissTable[K, V]{
+		groups:     make([]swissGroup[K, V], numGroups),
+		depth:      depth,
+		groupShift: 32 - depth - uint(bits.TrailingZeros(uint(numGroups))),
+	}
+}
+
+func (t *swissTable[K, V]) capacity() int {
+	return len(t.groups) * swissGroupSize
+}
+
+// span returns the number of the hashes, the high 32 bits of them, in the
+// range of the table.
+func (t *swissTable[K, V]) span() uint64 {
+	return 1 << (32 - t.depth)
+}
+
+// home returns the home group of the high 32 bits of a hash.
+func (t *swissTable[K, V]) home(hi uint32) int {
+	return int((uint64(hi) & (t.span() - 1)) >> t.groupShift)
+}
+
+// find returns the group and the slot of the key, if the table holds it.
+func (t *swissTable[K, V]) find(k K, hash uint64) (gi, i int, ok bool) {
+	mask := len(t.groups) - 1
+	gi = t.home(uint32(hash >> 32))
+	for p := 0; p < len(t.groups); p++ {
+		g := &t.groups[gi]
+		for m := matchH2(g.ctrlWord(), uint8(hash&0x7F)); m != 0; m &= m - 1 {
+			i = bits.TrailingZeros64(m) / 8
+			if g.slots[i].key == k {
+				return gi, i, true
+			}
+		}
+		if matchEmpty(g.ctrlWord()) != 0 {
+			break
+		}
+		gi = (gi + 1) & mask
+	}
+	return 0, 0, false
+}
+
+// insert stores the key, which the table does not hold, in the first free
+// slot from its home group on. The table is expected to have a free slot.
+func (t *swissTable[K, V]) insert(k K, v V, hash uint64) {
+	mask := len(t.groups) - 1
+	gi := t.home(uint32(hash >> 32))
+	for {
+		g := &t.groups[gi]
+		if m := matchEmptyOrDeleted(g.ctrlWord()); m != 0 {
+			i := bits.TrailingZeros64(m) / 8
+			if matchEmpty(g.ctrlWord())&(0x80<<(uint(i)*8)) != 0 {
+				t.used++
+			}
+			g.setCtrl(i, uint8(hash&0x7F))
+			g.slots[i] = swissSlot[K, V]{key: k, value: v}
+			t.n++
+			return
+		}
+		gi = (gi + 1) & mask
+	}
+}
+
+func (t *swissTable[K, V]) remove(gi, i int) {
+	g := &t.groups[gi]
+	g.setCtrl(i, ctrlDeleted)
+	g.slots[i] = swissSlot[K, V]{}
+	t.n--
+}
+
+// all calls f with the full slots of the table, starting at the given group,
+// until f returns false, and returns false if f did.
+func (t *swissTable[K, V]) all(start int, f func(g *swissGroup[K, V], i int) bool) bool {
+	for p := 0; p < len(t.groups); p++ {
+		g := &t.groups[(start+p)%len(t.groups)]
+		for m := matchFull(g.ctrlWord()); m != 0; m &= m - 1 {
+			if !f(g, bits.TrailingZeros64(m)/8) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SwissTable is an open-addressing hash table implementing ITable, laid out
+// as the Swiss tables are: the keys and the values are stored inline in
+// groups of slots, along with a control byte per slot matched a group at a
+// time. The keys are spread across tables of up to swissMaxGroups groups by
+// a directory indexed by the high bits of their hashes, every table growing
+// or splitting in two on its own once full, such that a resize never moves
+// more than the keys of a table and never stops the world.
+//
+// The tables follow the order of the hashes, as do the groups of a table,
+// which is the order the keys are scanned in, see Scan.
+type SwissTable[K comparable, V any] struct {
+	mu   sync.RWMutex
+	hash func(seed maphash.Seed, k K) uint64
+	seed maphash.Seed
+	// dir holds the table of every range of hashes of depth, a table of a
+	// lower depth being held by the entries of all the ranges it spans.
+	dir   []*swissTable[K, V]
+	depth uint
+	n     int
+}
+
+// NewSwissTable returns an empty SwissTable hashing the keys with the given
+// function, such as maphash.String.
+func NewSwissTable[K comparable, V any](hash func(seed maphash.Seed, k K) uint64) *SwissTable[K, V] {
+	return &SwissTable[K, V]{
+		hash: hash,
+		seed: maphash.MakeSeed(),
+		dir:  []*swissTable[K, V]{newSwissTable[K, V](1, 0)},
+	}
+}
+
+// index returns the entry of the directory of the high 32 bits of a hash.
+func (s *SwissTable[K, V]) index(hi uint32) int {
+	return int(uint64(hi) >> (32 - s.depth))
+}
+
+func (s *SwissTable[K, V]) table(hash uint64) *swissTable[K, V] {
+	return s.dir[s.index(uint32(hash>>32))]
+}
+
+func (s *SwissTable[K, V]) Put(key K, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := s.hash(s.seed, key)
+	t := s.table(hash)
+	if gi, i, ok := t.find(key, hash); ok {
+		t.groups[gi].slots[i].value = value
+		return
+	}
+	if t.used >= t.capacity()*7/8 {
+		s.resize(t, hash)
+		t = s.table(hash)
+	}
+	t.insert(key, value, hash)
+	s.n++
+}
+
+// resize replaces the full table holding the hash by a table of the same
+// size if it is mostly deleted slots, by a table twice as large, or by two
+// tables of the next depth splitting its range if it is as large as a table
+// gets.
+func (s *SwissTable[K, V]) resize(t *swissTable[K, V], hash uint64) {
+	switch {
+	case t.n+1 <= t.capacity()*7/16:
+		s.replace(t, hash, newSwissTable[K, V](len(t.groups), t.depth))
+	case len(t.groups) < swissMaxGroups:
+		s.replace(t, hash, newSwissTable[K, V](2*len(t.groups), t.depth))
+	default:
+		s.split(t, hash)
+	}
+}
+
+// entries returns the first entry of the directory holding the table of the
+// hash, and the number of the entries holding it.
+func (s *SwissTable[K, V]) entries(t *swissTable[K, V], hash uint64) (first, n int) {
+	n = 1 << (s.depth - t.depth)
+	return s.index(uint32(hash>>32)) &^ (n - 1), n
+}
+
+// replace moves the keys of the table holding the hash to the new table,
+// of the same range.
+func (s *SwissTable[K, V]) replace(t *swissTable[K, V], hash uint64, nt *swissTable[K, V]) {
+	t.all(0, func(g *swissGroup[K, V], i int) bool {
+		nt.insert(g.slots[i].key, g.slots[i].value, s.hash(s.seed, g.slots[i].key))
+		return true
+	})
+	first, n := s.entries(t, hash)
+	for i := first; i < first+n; i++ {
+		s.dir[i] = nt
+	}
+}
+
+// split moves the keys of the table holding the hash to two tables of the
+// next depth, the directory being doubled first if the table is of its depth.
+func (s *SwissTable[K, V]) split(t *swissTable[K, V], hash uint64) {
+	if t.depth == s.depth {
+		dir := make([]*swissTable[K, V], 2*len(s.dir))
+		for i, dt := range s.dir {
+			dir[2*i], dir[2*i+1] = dt, dt
+		}
+		s.dir, s.depth = dir, s.depth+1
+	}
+
+	left := newSwissTable[K, V](len(t.groups), t.depth+1)
+	right := newSwissTable[K, V](len(t.groups), t.depth+1)
+	t.all(0, func(g *swissGroup[K, V], i int) bool {
+		hash := s.hash(s.seed, g.slots[i].key)
+		if hash>>32&(t.span()>>1) == 0 {
+			left.insert(g.slots[i].key, g.slots[i].value, hash)
+		} else {
+			right.insert(g.slots[i].key, g.slots[i].value, hash)
+		}
+		return true
+	})
+
+	// The first half of the entries of the table goes to the left table,
+	// the second half to the right one.
+	first, n := s.entries(t, hash)
+	for i := first; i < first+n; i++ {
+		if i < first+n/2 {
+			s.dir[i] = left
+		} else {
+			s.dir[i] = right
+		}
+	}
+}
+
+func (s *SwissTable[K, V]) Get(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	hash := s.hash(s.seed, key)
+	t := s.table(hash)
+	if gi, i, ok := t.find(key, hash); ok {
+		return t.groups[gi].slots[i].value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (s *SwissTable[K, V]) Delete(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hash := s.hash(s.seed, key)
+	t := s.table(hash)
+	if gi, i, ok := t.find(key, hash); ok {
+		t.remove(gi, i)
+		s.n--
+	}
+}
+
+func (s *SwissTable[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.n
+}
+
+// All calls f with the keys and the values of the table until f returns
+// false. The iteration starts at a random table and group, such that the
+// first keys iterated over are a sample of the keys of the table.
+func (s *SwissTable[K, V]) All(f func(k K, obj V) bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// The iteration starts at the first entry of the table picked, such
+	// that a table held by several entries is iterated over once.
+	start := rand.Intn(len(s.dir))
+	start &^= 1<<(s.depth-s.dir[start].depth) - 1
+	for p := 0; p < len(s.dir); p++ {
+		idx := (start + p) % len(s.dir)
+		t := s.dir[idx]
+		if idx > 0 && s.dir[idx-1] == t {
+			continue
+		}
+		if !t.all(rand.Intn(len(t.groups)), func(g *swissGroup[K, V], i int) bool {
+			return f(g.slots[i].key, g.slots[i].value)
+		}) {
+			return
+		}
+	}
+}
+
+// scanEntry is a key found by a scan, along with the high 32 bits of its hash.
+type scanEntry[K comparable, V any] struct {
+	hi    uint64
+	key   K
+	value V
+}
+
+// scanPage sorts the keys found by a scan by their hashes and keeps the first
+// count keys or so, along with the cursor of the next keys, the cursor of the
+// keys not found yet being returned if all the keys are kept.
+func scanPage[K comparable, V any](entries []scanEntry[K, V], cursor uint64, count int) ([]scanEntry[K, V], uint64) {
+	slices.SortFunc(entries, func(a, b scanEntry[K, V]) int {
+		switch {
+		case a.hi < b.hi:
+			return -1
+		case a.hi > b.hi:
+			return 1
+		}
+		return 0
+	})
+
+	if len(entries) > count {
+		// The keys of the same hash are scanned together, the cursor
+		// telling the hashes apart only.
+		end := count
+		for end < len(entries) && entries[end].hi == entries[count-1].hi {
+			end++
+		}
+		if end < len(entries) {
+			entries, cursor = entries[:end], entries[end].hi
+		}
+	}
+	return entries, cursor
+}
+
+// scan appends the keys of the table with a hash, the high 32 bits of it,
+// not below cursor to the entries, until a group with an empty slot once
+// count keys are appended, and returns the bound below which all these keys
+// are appended.
+func (t *swissTable[K, V]) scan(entries []scanEntry[K, V], cursor uint64, count int, hash func(K) uint64) ([]scanEntry[K, V], uint64) {
+	collect := func(g *swissGroup[K, V], keep func(hi uint32) bool) {
+		for m := matchFull(g.ctrlWord()); m != 0; m &= m - 1 {
+			i := bits.TrailingZeros64(m) / 8
+			if hi := uint32(hash(g.slots[i].key) >> 32); uint64(hi) >= cursor && keep(hi) {
+				entries = append(entries, scanEntry[K, V]{uint64(hi), g.slots[i].key, g.slots[i].value})
+			}
+		}
+	}
+
+	lo, want := cursor&^(t.span()-1), len(entries)+count
+	last := len(t.groups) - 1
+	for p := t.home(uint32(cursor)); p < last; p++ {
+		// The keys stored past their home group, wrapped around the end of
+		// the table, are found by the scan of the last group.
+		collect(&t.groups[p], func(hi uint32) bool { return t.home(hi) <= p })
+		// No key being stored past a group with an empty slot, the keys of
+		// the groups up to it are all found.
+		if len(entries) >= want && matchEmpty(t.groups[p].ctrlWord()) != 0 {
+			return entries, lo + uint64(p+1)<<t.groupShift
+		}
+	}
+
+	collect(&t.groups[last], func(uint32) bool { return true })
+	// Unless the last group has an empty slot, the keys of the last groups
+	// are wrapped around to the first groups, up to a group with an empty slot.
+	if matchEmpty(t.groups[last].ctrlWord()) == 0 {
+		for q := 0; q < last; q++ {
+			collect(&t.groups[q], func(hi uint32) bool { return t.home(hi) > q })
+			if matchEmpty(t.groups[q].ctrlWord()) != 0 {
+				break
+			}
+		}
+	}
+	return entries, lo + t.span()
+}
+
+// Scan calls f with count keys of the table or so, in the order of the high
+// 32 bits of their hashes, starting with the keys with these bits not below
+// cursor, and returns the cursor of the next keys, 0 once all the keys are
+// scanned. Scanning from 0 until 0 is returned, every key held by the table
+// all along is scanned exactly once, whether the table is resized in between
+// or not.
+func (s *SwissTable[K, V]) Scan(cursor uint64, count int, f func(k K, obj V)) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	count = max(count, 1)
+
+	var entries []scanEntry[K, V]
+	for len(entries) < count && cursor <= math.MaxUint32 {
+		entries, cursor = s.dir[s.index(uint32(cursor))].scan(entries, cursor, count-len(entries), func(k K) uint64 {
+			return s.hash(s.seed, k)
+		})
+	}
+	entries, cursor = scanPage(entries, cursor, count)
+	for _, e := range entries {
+		f(e.key, e.value)
+	}
+	if cursor > math.MaxUint32 {
+		return 0
+	}
+	return cursor
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package common
+
+import (
+	"hash/maphash"
+	"math/rand"
+	"runtime"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSwissTable(t *testing.T) {
+	s := NewSwissTable[string, int](maphash.String)
+	m := map[string]int{}
+	r := rand.New(rand.NewSource(1))
+
+	// The table is checked against a map across its resizes, as it grows
+	// and as its deleted slots pile up.
+	for i := 0; i < 200000; i++ {
+		k := "key" + strconv.Itoa(r.Intn(20000))
+		switch r.Intn(3) {
+		case 0, 1:
+			s.Put(k, i)
+			m[k] = i
+		case 2:
+			s.Delete(k)
+			delete(m, k)
+		}
+		if i%997 == 0 {
+			want, exists := m[k]
+			if v, ok := s.Get(k); ok != exists || v != want {
+				t.Fatalf("expected %d for %s, got %d", want, k, v)
+			}
+		}
+	}
+
+	if s.Len() != len(m) {
+		t.Fatalf("expected %d keys, got %d", len(m), s.Len())
+	}
+	for k, v := range m {
+		if got, ok := s.Get(k); !ok || got != v {
+			t.Fatalf("expected %d for %s, got %d", v, k, got)
+		}
+	}
+	seen := map[string]bool{}
+	s.All(func(k string, v int) bool {
+		if seen[k] || m[k] != v {
+			t.Fatalf("unexpected key %s", k)
+		}
+		seen[k] = true
+		return true
+	})
+	if len(seen) != len(m) {
+		t.Fatalf("expected %d keys iterated over, got %d", len(m), len(seen))
+	}
+}
+
+func TestSwissTableScan(t *testing.T) {
+	s := NewSwissTable[string, int](maphash.String)
+	for i := 0; i < 5000; i++ {
+		s.Put("key"+strconv.Itoa(i), i)
+	}
+
+	// The keys held all along are scanned exactly once, in the order of
+	// their hashes, while the table is resized by the keys added.
+	seen := map[string]int{}
+	var hashes []uint64
+	cursor, added := uint64(0), 0
+	for {
+		cursor = s.Scan(cursor, 10, func(k string, _ int) {
+			seen[k]++
+			hashes = append(hashes, maphash.String(s.seed, k)>>32)
+		})
+		for j := 0; j < 20; j++ {
+			s.Put("new"+strconv.Itoa(added), added)
+			added++
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	for i := 0; i < 5000; i++ {
+		if n := seen["key"+strconv.Itoa(i)]; n != 1 {
+			t.Fatalf("expected key%d to be scanned once, got %d", i, n)
+		}
+	}
+	if !slices.IsSorted(hashes) {
+		t.Fatal("expected the keys to be scanned in the order of their hashes")
+	}
+	if added < 10000 {
+		t.Fatalf("expected the table to grow during the scan, got %d keys added", added)
+	}
+}
+
+// benchmarkTables are the tables compared by the benchmarks.
+var benchmarkTables = []struct {
+	name string
+	new  func() ITable[string, *int]
+}{
+	{"regmap", func() ITable[string, *int] { return &RegMap[string, *int]{M: make(map[string]*int)} }},
+	{"swiss", func() ITable[string, *int] { return NewSwissTable[string, *int](maphash.String) }},
+}
+
+// BenchmarkTableMemory reports the memory used per key by the tables,
+// the keys and the values being allocated apart from the tables.
+func BenchmarkTableMemory(b *testing.B) {
+	const numKeys = 1000000
+	keys := make([]string, numKeys)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	v := new(int)
+
+	for _, table := range benchmarkTables {
+		b.Run(table.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				t := table.new()
+				for _, k := range keys {
+					t.Put(k, v)
+				}
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/numKeys, "bytes/key")
+				runtime.KeepAlive(t)
+			}
+		})
+	}
+}
+
+// BenchmarkTableLatency reports the latencies of the puts of new keys and
+// of the gets of the tables holding a million keys, the puts including those
+// resizing the tables.
+func BenchmarkTableLatency(b *testing.B) {
+	const numKeys = 1000000
+	for _, table := range benchmarkTables {
+		b.Run(table.name, func(b *testing.B) {
+			t := table.new()
+			v := new(int)
+			for i := 0; i < numKeys; i++ {
+				t.Put("key"+strconv.Itoa(i), v)
+			}
+			r := rand.New(rand.NewSource(1))
+
+			latencies := make([]time.Duration, b.N)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				put, get := "new"+strconv.Itoa(i), "key"+strconv.Itoa(r.Intn(numKeys))
+				start := time.Now()
+				t.Put(put, v)
+				t.Get(get)
+				latencies[i] = time.Since(start)
+			}
+			b.StopTimer()
+
+			slices.Sort(latencies)
+			b.ReportMetric(float64(latencies[b.N/2].Nanoseconds()), "p50-ns")
+			b.ReportMetric(float64(latencies[b.N*99/100].Nanoseconds()), "p99-ns")
+			b.ReportMetric(float64(latencies[b.N-1].Nanoseconds()), "max-ns")
+		})
+	}
+}
@@ -56,12 +56,19 @@ var (
 	ErrInternalServer             = errors.New("internal server error, unable to process command")                       // Represents a generic internal server error.
 	ErrAuth                       = errors.New("AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?")
 	ErrAborted                    = errors.New("server received ABORT command")
+	ErrServerShuttingDown         = errors.New("server is shutting down")
 	ErrEmptyCommand               = errors.New("empty command")
 	ErrInvalidIPAddress           = errors.New("invalid IP address")
 	ErrInvalidFingerprint         = errors.New("invalid fingerprint")
 	ErrKeyDoesNotExist            = errors.New("could not perform this operation on a key that doesn't exist")
 	ErrKeyExists                  = errors.New("key exists")
 	ErrUnknownObjectType          = errors.New("unknown object type")
+	ErrUnknownSubscription        = errors.New("unknown subscription, re-issue the .WATCH command")
+	ErrWatchModeRequired          = errors.New("this command requires a connection in watch mode")
+	ErrWALDisabled                = errors.New("CDC requires the WAL, see enable-wal")
+	ErrWALFailed                  = errors.New("the write could not be logged to the WAL, the writes are rejected until it can be, see the server logs")
+	ErrOutOfMemory                = errors.New("OOM command not allowed when the shard is full, see eviction-policy")
+	ErrOutOfKeys                  = errors.New("OOM command not allowed when the key limit is reached, see max-keys and eviction-policy")
 
 	ErrInvalidValue = func(command, param string) error {
 		return fmt.Errorf("invalid value for a parameter in '%s' command for %s parameter", strings.ToUpper(command), strings.ToUpper(param))
@@ -110,6 +117,10 @@ var (
 		return fmt.Errorf("wrong type of path value - expected %s but found %s", expectedType, actualType) // Signals an unexpected type received when an integer was expected.
 	}
 
+	ErrRecordsNotRetained = func(lsn, first uint64) error {
+		return fmt.Errorf("the records after lsn %d are no longer retained, the oldest retained is %d", lsn, first) // Signals a gap in a change stream resumed from an LSN older than the WAL retains.
+	}
+
 	ErrUnknownCmd = func(cmd string) error {
 		return fmt.Errorf("ERROR unknown command '%v'", cmd) // Indicates that an unsupported encoding type was provided.
 	}
@@ -4989,7 +4989,7 @@ func evalCOPYObject(cd *cmd.DiceDBCmd, store *dstore.Store) *EvalResponse {
 	store.Put(key, copyObj)
 
 	if exDurationMs > 0 {
-		store.SetExpiry(copyObj, exDurationMs)
+		store.SetKeyExpiry(key, copyObj, exDurationMs)
 	}
 
 	return &EvalResponse{
@@ -5460,7 +5460,7 @@ func evalDBSize(args []string, store *dstore.Store) *EvalResponse {
 
 	// Expired keys must be explicitly deleted since the cronFrequency for cleanup is configurable.
 	// A longer delay may prevent timely cleanup, leading to incorrect DBSIZE results.
-	dstore.DeleteExpiredKeys(store)
+	dstore.DeleteExpiredKeys(store, math.MaxInt64)
 
 	return makeEvalResult(store.GetDBSize())
 }
@@ -5689,7 +5689,7 @@ func evalObjectIdleTime(key string, store *dstore.Store) *EvalResponse {
 		return makeEvalResult(NIL)
 	}
 
-	return makeEvalResult(int64(dstore.GetIdleTime(obj.LastAccessedAt)))
+	return makeEvalResult(int64(dstore.GetIdleTime(dstore.LastAccessedAt(obj))))
 }
 
 func evalOBJECT(args []string, store *dstore.Store) *EvalResponse {
@@ -49,6 +49,15 @@ func (ep *Epoll) Subscribe(event Event) error {
 	return nil
 }
 
+// Unsubscribe unsubscribes from the given event
+func (ep *Epoll) Unsubscribe(event Event) error {
+	nativeEvent := event.toNative()
+	if err := syscall.EpollCtl(ep.fd, syscall.EPOLL_CTL_DEL, event.Fd, &nativeEvent); err != nil {
+		return fmt.Errorf("epoll unsubscribe: %w", err)
+	}
+	return nil
+}
+
 // Poll polls for all the subscribed events simultaneously
 // and returns all the events that were triggered
 // It blocks until at least one event is triggered or the timeout is reached
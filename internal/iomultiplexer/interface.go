@@ -12,6 +12,9 @@ type IOMultiplexer interface {
 	// When the event is triggered, the Poll method will return it
 	Subscribe(event Event) error
 
+	// Unsubscribe stops monitoring the given event
+	Unsubscribe(event Event) error
+
 	// Poll polls for all the subscribed events simultaneously
 	// and returns all the events that were triggered
 	// It blocks until at least one event is triggered or the timeout is reached
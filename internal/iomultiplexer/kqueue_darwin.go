@@ -48,6 +48,14 @@ func (kq *KQueue) Subscribe(event Event) error {
 	return nil
 }
 
+// Unsubscribe unsubscribes from the given event
+func (kq *KQueue) Unsubscribe(event Event) error {
+	if unsubscribed, err := syscall.Kevent(kq.fd, []syscall.Kevent_t{event.toNative(syscall.EV_DELETE)}, nil, nil); err != nil || unsubscribed == -1 {
+		return fmt.Errorf("kqueue unsubscribe: %w", err)
+	}
+	return nil
+}
+
 // Poll polls for all the subscribed events simultaneously
 // and returns all the events that were triggered
 // It blocks until at least one event is triggered or the timeout is reached
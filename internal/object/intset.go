@@ -0,0 +1,132 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package object
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+	"sort"
+)
+
+// IntSet is a sorted set of integers packed in a single byte slice, every
+// integer taking the width of the widest one, 2, 4 or 8 bytes. The set is
+// upgraded to a wider width the first time an integer does not fit.
+type IntSet struct {
+	buf   []byte
+	width uint8
+}
+
+// Len returns the number of integers in the set.
+func (s *IntSet) Len() int {
+	if s.width == 0 {
+		return 0
+	}
+	return len(s.buf) / int(s.width)
+}
+
+// Size returns the estimated size of the set in memory, in bytes.
+func (s *IntSet) Size() int {
+	return sliceHeaderSize + 8 + cap(s.buf)
+}
+
+// widthOf returns the number of bytes the integer takes in a set.
+func widthOf(v int64) uint8 {
+	switch {
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return 2
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return 4
+	default:
+		return 8
+	}
+}
+
+func (s *IntSet) at(i int) int64 {
+	b := s.buf[i*int(s.width):]
+	switch s.width {
+	case 2:
+		return int64(int16(binary.LittleEndian.Uint16(b)))
+	case 4:
+		return int64(int32(binary.LittleEndian.Uint32(b)))
+	default:
+		return int64(binary.LittleEndian.Uint64(b))
+	}
+}
+
+// encode returns the integer encoded in the given width.
+func encode(v int64, width uint8) []byte {
+	b := make([]byte, width)
+	switch width {
+	case 2:
+		binary.LittleEndian.PutUint16(b, uint16(v))
+	case 4:
+		binary.LittleEndian.PutUint32(b, uint32(v))
+	default:
+		binary.LittleEndian.PutUint64(b, uint64(v))
+	}
+	return b
+}
+
+// search returns the position of the integer in the set, or the one
+// it is to be inserted at, and true if the set holds the integer.
+func (s *IntSet) search(v int64) (int, bool) {
+	n := s.Len()
+	i := sort.Search(n, func(i int) bool { return s.at(i) >= v })
+	return i, i < n && s.at(i) == v
+}
+
+// Contains returns true if the set holds the integer.
+func (s *IntSet) Contains(v int64) bool {
+	_, ok := s.search(v)
+	return ok
+}
+
+// Add adds the integer to the set, and returns true if the set did not hold it.
+func (s *IntSet) Add(v int64) bool {
+	if w := widthOf(v); w > s.width {
+		s.upgrade(w)
+	}
+	i, ok := s.search(v)
+	if ok {
+		return false
+	}
+	off := i * int(s.width)
+	s.buf = slices.Insert(s.buf, off, encode(v, s.width)...)
+	return true
+}
+
+// upgrade re-encodes the integers of the set in the given width.
+func (s *IntSet) upgrade(width uint8) {
+	buf := make([]byte, 0, s.Len()*int(width))
+	for i := 0; i < s.Len(); i++ {
+		buf = append(buf, encode(s.at(i), width)...)
+	}
+	s.buf, s.width = buf, width
+}
+
+// Remove removes the integer from the set, and returns true if the set held it.
+func (s *IntSet) Remove(v int64) bool {
+	i, ok := s.search(v)
+	if !ok {
+		return false
+	}
+	off := i * int(s.width)
+	s.buf = slices.Delete(s.buf, off, off+int(s.width))
+	return true
+}
+
+// All calls f with the integers of the set, in ascending order, until f returns false.
+func (s *IntSet) All(f func(v int64) bool) {
+	for i := 0; i < s.Len(); i++ {
+		if !f(s.at(i)) {
+			return
+		}
+	}
+}
+
+// DeepCopy returns a copy of the set.
+func (s *IntSet) DeepCopy() interface{} {
+	return &IntSet{buf: slices.Clone(s.buf), width: s.width}
+}
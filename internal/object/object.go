@@ -23,6 +23,10 @@ package object
 //     and to simplify management by not combining
 //     `Type` and `LastAccessedAt` into a single integer.
 //
+//   - Size: A uint32 field holding the estimated number of bytes the object, along
+//     with its key, uses in the store. It is set by the store when the object is put,
+//     so that the memory accounted for a key is released exactly when the key is.
+//
 //   - Value: An `interface{}` type that holds the actual data of the object. This could
 //     represent any type of data, allowing flexibility to store different kinds of
 //     objects (e.g., strings, numbers, complex data structures like lists or maps).
@@ -34,6 +38,10 @@ type Obj struct {
 	// It helps track when the object was last accessed and may be used for cache eviction or freshness tracking.
 	LastAccessedAt uint32
 
+	// Size is the estimated size, in bytes, of the key and of the object
+	// in the store holding it, accounted for in the memory used by the store.
+	Size uint32
+
 	// Value holds the actual content or data of the object, which can be of any type.
 	// This allows flexibility in storing various kinds of objects (simple or complex).
 	Value interface{}
@@ -0,0 +1,146 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package object
+
+import (
+	"encoding/binary"
+	"slices"
+)
+
+// PackedList is a list of strings packed in a single byte slice, every string
+// prefixed with its length as a uvarint. It trades the constant time lookups
+// of a map for a fraction of its memory, which is what the small collections
+// are encoded as, the listpack encoding.
+//
+// The lists of pairs, such as the fields and the values of a hash, are stored
+// as their elements one after the other.
+type PackedList struct {
+	buf []byte
+	n   int
+}
+
+// Len returns the number of strings in the list.
+func (l *PackedList) Len() int {
+	return l.n
+}
+
+// Size returns the estimated size of the list in memory, in bytes.
+func (l *PackedList) Size() int {
+	return sliceHeaderSize + 8 + cap(l.buf)
+}
+
+// entry returns the bounds of the string at the offset, its end being the offset of the next string.
+func (l *PackedList) entry(off int) (start, end int) {
+	n, w := binary.Uvarint(l.buf[off:])
+	start = off + w
+	return start, start + int(n)
+}
+
+// Append appends the strings to the list.
+func (l *PackedList) Append(ss ...string) {
+	for _, s := range ss {
+		l.buf = binary.AppendUvarint(l.buf, uint64(len(s)))
+		l.buf = append(l.buf, s...)
+	}
+	l.n += len(ss)
+}
+
+// All calls f with the strings of the list, in order, until f returns false.
+func (l *PackedList) All(f func(s string) bool) {
+	for off := 0; off < len(l.buf); {
+		start, end := l.entry(off)
+		if !f(string(l.buf[start:end])) {
+			return
+		}
+		off = end
+	}
+}
+
+// find returns the offset of the first string equal to s among the strings
+// at the positions multiple of step, -1 if none is, along with the offset of
+// the string following it.
+func (l *PackedList) find(s string, step int) (off, next int) {
+	for i := 0; off < len(l.buf); i++ {
+		start, end := l.entry(off)
+		if i%step == 0 && string(l.buf[start:end]) == s {
+			return off, end
+		}
+		off = end
+	}
+	return -1, -1
+}
+
+// Contains returns true if the list holds the string.
+func (l *PackedList) Contains(s string) bool {
+	off, _ := l.find(s, 1)
+	return off >= 0
+}
+
+// Remove removes the first occurrence of the string from the list, and
+// returns true if the list held it.
+func (l *PackedList) Remove(s string) bool {
+	off, next := l.find(s, 1)
+	if off < 0 {
+		return false
+	}
+	l.buf = slices.Delete(l.buf, off, next)
+	l.n--
+	return true
+}
+
+// GetPair returns the value paired with the key in the list of pairs.
+func (l *PackedList) GetPair(k string) (string, bool) {
+	off, next := l.find(k, 2)
+	if off < 0 {
+		return "", false
+	}
+	start, end := l.entry(next)
+	return string(l.buf[start:end]), true
+}
+
+// SetPair sets the value paired with the key in the list of pairs, appending
+// the pair if the key is not in the list. It returns the previous value, if any.
+func (l *PackedList) SetPair(k, v string) (string, bool) {
+	off, next := l.find(k, 2)
+	if off < 0 {
+		l.Append(k, v)
+		return "", false
+	}
+	start, end := l.entry(next)
+	old := string(l.buf[start:end])
+	entry := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(v)), uint64(len(v)))
+	entry = append(entry, v...)
+	l.buf = slices.Replace(l.buf, next, end, entry...)
+	return old, true
+}
+
+// DeletePair removes the pair of the key from the list of pairs, and
+// returns true if the list held it.
+func (l *PackedList) DeletePair(k string) bool {
+	off, next := l.find(k, 2)
+	if off < 0 {
+		return false
+	}
+	_, end := l.entry(next)
+	l.buf = slices.Delete(l.buf, off, end)
+	l.n -= 2
+	return true
+}
+
+// Pairs calls f with the pairs of the list, in order, until f returns false.
+func (l *PackedList) Pairs(f func(k, v string) bool) {
+	for off := 0; off < len(l.buf); {
+		kStart, kEnd := l.entry(off)
+		vStart, vEnd := l.entry(kEnd)
+		if !f(string(l.buf[kStart:kEnd]), string(l.buf[vStart:vEnd])) {
+			return
+		}
+		off = vEnd
+	}
+}
+
+// DeepCopy returns a copy of the list.
+func (l *PackedList) DeepCopy() interface{} {
+	return &PackedList{buf: slices.Clone(l.buf), n: l.n}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package object
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPackedListPairs(t *testing.T) {
+	l := &PackedList{}
+	l.SetPair("f1", "v1")
+	l.SetPair("f2", strings.Repeat("v", 300))
+	if old, ok := l.SetPair("f1", "value1"); !ok || old != "v1" {
+		t.Fatalf("expected the previous value v1, got %q", old)
+	}
+	if v, ok := l.GetPair("f1"); !ok || v != "value1" {
+		t.Fatalf("expected value1, got %q", v)
+	}
+	// The values are not matched as keys.
+	if _, ok := l.GetPair("value1"); ok {
+		t.Fatal("expected the value not to be found as a key")
+	}
+	if !l.DeletePair("f1") || l.Len() != 2 {
+		t.Fatalf("expected a single pair left, got %d strings", l.Len())
+	}
+	if v, ok := l.GetPair("f2"); !ok || len(v) != 300 {
+		t.Fatalf("expected the value of f2 to be left intact, got %q", v)
+	}
+}
+
+func TestIntSet(t *testing.T) {
+	s := &IntSet{}
+	for _, v := range []int64{5, -3, 5, 1000} {
+		s.Add(v)
+	}
+	if s.width != 2 || s.Len() != 3 {
+		t.Fatalf("expected 3 integers of 2 bytes, got %d of %d bytes", s.Len(), s.width)
+	}
+
+	// The set is upgraded to the width of the widest integer.
+	s.Add(math.MaxInt64)
+	s.Add(math.MinInt32)
+	if s.width != 8 {
+		t.Fatalf("expected a width of 8 bytes, got %d", s.width)
+	}
+	var all []int64
+	s.All(func(v int64) bool {
+		all = append(all, v)
+		return true
+	})
+	if want := []int64{math.MinInt32, -3, 5, 1000, math.MaxInt64}; !slices.Equal(all, want) {
+		t.Fatalf("expected %v, got %v", want, all)
+	}
+
+	if !s.Remove(5) || s.Contains(5) || s.Remove(5) {
+		t.Fatal("expected 5 to be removed once")
+	}
+}
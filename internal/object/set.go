@@ -0,0 +1,232 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package object
+
+import (
+	"maps"
+	"strconv"
+
+	"github.com/dicedb/dice/config"
+)
+
+// Set is a set of strings stored as an intset while its members are all
+// integers, as a listpack while it is small, and as a hashtable once it
+// grows past the thresholds set in the config. A set never goes back to
+// a smaller encoding, even as members are removed.
+type Set struct {
+	ints   *IntSet
+	packed *PackedList
+	m      map[string]struct{}
+}
+
+// NewSet returns an empty set, stored as an intset.
+func NewSet() *Set {
+	return &Set{ints: &IntSet{}}
+}
+
+// asInt returns the integer the string is the canonical form of, if any,
+// the strings such as "007" or "+7" not being stored as integers as they
+// would not read back the same.
+func asInt(s string) (int64, bool) {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || strconv.FormatInt(v, 10) != s {
+		return 0, false
+	}
+	return v, true
+}
+
+// Encoding returns the encoding the set is stored as.
+func (s *Set) Encoding() ObjectEncoding {
+	switch {
+	case s.ints != nil:
+		return ObjEncodingIntset
+	case s.packed != nil:
+		return ObjEncodingListpack
+	default:
+		return ObjEncodingHashtable
+	}
+}
+
+// Len returns the number of members of the set.
+func (s *Set) Len() int {
+	switch {
+	case s.ints != nil:
+		return s.ints.Len()
+	case s.packed != nil:
+		return s.packed.Len()
+	default:
+		return len(s.m)
+	}
+}
+
+// Contains returns true if the member is in the set.
+func (s *Set) Contains(member string) bool {
+	switch {
+	case s.ints != nil:
+		v, ok := asInt(member)
+		return ok && s.ints.Contains(v)
+	case s.packed != nil:
+		return s.packed.Contains(member)
+	default:
+		_, ok := s.m[member]
+		return ok
+	}
+}
+
+// Add adds the member to the set, upgrading the encoding of the set if the
+// member does not fit in it, and returns true if the set did not hold it.
+func (s *Set) Add(member string) bool {
+	if s.ints != nil {
+		v, ok := asInt(member)
+		if ok && (s.ints.Contains(v) || s.ints.Len() < config.Config.SetMaxIntsetEntries) {
+			return s.ints.Add(v)
+		}
+		s.upgradeIntset(member)
+	}
+
+	if s.packed != nil {
+		if s.packed.Contains(member) {
+			return false
+		}
+		if s.packed.Len() < config.Config.SetMaxListpackEntries && len(member) <= config.Config.SetMaxListpackValue {
+			s.packed.Append(member)
+			return true
+		}
+		s.upgradeListpack()
+	}
+
+	if _, ok := s.m[member]; ok {
+		return false
+	}
+	s.m[member] = struct{}{}
+	return true
+}
+
+// upgradeIntset moves the members of the intset to a listpack, or to a
+// hashtable if the member about to be added would not fit in the listpack.
+func (s *Set) upgradeIntset(member string) {
+	ints := s.ints
+	s.ints = nil
+	if ints.Len() < config.Config.SetMaxListpackEntries && len(member) <= config.Config.SetMaxListpackValue {
+		s.packed = &PackedList{}
+		ints.All(func(v int64) bool {
+			s.packed.Append(strconv.FormatInt(v, 10))
+			return true
+		})
+		return
+	}
+	s.m = make(map[string]struct{}, ints.Len()+1)
+	ints.All(func(v int64) bool {
+		s.m[strconv.FormatInt(v, 10)] = struct{}{}
+		return true
+	})
+}
+
+// upgradeListpack moves the members of the listpack to a hashtable.
+func (s *Set) upgradeListpack() {
+	s.m = make(map[string]struct{}, s.packed.Len()+1)
+	s.packed.All(func(member string) bool {
+		s.m[member] = struct{}{}
+		return true
+	})
+	s.packed = nil
+}
+
+// Remove removes the member from the set, and returns true if the set held it.
+func (s *Set) Remove(member string) bool {
+	switch {
+	case s.ints != nil:
+		v, ok := asInt(member)
+		return ok && s.ints.Remove(v)
+	case s.packed != nil:
+		return s.packed.Remove(member)
+	default:
+		if _, ok := s.m[member]; !ok {
+			return false
+		}
+		delete(s.m, member)
+		return true
+	}
+}
+
+// All calls f with the members of the set until f returns false. The members
+// of an intset come in ascending order, those of a listpack in the order they
+// were added, and those of a hashtable in no particular order.
+func (s *Set) All(f func(member string) bool) {
+	switch {
+	case s.ints != nil:
+		s.ints.All(func(v int64) bool {
+			return f(strconv.FormatInt(v, 10))
+		})
+	case s.packed != nil:
+		s.packed.All(f)
+	default:
+		for member := range s.m {
+			if !f(member) {
+				return
+			}
+		}
+	}
+}
+
+// Size returns the estimated size of the set in memory, in bytes.
+func (s *Set) Size() int {
+	switch {
+	case s.ints != nil:
+		return s.ints.Size()
+	case s.packed != nil:
+		return s.packed.Size()
+	default:
+		size := mapHeaderSize
+		for member := range s.m {
+			size += StringSize(member) + mapEntryOverhead
+		}
+		return size
+	}
+}
+
+// SampledSize returns the estimated size of the set in memory, in bytes, the
+// size of a hashtable being extrapolated from at most samples of its members.
+func (s *Set) SampledSize(samples int) int {
+	if s.m == nil {
+		return s.Size()
+	}
+	n, size := 0, 0
+	for member := range s.m {
+		if n == samples {
+			break
+		}
+		size += StringSize(member) + mapEntryOverhead
+		n++
+	}
+	if n == 0 {
+		return mapHeaderSize
+	}
+	return mapHeaderSize + size*len(s.m)/n
+}
+
+// FreeChunk removes at most n members of the set, an intset or a listpack
+// being released at once, and returns true once the set is empty.
+func (s *Set) FreeChunk(n int) bool {
+	s.ints, s.packed = nil, nil
+	for member := range s.m {
+		if n--; n < 0 {
+			break
+		}
+		delete(s.m, member)
+	}
+	return len(s.m) == 0
+}
+
+// DeepCopy returns a copy of the set, in the same encoding.
+func (s *Set) DeepCopy() interface{} {
+	switch {
+	case s.ints != nil:
+		return &Set{ints: s.ints.DeepCopy().(*IntSet)}
+	case s.packed != nil:
+		return &Set{packed: s.packed.DeepCopy().(*PackedList)}
+	default:
+		return &Set{m: maps.Clone(s.m)}
+	}
+}
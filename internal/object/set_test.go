@@ -0,0 +1,96 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package object
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+)
+
+func TestMain(m *testing.M) {
+	config.ForceInit(&config.DiceDBConfig{})
+	os.Exit(m.Run())
+}
+
+func TestSetEncodings(t *testing.T) {
+	s := NewSet()
+	for i := 0; i < 10; i++ {
+		s.Add(strconv.Itoa(i))
+	}
+	if s.Encoding() != ObjEncodingIntset {
+		t.Fatalf("expected an intset, got %s", s.Encoding())
+	}
+
+	// The strings not in the canonical form of an integer are not stored as integers.
+	if s.Contains("007") || !s.Add("007") {
+		t.Fatal("expected 007 to be added as a string")
+	}
+	if s.Encoding() != ObjEncodingListpack || !s.Contains("7") || !s.Contains("007") || s.Len() != 11 {
+		t.Fatalf("expected a listpack of 11 members, got a %s of %d", s.Encoding(), s.Len())
+	}
+
+	s.Add(strings.Repeat("m", config.Config.SetMaxListpackValue+1))
+	if s.Encoding() != ObjEncodingHashtable || s.Len() != 12 {
+		t.Fatalf("expected a hashtable of 12 members, got a %s of %d", s.Encoding(), s.Len())
+	}
+	if !s.Remove("007") || s.Contains("007") {
+		t.Fatal("expected 007 to be removed")
+	}
+}
+
+func TestSetIntsetEntries(t *testing.T) {
+	s := NewSet()
+	for i := 0; i <= config.Config.SetMaxIntsetEntries; i++ {
+		s.Add(strconv.Itoa(i))
+	}
+	// An intset too large for a listpack goes straight to a hashtable.
+	if s.Encoding() != ObjEncodingHashtable || s.Len() != config.Config.SetMaxIntsetEntries+1 {
+		t.Fatalf("expected a hashtable of %d members, got a %s of %d",
+			config.Config.SetMaxIntsetEntries+1, s.Encoding(), s.Len())
+	}
+
+	small, large := NewSet(), s
+	small.Add("1")
+	if small.Size()*100 > large.Size() {
+		t.Fatalf("expected the intset of a single member to be far smaller than the hashtable, got %d and %d",
+			small.Size(), large.Size())
+	}
+}
+
+func TestSetSampledSize(t *testing.T) {
+	s := NewSet()
+	for i := 0; i < 1000; i++ {
+		s.Add("member" + strconv.Itoa(i))
+	}
+
+	// The members being of about the same length, a few of them are
+	// enough to estimate the size of the set closely.
+	exact, sampled := s.Size(), s.SampledSize(5)
+	if sampled < exact*9/10 || sampled > exact*11/10 {
+		t.Fatalf("expected the estimate to be within 10%% of %d, got %d", exact, sampled)
+	}
+	if s.SampledSize(s.Len()) != exact {
+		t.Fatalf("expected the estimate from all the members to be %d, got %d", exact, s.SampledSize(s.Len()))
+	}
+}
+
+func TestGetEncoding(t *testing.T) {
+	tests := []struct {
+		obj  *Obj
+		want ObjectEncoding
+	}{
+		{&Obj{Type: ObjTypeString, Value: "v"}, ObjEncodingRaw},
+		{&Obj{Type: ObjTypeInt, Value: int64(1)}, ObjEncodingInt},
+		{&Obj{Type: ObjTypeSet, Value: NewSet()}, ObjEncodingIntset},
+	}
+	for _, tc := range tests {
+		if got := GetEncoding(tc.obj); got != tc.want {
+			t.Fatalf("expected %s, got %s", tc.want, got)
+		}
+	}
+}
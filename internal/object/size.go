@@ -0,0 +1,129 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package object
+
+import "unsafe"
+
+// The estimated sizes, in bytes, of the building blocks of the values.
+const (
+	// ObjOverhead is the size of an Obj along with the entry
+	// of its key in the table of the store.
+	ObjOverhead = int(unsafe.Sizeof(Obj{})) + 32
+
+	stringHeaderSize = int(unsafe.Sizeof(""))
+	ifaceSize        = int(unsafe.Sizeof(interface{}(nil)))
+	// mapEntryOverhead is the share of a map entry in the buckets of
+	// the map, on top of the key and the value it holds.
+	mapEntryOverhead = 8
+	mapHeaderSize    = 48
+	sliceHeaderSize  = int(unsafe.Sizeof([]interface{}{}))
+)
+
+// Sizer is implemented by the values estimating their own size in memory,
+// which is how the values of the types defined outside of this package
+// are accounted for.
+type Sizer interface {
+	Size() int
+}
+
+// SampledSizer is implemented by the values holding many elements, the size
+// of which is estimated from a sample of their elements, see EstimateSampledSize.
+type SampledSizer interface {
+	SampledSize(samples int) int
+}
+
+// sizeEstimators estimate the size of the values of every object type, the
+// values of the types not listed being estimated by their Size method, if
+// any, or as a pointer.
+var sizeEstimators = map[ObjectType]func(v interface{}) int{
+	ObjTypeString: func(v interface{}) int {
+		s, _ := v.(string)
+		return StringSize(s)
+	},
+	ObjTypeInt:   func(interface{}) int { return 8 },
+	ObjTypeFloat: func(interface{}) int { return 8 },
+	ObjTypeJSON:  jsonSize,
+	ObjTypeByteArray: func(v interface{}) int {
+		b, _ := v.([]byte)
+		return sliceHeaderSize + cap(b)
+	},
+}
+
+// EstimateSize returns the estimated size of the value of the object, in bytes.
+func EstimateSize(obj *Obj) int {
+	if estimate, ok := sizeEstimators[obj.Type]; ok {
+		return estimate(obj.Value)
+	}
+	if s, ok := obj.Value.(Sizer); ok {
+		return s.Size()
+	}
+	return 8
+}
+
+// EstimateSampledSize returns the estimated size of the value of the object,
+// in bytes, the size of a value holding many elements being extrapolated
+// from at most samples of its elements, or computed from all of them for 0.
+func EstimateSampledSize(obj *Obj, samples int) int {
+	if s, ok := obj.Value.(SampledSizer); ok && samples > 0 {
+		return s.SampledSize(samples)
+	}
+	return EstimateSize(obj)
+}
+
+// StringSize returns the estimated size of the string, in bytes.
+func StringSize(s string) int {
+	return stringHeaderSize + len(s)
+}
+
+// MapSize returns the estimated size of the map of strings, in bytes.
+func MapSize(m map[string]string) int {
+	size := mapHeaderSize
+	for k, v := range m {
+		size += StringSize(k) + StringSize(v) + mapEntryOverhead
+	}
+	return size
+}
+
+// SampledMapSize returns the estimated size of the map of strings, in bytes,
+// extrapolated from the size of at most samples of its entries.
+func SampledMapSize(m map[string]string, samples int) int {
+	n, size := 0, 0
+	for k, v := range m {
+		if n == samples {
+			break
+		}
+		size += StringSize(k) + StringSize(v) + mapEntryOverhead
+		n++
+	}
+	if n == 0 {
+		return mapHeaderSize
+	}
+	return mapHeaderSize + size*len(m)/n
+}
+
+// jsonSize returns the estimated size of the JSON document, as decoded
+// into maps, slices and scalars held in interfaces.
+func jsonSize(v interface{}) int {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		size := mapHeaderSize
+		for k, e := range v {
+			size += StringSize(k) + ifaceSize + jsonSize(e) + mapEntryOverhead
+		}
+		return size
+	case []interface{}:
+		size := sliceHeaderSize
+		for _, e := range v {
+			size += ifaceSize + jsonSize(e)
+		}
+		return size
+	case string:
+		return StringSize(v)
+	case nil:
+		return 0
+	default:
+		// The numbers and the booleans.
+		return 8
+	}
+}
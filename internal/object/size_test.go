@@ -0,0 +1,45 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package object
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bytedance/sonic"
+)
+
+type sizedValue int
+
+func (v sizedValue) Size() int { return int(v) }
+
+func TestEstimateSize(t *testing.T) {
+	var doc interface{}
+	if err := sonic.UnmarshalString(`{"name":"`+strings.Repeat("x", 1000)+`","tags":["a","b"],"n":1}`, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		obj      *Obj
+		min, max int
+	}{
+		{"string", &Obj{Type: ObjTypeString, Value: strings.Repeat("x", 100)}, 100, 150},
+		{"int", &Obj{Type: ObjTypeInt, Value: int64(42)}, 8, 8},
+		{"json", &Obj{Type: ObjTypeJSON, Value: doc}, 1000, 1500},
+		{"sizer", &Obj{Type: ObjTypeSSMap, Value: sizedValue(123)}, 123, 123},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if size := EstimateSize(tc.obj); size < tc.min || size > tc.max {
+				t.Fatalf("expected a size in [%d, %d], got %d", tc.min, tc.max, size)
+			}
+		})
+	}
+
+	m := map[string]string{"f1": strings.Repeat("v", 100)}
+	if size := MapSize(m); size < 100 {
+		t.Fatalf("expected the map to be at least 100 bytes, got %d", size)
+	}
+}
@@ -22,3 +22,67 @@ func AssertType(_type, expectedType ObjectType) []byte {
 	}
 	return nil
 }
+
+// ObjectEncoding is the way the value of an object is laid out in memory,
+// the small collections being packed as listpacks or intsets until they
+// grow past the thresholds set in the config.
+type ObjectEncoding uint8
+
+const (
+	ObjEncodingRaw ObjectEncoding = iota
+	ObjEncodingInt
+	ObjEncodingFloat
+	ObjEncodingJSON
+	ObjEncodingListpack
+	ObjEncodingIntset
+	ObjEncodingHashtable
+	ObjEncodingSkiplist
+)
+
+// String returns the name of the encoding as reported by OBJECT ENCODING.
+func (oe ObjectEncoding) String() string {
+	names := [...]string{
+		"raw",
+		"int",
+		"float",
+		"json",
+		"listpack",
+		"intset",
+		"hashtable",
+		"skiplist",
+	}
+
+	if oe < ObjectEncoding(len(names)) {
+		return names[oe]
+	}
+	return "unknown"
+}
+
+// Encoder is implemented by the values switching between encodings as they grow.
+type Encoder interface {
+	Encoding() ObjectEncoding
+}
+
+// typeEncodings are the encodings of the values of the object types
+// that are always laid out the same way.
+var typeEncodings = map[ObjectType]ObjectEncoding{
+	ObjTypeString:    ObjEncodingRaw,
+	ObjTypeByteArray: ObjEncodingRaw,
+	ObjTypeInt:       ObjEncodingInt,
+	ObjTypeFloat:     ObjEncodingFloat,
+	ObjTypeJSON:      ObjEncodingJSON,
+	ObjTypeSet:       ObjEncodingHashtable,
+	ObjTypeSSMap:     ObjEncodingHashtable,
+	ObjTypeSortedSet: ObjEncodingSkiplist,
+}
+
+// GetEncoding returns the encoding of the value of the object.
+func GetEncoding(obj *Obj) ObjectEncoding {
+	if e, ok := obj.Value.(Encoder); ok {
+		return e.Encoding()
+	}
+	if e, ok := typeEncodings[obj.Type]; ok {
+		return e
+	}
+	return ObjEncodingRaw
+}
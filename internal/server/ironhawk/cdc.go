@@ -0,0 +1,334 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	"github.com/dicedb/dice/internal/wal"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// cdcRecord is the change record of a key mutated by a command logged in the WAL.
+type cdcRecord struct {
+	lsn       uint64
+	timestamp int64
+	command   string
+	args      []string
+	key       string
+	// shard is the ID of the shard holding the key, -1 if the command has no key.
+	shard int
+}
+
+// response returns the response sent for the record, along
+// with the value of the key if the value is not nil.
+func (r *cdcRecord) response(value *wire.Response) *wire.Response {
+	args := make([]*structpb.Value, len(r.args))
+	for i, arg := range r.args {
+		args[i] = structpb.NewStringValue(arg)
+	}
+	fields := map[string]*structpb.Value{
+		"lsn":       structpb.NewStringValue(strconv.FormatUint(r.lsn, 10)),
+		"timestamp": structpb.NewNumberValue(float64(r.timestamp / 1e6)),
+		"command":   structpb.NewStringValue(r.command),
+		"args":      structpb.NewListValue(&structpb.ListValue{Values: args}),
+		"key":       structpb.NewStringValue(r.key),
+	}
+	if r.shard >= 0 {
+		fields["shard"] = structpb.NewNumberValue(float64(r.shard))
+	}
+
+	res := &wire.Response{Attrs: &structpb.Struct{Fields: fields}}
+	if value != nil {
+		res.Value = value.Value
+		res.VList = value.VList
+		res.VSsMap = value.VSsMap
+	}
+	return res
+}
+
+// cdcSubscriber is the state of the change stream of a watch connection.
+type cdcSubscriber struct {
+	q         *watchQueue
+	postImage bool
+	// catchingUp is true while the records retained in the WAL are being
+	// replayed, the live entries being held in pending meanwhile.
+	catchingUp bool
+	pending    []*wal.WALEntry
+	// last is the LSN of the last record queued.
+	last uint64
+}
+
+// cdcMaxPending is the number of entries queued by the hub, or held for a
+// subscriber catching up with the WAL, beyond which the subscribers lagging
+// behind are disconnected. They resume from the last LSN they received, the
+// entries they missed being replayed from the WAL.
+const cdcMaxPending = 1 << 16
+
+// cdcHub streams the entries logged in the WAL to the CDC.SUBSCRIBE subscribers.
+type cdcHub struct {
+	wl wal.AbstractWAL
+	sm *shardmanager.ShardManager
+
+	mu sync.Mutex
+	// entries are the entries logged and not yet dispatched, up to
+	// cdcMaxPending of them given that the WAL must never wait for the hub.
+	entries     []*wal.WALEntry
+	queued      chan struct{}
+	subscribers map[string]*cdcSubscriber
+}
+
+// errCDCStopped stops the replay of the WAL for a subscriber gone.
+var errCDCStopped = errors.New("cdc subscriber stopped")
+
+func newCDCHub(wl wal.AbstractWAL, sm *shardmanager.ShardManager) *cdcHub {
+	return &cdcHub{
+		wl:          wl,
+		sm:          sm,
+		queued:      make(chan struct{}, 1),
+		subscribers: map[string]*cdcSubscriber{},
+	}
+}
+
+// StartCDC starts streaming the entries logged in the WAL to the
+// CDC.SUBSCRIBE subscribers, until the context is canceled.
+func (w *WatchManager) StartCDC(ctx context.Context, wl wal.AbstractWAL, shardManager *shardmanager.ShardManager) {
+	h := newCDCHub(wl, shardManager)
+	w.mu.Lock()
+	w.cdc = h
+	w.mu.Unlock()
+
+	wl.Observe(h.observe)
+	go h.run(ctx)
+}
+
+// SubscribeCDC starts the change stream of the watch connection of the thread,
+// replacing the previous one, if any. The records are queued along with the
+// updates of the query subscriptions of the connection.
+func (w *WatchManager) SubscribeCDC(t *IOThread, s *cmd.CDCSubscription) {
+	w.mu.Lock()
+	q := w.register(t)
+	h := w.cdc
+	w.mu.Unlock()
+
+	if h == nil {
+		q.push(0, &wire.Response{Err: diceerrors.ErrWALDisabled.Error()})
+		return
+	}
+	h.subscribe(t.ClientID, q, s)
+}
+
+// observe queues the entry logged in the WAL. It is called with the WAL locked.
+func (h *cdcHub) observe(entry *wal.WALEntry) {
+	h.mu.Lock()
+	if len(h.entries) >= cdcMaxPending {
+		// The hub not keeping up with the WAL, all of its subscribers lag.
+		for clientID, sub := range h.subscribers {
+			h.disconnect(clientID, sub)
+		}
+		h.entries = nil
+	}
+	h.entries = append(h.entries, entry)
+	h.mu.Unlock()
+
+	select {
+	case h.queued <- struct{}{}:
+	default:
+	}
+}
+
+func (h *cdcHub) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.queued:
+		}
+
+		h.mu.Lock()
+		entries := h.entries
+		h.entries = nil
+		h.mu.Unlock()
+
+		for _, entry := range entries {
+			h.dispatch(entry)
+		}
+	}
+}
+
+// dispatch sends the records of the entry to the subscribers, or holds
+// the entry for the subscribers catching up with the WAL.
+func (h *cdcHub) dispatch(entry *wal.WALEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.subscribers) == 0 {
+		return
+	}
+
+	records := h.records(entry)
+	values := map[string]*wire.Response{}
+	for clientID, sub := range h.subscribers {
+		if sub.catchingUp {
+			if len(sub.pending) >= cdcMaxPending {
+				h.disconnect(clientID, sub)
+				continue
+			}
+			sub.pending = append(sub.pending, entry)
+			continue
+		}
+		h.send(sub, records, values)
+	}
+}
+
+// send queues the records for the subscriber. The values of the keys are
+// read once for all the subscribers, values being nil for the records
+// replayed from the WAL, whose values are long gone.
+func (h *cdcHub) send(sub *cdcSubscriber, records []*cdcRecord, values map[string]*wire.Response) {
+	// The records of an entry share its LSN.
+	if len(records) == 0 || records[0].lsn <= sub.last {
+		return
+	}
+	for _, r := range records {
+		var value *wire.Response
+		if sub.postImage && values != nil && r.shard >= 0 {
+			if value = values[r.key]; value == nil {
+				value = cmd.KeyValue(h.sm, r.key)
+				values[r.key] = value
+			}
+		}
+
+		// A subscriber not keeping up is disconnected rather than
+		// missing records, and resumes from the last LSN it received.
+		sub.q.pushWithPolicy(0, r.response(value), WatchQueueDisconnect)
+		sub.last = r.lsn
+	}
+}
+
+// records returns the change records of the entry, one for every key mutated.
+func (h *cdcHub) records(entry *wal.WALEntry) []*cdcRecord {
+	c := &wire.Command{}
+	if err := proto.Unmarshal(entry.Data, c); err != nil {
+		slog.Warn("could not decode the WAL entry", slog.Uint64("lsn", entry.LogSequenceNumber), slog.Any("error", err))
+		return nil
+	}
+
+	cmds := []*cmd.Cmd{{C: c}}
+	if c.Cmd == "BATCH" {
+		if b, err := cmd.ParseBatch(cmds[0]); err == nil {
+			cmds = b.Cmds
+		}
+	}
+
+	var records []*cdcRecord
+	for _, c := range cmds {
+		keys := c.Keys()
+		if len(keys) == 0 {
+			keys = []string{""}
+		}
+		for _, key := range keys {
+			shard := -1
+			if key != "" {
+				shard = h.sm.GetShardForKey(key).ID
+			}
+			records = append(records, &cdcRecord{
+				lsn:       entry.LogSequenceNumber,
+				timestamp: entry.Timestamp,
+				command:   c.C.Cmd,
+				args:      c.C.Args,
+				key:       key,
+				shard:     shard,
+			})
+		}
+	}
+	return records
+}
+
+func (h *cdcHub) subscribe(clientID string, q *watchQueue, s *cmd.CDCSubscription) {
+	sub := &cdcSubscriber{q: q, postImage: s.PostImage, catchingUp: s.HasFrom, last: s.From}
+
+	h.mu.Lock()
+	h.subscribers[clientID] = sub
+	h.mu.Unlock()
+
+	if s.HasFrom {
+		go h.catchUp(sub, s.From)
+	}
+}
+
+// catchUp queues the records retained in the WAL after the LSN for the
+// subscriber, and then the live records held meanwhile. The records are
+// queued as the connection drains them, so that the subscriber is not
+// disconnected for replaying a long history.
+func (h *cdcHub) catchUp(sub *cdcSubscriber, from uint64) {
+	first := true
+	err := h.wl.ReplayFrom(from, func(entry *wal.WALEntry) error {
+		if first && entry.LogSequenceNumber > from+1 {
+			sub.q.pushWithPolicy(0, &wire.Response{
+				Err: diceerrors.ErrRecordsNotRetained(from, entry.LogSequenceNumber).Error(),
+			}, WatchQueueDisconnect)
+		}
+		first = false
+
+		if !sub.q.waitRoom() {
+			return errCDCStopped
+		}
+		records := h.records(entry)
+		h.mu.Lock()
+		h.send(sub, records, nil)
+		h.mu.Unlock()
+		return nil
+	})
+	if err != nil && !errors.Is(err, errCDCStopped) {
+		slog.Error("could not replay the WAL for a CDC subscriber", slog.Any("error", err))
+		sub.q.pushWithPolicy(0, &wire.Response{Err: err.Error()}, WatchQueueDisconnect)
+	}
+
+	for {
+		h.mu.Lock()
+		pending := sub.pending
+		sub.pending = nil
+		if len(pending) == 0 {
+			sub.catchingUp = false
+			h.mu.Unlock()
+			return
+		}
+		h.mu.Unlock()
+
+		for _, entry := range pending {
+			if !sub.q.waitRoom() {
+				return
+			}
+			records := h.records(entry)
+			h.mu.Lock()
+			h.send(sub, records, map[string]*wire.Response{})
+			h.mu.Unlock()
+		}
+	}
+}
+
+// disconnect disconnects the subscriber lagging behind the WAL, which
+// resumes from the last LSN it received. It is called with the hub locked.
+func (h *cdcHub) disconnect(clientID string, sub *cdcSubscriber) {
+	sub.q.disconnect()
+	sub.pending = nil
+	delete(h.subscribers, clientID)
+}
+
+// unsubscribe stops the change stream of the client, if it is the one of the queue.
+func (h *cdcHub) unsubscribe(clientID string, q *watchQueue) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if sub := h.subscribers[clientID]; sub != nil && sub.q == q {
+		delete(h.subscribers, clientID)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	"github.com/dicedb/dice/internal/wal"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/proto"
+)
+
+// startCDC streams a WAL in a temporary directory to the CDC subscribers of the watch manager.
+func startCDC(t *testing.T, wm *WatchManager, sm *shardmanager.ShardManager) *wal.AOF {
+	t.Helper()
+	wl, err := wal.NewAOFWAL(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wl.Init(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		_ = wl.Close()
+	})
+	wm.StartCDC(ctx, wl, sm)
+	return wl
+}
+
+// write executes the command and logs it, the way the io-threads do.
+func write(t *testing.T, sm *shardmanager.ShardManager, wl wal.AbstractWAL, c string, args ...string) {
+	t.Helper()
+	wc := &wire.Command{Cmd: c, Args: args}
+	if _, err := (&cmd.Cmd{C: wc, WAL: cmd.NewCommandLog(wl)}).Execute(sm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertRecord(t *testing.T, r *wire.Response, lsn, command, key string) {
+	t.Helper()
+	fields := r.GetAttrs().GetFields()
+	if fields["lsn"].GetStringValue() != lsn || fields["command"].GetStringValue() != command ||
+		fields["key"].GetStringValue() != key {
+		t.Fatalf("expected the record of %s %s at LSN %s, got %v", command, key, lsn, r)
+	}
+	if _, ok := fields["shard"]; !ok || fields["timestamp"].GetNumberValue() == 0 {
+		t.Fatalf("expected the shard and the timestamp of the record, got %v", r)
+	}
+}
+
+func TestCDCSubscribeFrom(t *testing.T) {
+	wm, sm, thread, conn := startWatchManager(t)
+	wl := startCDC(t, wm, sm)
+
+	write(t, sm, wl, "SET", "k1", "v1")
+	write(t, sm, wl, "SET", "k2", "v2")
+	write(t, sm, wl, "BATCH", "ATOMIC", "2", "SET", "k3", "v3", "1", "DEL", "k1")
+
+	// The records retained in the WAL are replayed, without their values.
+	wm.SubscribeCDC(thread, &cmd.CDCSubscription{From: 1, HasFrom: true, PostImage: true})
+	assertRecord(t, readUpdate(t, conn), "2", "SET", "k2")
+	r := readUpdate(t, conn)
+	assertRecord(t, r, "3", "SET", "k3")
+	if r.GetValue() != nil {
+		t.Fatalf("expected no value for a replayed record, got %v", r)
+	}
+	assertRecord(t, readUpdate(t, conn), "3", "DEL", "k1")
+
+	// The live records follow, with the value of their key.
+	write(t, sm, wl, "SET", "k2", "v3")
+	r = readUpdate(t, conn)
+	assertRecord(t, r, "4", "SET", "k2")
+	if r.GetVStr() != "v3" {
+		t.Fatalf("expected the post-image v3, got %v", r)
+	}
+}
+
+func TestCDCSubscribeLive(t *testing.T) {
+	wm, sm, thread, conn := startWatchManager(t)
+	wl := startCDC(t, wm, sm)
+
+	write(t, sm, wl, "SET", "k1", "v1")
+	// The records are sent by the dispatcher in the order of the
+	// WAL, it is drained before subscribing from the next LSN.
+	time.Sleep(10 * time.Millisecond)
+	wm.SubscribeCDC(thread, &cmd.CDCSubscription{})
+	write(t, sm, wl, "SET", "k2", "v2")
+
+	r := readUpdate(t, conn)
+	assertRecord(t, r, "2", "SET", "k2")
+	if r.GetValue() != nil {
+		t.Fatalf("expected no value without POSTIMAGE, got %v", r)
+	}
+
+	// The stream stops with the connection.
+	wm.CleanupThreadWatchSubscriptions(thread)
+	if len(wm.cdc.subscribers) != 0 {
+		t.Fatalf("expected no CDC subscribers left, got %v", wm.cdc.subscribers)
+	}
+}
+
+// retainedWAL is a WAL retaining only the entries it holds.
+type retainedWAL struct {
+	*wal.WALNull
+	entries []*wal.WALEntry
+}
+
+func (w *retainedWAL) ReplayFrom(lsn uint64, callback func(*wal.WALEntry) error) error {
+	for _, e := range w.entries {
+		if e.LogSequenceNumber > lsn {
+			if err := callback(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestCDCSubscribeGap(t *testing.T) {
+	wm, sm, thread, conn := startWatchManager(t)
+	data, _ := proto.Marshal(&wire.Command{Cmd: "SET", Args: []string{"k1", "v1"}})
+	wl := &retainedWAL{entries: []*wal.WALEntry{{LogSequenceNumber: 7, Data: data, Timestamp: time.Now().UnixNano()}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	wm.StartCDC(ctx, wl, sm)
+
+	// The consumer is told that the records after LSN 3 are gone, and
+	// gets the ones retained.
+	wm.SubscribeCDC(thread, &cmd.CDCSubscription{From: 3, HasFrom: true})
+	if r := readUpdate(t, conn); r.GetErr() != diceerrors.ErrRecordsNotRetained(3, 7).Error() {
+		t.Fatalf("expected the records not to be retained, got %v", r)
+	}
+	assertRecord(t, readUpdate(t, conn), "7", "SET", "k1")
+}
+
+func TestCDCHubOverflow(t *testing.T) {
+	_, sm, thread, _ := startWatchManager(t)
+	h := newCDCHub(&wal.WALNull{}, sm)
+	q := newWatchQueue(thread, 16, WatchQueueDisconnect, &watchQueueStats{})
+	h.subscribe(thread.ClientID, q, &cmd.CDCSubscription{})
+
+	// The hub not dispatching, the entries pile up until the subscriber
+	// is disconnected and the entries queued for it dropped.
+	for lsn := uint64(1); lsn <= cdcMaxPending+1; lsn++ {
+		h.observe(&wal.WALEntry{LogSequenceNumber: lsn})
+	}
+	if len(h.subscribers) != 0 || len(h.entries) != 1 {
+		t.Fatalf("expected the subscriber disconnected and 1 entry queued, got %d subscribers and %d entries",
+			len(h.subscribers), len(h.entries))
+	}
+	if q.stats.disconnected.Load() != 1 || q.waitRoom() {
+		t.Fatalf("expected the queue of the subscriber to be closed")
+	}
+}
+
+func TestCDCCatchUpOverflow(t *testing.T) {
+	_, sm, thread, _ := startWatchManager(t)
+	h := newCDCHub(&wal.WALNull{}, sm)
+	q := newWatchQueue(thread, 16, WatchQueueDisconnect, &watchQueueStats{})
+
+	// The subscriber is left catching up, the live entries being held for it.
+	h.subscribers[thread.ClientID] = &cdcSubscriber{q: q, catchingUp: true}
+	data, _ := proto.Marshal(&wire.Command{Cmd: "SET", Args: []string{"k1", "v1"}})
+	for lsn := uint64(1); lsn <= cdcMaxPending+1; lsn++ {
+		h.dispatch(&wal.WALEntry{LogSequenceNumber: lsn, Data: data})
+	}
+	if len(h.subscribers) != 0 || q.stats.disconnected.Load() != 1 {
+		t.Fatalf("expected the subscriber catching up to be disconnected, got %d subscribers", len(h.subscribers))
+	}
+}
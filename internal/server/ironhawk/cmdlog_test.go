@@ -0,0 +1,111 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/shardmanager"
+	"github.com/dicedb/dice/internal/wal"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/proto"
+)
+
+// recordingWAL is a WAL recording the commands appended to it, failing to
+// append them while failing is set.
+type recordingWAL struct {
+	*wal.WALNull
+	mu       sync.Mutex
+	failing  bool
+	commands []*wire.Command
+}
+
+func (w *recordingWAL) LogCommand(b []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failing {
+		return errors.New("disk full")
+	}
+	c := &wire.Command{}
+	if err := proto.Unmarshal(b, c); err != nil {
+		return err
+	}
+	w.commands = append(w.commands, c)
+	return nil
+}
+
+func TestCommandLogFailure(t *testing.T) {
+	sm := shardmanager.NewShardManager(1, nil, make(chan error))
+	wl := &recordingWAL{}
+	log := cmd.NewCommandLog(wl)
+	fire := func(c string, args ...string) (*cmd.CmdRes, error) {
+		return (&cmd.Cmd{C: &wire.Command{Cmd: c, Args: args}, WAL: log}).Execute(sm)
+	}
+
+	if _, err := fire("SET", "k1", "v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The write failing to be logged is applied, and the writes after it
+	// are rejected without being applied until the WAL recovers.
+	wl.failing = true
+	if _, err := fire("SET", "k2", "v2"); err != diceerrors.ErrWALFailed {
+		t.Fatalf("expected the write not to be logged, got %v", err)
+	}
+	if _, err := fire("SET", "k3", "v3"); err != diceerrors.ErrWALFailed {
+		t.Fatalf("expected the write to be rejected, got %v", err)
+	}
+	store := sm.GetShardForKey("k3").Thread.Store()
+	if store.GetNoTouch("k2") == nil || store.GetNoTouch("k3") != nil {
+		t.Fatalf("expected k2 to be applied and k3 to be rejected")
+	}
+	if _, err := fire("GET", "k1"); err != nil {
+		t.Fatalf("expected the reads to be served, got %v", err)
+	}
+
+	wl.failing = false
+	if _, err := fire("SET", "k4", "v4"); err != nil {
+		t.Fatal(err)
+	}
+	var logged []string
+	for _, c := range wl.commands {
+		logged = append(logged, c.Args[0])
+	}
+	if len(logged) != 3 || logged[0] != "k1" || logged[1] != "k2" || logged[2] != "k4" {
+		t.Fatalf("expected the writes applied to be logged in order, got %v", logged)
+	}
+}
+
+func TestCommandLogOrder(t *testing.T) {
+	sm := shardmanager.NewShardManager(2, nil, make(chan error))
+	wl := &recordingWAL{}
+	log := cmd.NewCommandLog(wl)
+
+	// The last write to the key logged is the last one executed.
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				c := &cmd.Cmd{C: &wire.Command{Cmd: "SET", Args: []string{"k", strconv.Itoa(g*1000 + i)}}, WAL: log}
+				if _, err := c.Execute(sm); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	last := wl.commands[len(wl.commands)-1].Args[1]
+	if v := fmt.Sprint(sm.GetShardForKey("k").Thread.Store().GetNoTouch("k").Value); v != last {
+		t.Fatalf("expected the value last logged %s, got %v", last, v)
+	}
+}
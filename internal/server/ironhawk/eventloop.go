@@ -0,0 +1,213 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dicedb/dice/internal/iomultiplexer"
+)
+
+// eventLoopPollTimeout bounds the time an event loop is blocked
+// polling so that it notices the cancellation of its context.
+const eventLoopPollTimeout = 100 * time.Millisecond
+
+// connCheckInterval is the interval at which an event loop checks its
+// connections for being idle or in need of a ping.
+const connCheckInterval = 250 * time.Millisecond
+
+// connHandler handles the connections served by the event loops.
+type connHandler interface {
+	// handleReadable executes the commands available on the connection.
+	handleReadable(t *IOThread) error
+	// checkIOThread is called periodically for every connection.
+	checkIOThread(t *IOThread, now time.Time) error
+	// closeIOThread releases the connection once it is no longer served.
+	closeIOThread(t *IOThread, err error)
+}
+
+// eventLoop serves the connections assigned to it. It waits for any
+// of the connections to become readable and then hands them over to its
+// workers, a bounded pool of goroutines executing the commands available
+// on the connections, for a command blocking, such as DEBUG SLEEP or the
+// flush of a response to a slow client, to hold back its own connection
+// only. Once all the workers are busy, the event loop waits for one of
+// them to be done before polling again.
+//
+// A connection is owned by exactly one event loop, which stops polling it
+// while its commands are executed, hence its commands are executed by one
+// worker at a time and in the order they were sent.
+type eventLoop struct {
+	id  int
+	mux iomultiplexer.IOMultiplexer
+
+	mu      sync.Mutex
+	threads map[int]*IOThread
+	// busy are the connections whose commands are being executed, which
+	// are neither polled nor checked until they are done.
+	busy map[int]bool
+
+	// readable are the connections handed over to the workers.
+	readable chan *IOThread
+	workers  int
+	handlers sync.WaitGroup
+}
+
+func newEventLoop(id, workers int) (*eventLoop, error) {
+	mux, err := iomultiplexer.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the multiplexer for event loop %d: %w", id, err)
+	}
+	workers = max(workers, 1)
+	return &eventLoop{
+		id:       id,
+		mux:      mux,
+		threads:  make(map[int]*IOThread),
+		busy:     make(map[int]bool),
+		readable: make(chan *IOThread, workers),
+		workers:  workers,
+	}, nil
+}
+
+// add starts serving the connection of the io-thread on the event loop.
+func (l *eventLoop) add(t *IOThread) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fd := t.IoHandler.FD()
+	if err := l.mux.Subscribe(iomultiplexer.Event{Fd: fd, Op: iomultiplexer.OpRead}); err != nil {
+		return err
+	}
+	l.threads[fd] = t
+	return nil
+}
+
+// remove stops serving the connection of the io-thread on the event loop.
+func (l *eventLoop) remove(t *IOThread) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fd := t.IoHandler.FD()
+	if !l.busy[fd] {
+		_ = l.mux.Unsubscribe(iomultiplexer.Event{Fd: fd, Op: iomultiplexer.OpRead})
+	}
+	delete(l.threads, fd)
+	delete(l.busy, fd)
+}
+
+// acquire returns the io-thread of the connection and stops polling the
+// connection, nil if it is no longer served or already being handled.
+func (l *eventLoop) acquire(fd int) (*IOThread, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t := l.threads[fd]
+	if t == nil || l.busy[fd] {
+		return nil, nil
+	}
+	if err := l.mux.Unsubscribe(iomultiplexer.Event{Fd: fd, Op: iomultiplexer.OpRead}); err != nil {
+		return t, err
+	}
+	l.busy[fd] = true
+	return t, nil
+}
+
+// release polls the connection of the io-thread again once its commands
+// have been executed.
+func (l *eventLoop) release(t *IOThread) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fd := t.IoHandler.FD()
+	delete(l.busy, fd)
+	return l.mux.Subscribe(iomultiplexer.Event{Fd: fd, Op: iomultiplexer.OpRead})
+}
+
+// snapshot returns the io-threads of the connections not being handled.
+func (l *eventLoop) snapshot() []*IOThread {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	threads := make([]*IOThread, 0, len(l.threads))
+	for fd, t := range l.threads {
+		if !l.busy[fd] {
+			threads = append(threads, t)
+		}
+	}
+	return threads
+}
+
+// work executes the commands available on the connections handed over to
+// the worker until the event loop stops, the connection being removed from
+// the event loop and closed once handling it fails.
+func (l *eventLoop) work(h connHandler) {
+	defer l.handlers.Done()
+	for t := range l.readable {
+		err := h.handleReadable(t)
+		if err == nil {
+			err = l.release(t)
+		}
+		if err != nil {
+			l.remove(t)
+			h.closeIOThread(t, err)
+		}
+	}
+}
+
+// run polls for readable connections until the context is canceled, and
+// returns once the commands being executed by its workers are done. The
+// connection is removed from the event loop and closed once handling it or
+// checking it fails.
+func (l *eventLoop) run(ctx context.Context, h connHandler) error {
+	defer l.mux.Close()
+	for i := 0; i < l.workers; i++ {
+		l.handlers.Add(1)
+		go l.work(h)
+	}
+	defer func() {
+		close(l.readable)
+		l.handlers.Wait()
+	}()
+
+	lastCheck := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		events, err := l.mux.Poll(eventLoopPollTimeout)
+		if err != nil {
+			if errors.Is(err, syscall.EINTR) {
+				continue
+			}
+			return err
+		}
+
+		for _, event := range events {
+			t, err := l.acquire(event.Fd)
+			if err != nil {
+				l.remove(t)
+				h.closeIOThread(t, err)
+				continue
+			}
+			if t != nil {
+				l.readable <- t
+			}
+		}
+
+		if now := time.Now(); now.Sub(lastCheck) >= connCheckInterval {
+			lastCheck = now
+			for _, t := range l.snapshot() {
+				if err := h.checkIOThread(t, now); err != nil {
+					l.remove(t)
+					h.closeIOThread(t, err)
+				}
+			}
+		}
+	}
+}
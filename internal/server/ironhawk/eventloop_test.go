@@ -0,0 +1,383 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/auth"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/shardmanager"
+	"github.com/dicedb/dice/internal/wal"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMain(m *testing.M) {
+	config.ForceInit(&config.DiceDBConfig{})
+	os.Exit(m.Run())
+}
+
+func freePort(tb testing.TB) int {
+	tb.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// startEventLoopServer starts the server on a free port and returns
+// the address it listens on along with the number of connected clients.
+func startEventLoopServer(tb testing.TB) (addr string, active func() int) {
+	tb.Helper()
+	wl, _ := wal.NewNullWAL()
+	m := NewIOThreadManager()
+	sm, wm := shardmanager.NewShardManager(1, nil, make(chan error)), NewWatchManager()
+	s := NewServer(sm, m, wm, wl)
+	s.Host, s.Port = "127.0.0.1", freePort(tb)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go wm.Run(ctx, nil, sm)
+	go func() {
+		defer close(done)
+		_ = s.Run(ctx)
+	}()
+	tb.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	addr = net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			return addr, func() int { return int(m.IOThreadCount()) }
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	tb.Fatalf("server did not start listening on %s", addr)
+	return "", nil
+}
+
+// startGoroutineServer serves every connection on its own goroutine
+// blocked in ReadSync, the model the event loops replace.
+func startGoroutineServer(tb testing.TB) (addr string, active func() int) {
+	tb.Helper()
+	wl, _ := wal.NewNullWAL()
+	sm, wm := shardmanager.NewShardManager(1, nil, make(chan error)), NewWatchManager()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("failed to listen: %v", err)
+	}
+	tb.Cleanup(func() { ln.Close() })
+
+	var n atomic.Int64
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			n.Add(1)
+			t := &IOThread{IoHandler: NewIOHandlerWithConn(conn), Session: auth.NewSession()}
+			go func() {
+				defer n.Add(-1)
+				_ = t.StartSync(context.Background(), sm, wm, cmd.NewCommandLog(wl))
+				conn.Close()
+			}()
+		}
+	}()
+	return ln.Addr().String(), func() int { return int(n.Load()) }
+}
+
+func handshakeFramed(t *testing.T, conn net.Conn) {
+	t.Helper()
+	b, _ := proto.Marshal(&wire.Command{Cmd: "HANDSHAKE", Args: []string{"c1", "command", "FRAMED"}})
+	if _, err := conn.Write(b); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	buf := make([]byte, 512)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	r := &wire.Response{}
+	if err := proto.Unmarshal(buf[:n], r); err != nil || r.GetVStr() != "OK" {
+		t.Fatalf("handshake failed: %v %v", r, err)
+	}
+}
+
+// TestHandshakeFramedPipelined checks that the framed commands pipelined
+// right after the response to the HANDSHAKE, which the client must wait for,
+// are all executed.
+func TestHandshakeFramedPipelined(t *testing.T) {
+	addr, _ := startEventLoopServer(t)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	handshakeFramed(t, conn)
+
+	var cmds []*wire.Command
+	for i := 0; i < 10; i++ {
+		cmds = append(cmds, &wire.Command{Cmd: "PING", Args: []string{strconv.Itoa(i)}})
+	}
+	if _, err := conn.Write(framedBytes(t, cmds...)); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	reader := bufio.NewReader(conn)
+	for i := range cmds {
+		r := &wire.Response{}
+		if err := protodelim.UnmarshalFrom(reader, r); err != nil {
+			t.Fatalf("failed to read response %d: %v", i, err)
+		}
+		if r.GetVStr() != "PONG "+strconv.Itoa(i) {
+			t.Fatalf("expected PONG %d, got %v", i, r)
+		}
+	}
+}
+
+func TestEventLoopPartialFrame(t *testing.T) {
+	addr, _ := startEventLoopServer(t)
+
+	slow, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer slow.Close()
+	handshakeFramed(t, slow)
+
+	// Send only the first half of the framed command.
+	b := framedBytes(t, &wire.Command{Cmd: "PING"})
+	if _, err := slow.Write(b[:len(b)/2]); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	// The incomplete command must not hold up the other connections.
+	fast, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer fast.Close()
+	handshakeFramed(t, fast)
+	if _, err := fast.Write(b); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	_ = fast.SetReadDeadline(time.Now().Add(time.Second))
+	r := &wire.Response{}
+	if err := protodelim.UnmarshalFrom(bufio.NewReader(fast), r); err != nil || r.GetVStr() != "PONG" {
+		t.Fatalf("expected PONG, got %v %v", r, err)
+	}
+
+	// The command is executed once the rest of it arrives.
+	if _, err := slow.Write(b[len(b)/2:]); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	_ = slow.SetReadDeadline(time.Now().Add(time.Second))
+	r = &wire.Response{}
+	if err := protodelim.UnmarshalFrom(bufio.NewReader(slow), r); err != nil || r.GetVStr() != "PONG" {
+		t.Fatalf("expected PONG, got %v %v", r, err)
+	}
+}
+
+func TestEventLoopPipelinedInOrder(t *testing.T) {
+	// The commands of the connection are spread over many readable events
+	// handed over to the workers of the same event loop.
+	defer func(n, w int) {
+		config.Config.NumEventLoops, config.Config.EventLoopWorkers = n, w
+	}(config.Config.NumEventLoops, config.Config.EventLoopWorkers)
+	config.Config.NumEventLoops, config.Config.EventLoopWorkers = 1, 4
+	addr, _ := startEventLoopServer(t)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	handshakeFramed(t, conn)
+
+	const n = 500
+	go func() {
+		_, _ = conn.Write(framedBytes(t, &wire.Command{Cmd: "SET", Args: []string{"k", "0"}}))
+		for i := 0; i < n; i += 10 {
+			var cmds []*wire.Command
+			for j := 0; j < 10; j++ {
+				cmds = append(cmds, &wire.Command{Cmd: "INCR", Args: []string{"k"}})
+			}
+			if _, err := conn.Write(framedBytes(t, cmds...)); err != nil {
+				return
+			}
+		}
+	}()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	for i := 0; i <= n; i++ {
+		r := &wire.Response{}
+		if err := protodelim.UnmarshalFrom(reader, r); err != nil {
+			t.Fatalf("failed to read response %d: %v", i, err)
+		}
+		if r.Err != "" {
+			t.Fatalf("unexpected error for command %d: %v", i, r.Err)
+		}
+		if i > 0 && r.GetVInt() != int64(i) {
+			t.Fatalf("expected %d, got %v", i, r)
+		}
+	}
+}
+
+func TestEventLoopBlockingCommand(t *testing.T) {
+	// Both connections are served by the same event loop.
+	defer func(n int) { config.Config.NumEventLoops = n }(config.Config.NumEventLoops)
+	config.Config.NumEventLoops = 1
+	addr, _ := startEventLoopServer(t)
+
+	slow, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer slow.Close()
+	handshakeFramed(t, slow)
+	if _, err := slow.Write(framedBytes(t, &wire.Command{Cmd: "DEBUG", Args: []string{"SLEEP", "2"}})); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	// The command blocking must not hold up the other connections.
+	start := time.Now()
+	fast, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer fast.Close()
+	handshakeFramed(t, fast)
+	if _, err := fast.Write(framedBytes(t, &wire.Command{Cmd: "PING"})); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	_ = fast.SetReadDeadline(time.Now().Add(time.Second))
+	r := &wire.Response{}
+	if err := protodelim.UnmarshalFrom(bufio.NewReader(fast), r); err != nil || r.GetVStr() != "PONG" {
+		t.Fatalf("expected PONG, got %v %v", r, err)
+	}
+	if took := time.Since(start); took > time.Second {
+		t.Fatalf("expected the other connection to be served while the command blocks, took %v", took)
+	}
+
+	_ = slow.SetReadDeadline(time.Now().Add(3 * time.Second))
+	r = &wire.Response{}
+	if err := protodelim.UnmarshalFrom(bufio.NewReader(slow), r); err != nil || r.GetVStr() != "OK" {
+		t.Fatalf("expected OK, got %v %v", r, err)
+	}
+}
+
+// benchmarkConnections spreads b.N PING commands over the given number of
+// connections, each sending a command and waiting for its response.
+func benchmarkConnections(b *testing.B, addr string, active func() int, conns int) {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err == nil && limit.Cur < uint64(2*conns+64) {
+		b.Skipf("open files limit %d is too low for %d connections", limit.Cur, conns)
+	}
+
+	req, _ := proto.Marshal(&wire.Command{Cmd: "PING"})
+	buf := make([]byte, 512)
+
+	// Wait for the connections of the previous runs to be released.
+	for active() > 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	before := memInUse()
+	clients := make([]net.Conn, conns)
+	for i := range clients {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			b.Fatalf("failed to dial: %v", err)
+		}
+		defer conn.Close()
+		clients[i] = conn
+
+		// Make sure the connection is being served.
+		if _, err := conn.Write(req); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := conn.Read(buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+	memPerConn := float64(memInUse()-before) / float64(conns)
+	var remaining atomic.Int64
+	remaining.Store(int64(b.N))
+
+	b.ResetTimer()
+	var wg sync.WaitGroup
+	for _, conn := range clients {
+		wg.Add(1)
+		go func(conn net.Conn) {
+			defer wg.Done()
+			buf := make([]byte, 512)
+			for remaining.Add(-1) >= 0 {
+				if _, err := conn.Write(req); err != nil {
+					b.Error(err)
+					return
+				}
+				if _, err := conn.Read(buf); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		}(conn)
+	}
+	wg.Wait()
+	b.ReportMetric(memPerConn, "mem-B/conn")
+}
+
+// memInUse returns the heap and stack memory in use by the process,
+// which includes both the server and the client ends of the connections.
+func memInUse() int64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return int64(m.HeapInuse + m.StackInuse)
+}
+
+var benchmarkConnectionCounts = []int{10, 1000, 8000}
+
+// BenchmarkEventLoopServer serves the connections with the event loops.
+func BenchmarkEventLoopServer(b *testing.B) {
+	addr, active := startEventLoopServer(b)
+	for _, conns := range benchmarkConnectionCounts {
+		b.Run(fmt.Sprintf("conns=%d", conns), func(b *testing.B) {
+			benchmarkConnections(b, addr, active, conns)
+		})
+	}
+}
+
+// BenchmarkGoroutinePerConnServer serves every connection on its own goroutine.
+func BenchmarkGoroutinePerConnServer(b *testing.B) {
+	addr, active := startGoroutineServer(b)
+	for _, conns := range benchmarkConnectionCounts {
+		b.Run(fmt.Sprintf("conns=%d", conns), func(b *testing.B) {
+			benchmarkConnections(b, addr, active, conns)
+		})
+	}
+}
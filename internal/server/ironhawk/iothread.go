@@ -7,18 +7,28 @@ import (
 	"context"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/dicedb/dice/internal/auth"
 	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/id"
 	"github.com/dicedb/dice/internal/shardmanager"
 	"github.com/dicedb/dicedb-go/wire"
 )
 
 type IOThread struct {
+	ID        uint32
 	ClientID  string
 	Mode      string
 	IoHandler *IOHandler
 	Session   *auth.Session
+
+	// LastActive is the time the last command was received and NoEvict,
+	// set with CLIENT NO-EVICT, keeps the connection from being closed
+	// for being idle. Both are only accessed by the event loop owning
+	// the connection.
+	LastActive time.Time
+	NoEvict    bool
 }
 
 func NewIOThread(clientFD int) (*IOThread, error) {
@@ -28,59 +38,156 @@ func NewIOThread(clientFD int) (*IOThread, error) {
 		return nil, err
 	}
 	return &IOThread{
-		IoHandler: io,
-		Session:   auth.NewSession(),
+		ID:         id.NextID(),
+		IoHandler:  io,
+		Session:    auth.NewSession(),
+		LastActive: time.Now(),
 	}, nil
 }
 
-func (t *IOThread) StartSync(ctx context.Context, shardManager *shardmanager.ShardManager, watchManager *WatchManager) error {
+// StartSync serves the connection on the calling goroutine,
+// blocking until the next command is available.
+func (t *IOThread) StartSync(ctx context.Context, shardManager *shardmanager.ShardManager,
+	watchManager *WatchManager, log *cmd.CommandLog) error {
 	for {
 		c, err := t.IoHandler.ReadSync()
 		if err != nil {
 			return err
 		}
-
-		_c := &cmd.Cmd{
-			C:        c,
-			ClientID: t.ClientID,
-			Mode:     t.Mode,
+		if err := t.handle(c, shardManager, watchManager, log); err != nil {
+			return err
 		}
+	}
+}
 
-		res, err := _c.Execute(shardManager)
+// HandleReadable executes all the commands available on the connection
+// without blocking. It is called by the event loop owning the connection
+// once the socket is readable.
+func (t *IOThread) HandleReadable(shardManager *shardmanager.ShardManager,
+	watchManager *WatchManager, log *cmd.CommandLog) error {
+	readErr := t.IoHandler.ReadAvailable()
+	for {
+		c, err := t.IoHandler.Next()
 		if err != nil {
-			res = &cmd.CmdRes{R: &wire.Response{Err: err.Error()}}
+			return err
+		}
+		if c == nil {
+			return readErr
+		}
+		if err := t.handle(c, shardManager, watchManager, log); err != nil {
+			return err
 		}
+	}
+}
 
-		// TODO: Optimize this. We are doing this for all command execution
-		// Also, we are allowing people to override the client ID.
-		// Also, CLientID is duplicated in command and io-thread.
-		// Also, we shouldn't allow execution/registration incase of invalid commands
-		// like for B.WATCH cmd since it'll err out we shall return and not create subscription
-		t.ClientID = _c.ClientID
+// handle executes the command and writes its response.
+func (t *IOThread) handle(c *wire.Command, shardManager *shardmanager.ShardManager,
+	watchManager *WatchManager, log *cmd.CommandLog) error {
+	t.LastActive = time.Now()
+	_c := &cmd.Cmd{
+		C:        c,
+		ClientID: t.ClientID,
+		Mode:     t.Mode,
+		WAL:      log,
+	}
 
-		if c.Cmd == "HANDSHAKE" {
-			t.ClientID = _c.C.Args[0]
-			t.Mode = _c.C.Args[1]
-		}
+	res, err := _c.Execute(shardManager)
+	if err != nil {
+		res = &cmd.CmdRes{R: &wire.Response{Err: err.Error()}}
+	}
 
-		if strings.HasSuffix(c.Cmd, ".WATCH") {
-			watchManager.HandleWatch(_c, t)
-		}
+	// TODO: Optimize this. We are doing this for all command execution
+	// Also, we are allowing people to override the client ID.
+	// Also, CLientID is duplicated in command and io-thread.
+	// Also, we shouldn't allow execution/registration incase of invalid commands
+	// like for B.WATCH cmd since it'll err out we shall return and not create subscription
+	t.ClientID = _c.ClientID
+
+	if c.Cmd == "HANDSHAKE" && err == nil {
+		t.ClientID = _c.C.Args[0]
+		t.Mode = _c.C.Args[1]
+	}
+
+	if c.Cmd == "CLIENT" && err == nil {
+		t.NoEvict, _ = cmd.ClientNoEvict(c)
+	}
+
+	if strings.HasSuffix(c.Cmd, ".WATCH") && err == nil {
+		watchManager.HandleWatch(_c, t)
+	}
 
-		if strings.HasSuffix(c.Cmd, "UNWATCH") {
-			watchManager.HandleUnwatch(_c, t)
+	if strings.HasSuffix(c.Cmd, "UNWATCH") {
+		watchManager.HandleUnwatch(_c, t)
+	}
+
+	// The introspection of the subscriptions is handled by the watch manager.
+	if c.Cmd == "WATCH.LIST" && err == nil {
+		res = &cmd.CmdRes{R: watchManager.ListResponse()}
+	}
+	if c.Cmd == "WATCH.STATS" && err == nil {
+		res = &cmd.CmdRes{R: watchManager.StatsResponse()}
+	}
+
+	// The pub/sub commands are handled by the watch manager.
+	if err == nil {
+		if r := watchManager.HandlePubSub(_c, t); r != nil {
+			res = &cmd.CmdRes{R: r}
 		}
+	}
 
-		watchManager.RegisterThread(t)
+	watchManager.RegisterThread(t)
 
-		if err := t.IoHandler.WriteSync(ctx, res.R); err != nil {
+	if err := t.IoHandler.WriteBuffered(res.R); err != nil {
+		return err
+	}
+
+	// Responses to pipelined commands are batched and flushed
+	// only when there are no more commands buffered on the connection.
+	if !t.IoHandler.HasPending() {
+		if err := t.IoHandler.Flush(); err != nil {
 			return err
 		}
+	}
+
+	// The response to the HANDSHAKE is always unframed and
+	// the framing, if negotiated, applies to everything after it.
+	if c.Cmd == "HANDSHAKE" && err == nil && cmd.IsFramedHandshake(c) {
+		t.IoHandler.EnableFraming()
+	}
+
+	// A watch connection resuming its subscriptions gets
+	// the updates it missed right after the HANDSHAKE.
+	if c.Cmd == "HANDSHAKE" && err == nil && t.Mode == "watch" {
+		if h, err := cmd.ParseHandshake(c); err == nil && len(h.Resume) > 0 {
+			watchManager.Resume(t, h.Resume)
+		}
+	}
+
+	// The change stream starts once the client got the response.
+	if c.Cmd == "CDC.SUBSCRIBE" && err == nil {
+		if s, err := cmd.ParseCDCSubscribe(_c); err == nil {
+			watchManager.SubscribeCDC(t, s)
+		}
+	}
 
-		// TODO: Streamline this because we need ordering of updates
-		// that are being sent to watchers.
+	// A failed command has not modified any data.
+	if err != nil {
+		return nil
+	}
+
+	// TODO: Streamline this because we need ordering of updates
+	// that are being sent to watchers.
+	if c.Cmd == "BATCH" {
+		// Every command in the batch may have modified a watched key.
+		if b, err := cmd.ParseBatch(_c); err == nil {
+			for _, bc := range b.Cmds {
+				watchManager.NotifyWatchers(bc, shardManager, t)
+			}
+		}
+	} else {
 		watchManager.NotifyWatchers(_c, shardManager, t)
 	}
+	return nil
 }
 
 func (t *IOThread) Stop() error {
@@ -11,6 +11,10 @@ import (
 	"github.com/dicedb/dice/config"
 )
 
+// IOThreadManager tracks the io-threads of all the connected clients.
+// The io-threads are keyed by their ID and not by the client ID because
+// a client opens one connection for commands and one for watch updates,
+// both of which carry the same client ID.
 type IOThreadManager struct {
 	connectedClients sync.Map
 	numIOThreads     atomic.Uint32
@@ -34,7 +38,7 @@ func (m *IOThreadManager) RegisterIOThread(ioThread *IOThread) error {
 		return ErrMaxClientsReached
 	}
 
-	m.connectedClients.Store(ioThread.ClientID, ioThread)
+	m.connectedClients.Store(ioThread.ID, ioThread)
 	m.numIOThreads.Add(1)
 	return nil
 }
@@ -43,7 +47,7 @@ func (m *IOThreadManager) IOThreadCount() uint32 {
 	return m.numIOThreads.Load()
 }
 
-func (m *IOThreadManager) UnregisterIOThread(id string) error {
+func (m *IOThreadManager) UnregisterIOThread(id uint32) error {
 	if client, loaded := m.connectedClients.LoadAndDelete(id); loaded {
 		w := client.(*IOThread)
 		if err := w.Stop(); err != nil {
@@ -56,3 +60,12 @@ func (m *IOThreadManager) UnregisterIOThread(id string) error {
 	m.numIOThreads.Add(^uint32(0))
 	return nil
 }
+
+// ForEach calls f for every registered io-thread.
+// Threads registered or unregistered while iterating may or may not be visited.
+func (m *IOThreadManager) ForEach(f func(t *IOThread)) {
+	m.connectedClients.Range(func(_, v any) bool {
+		f(v.(*IOThread))
+		return true
+	})
+}
@@ -10,11 +10,19 @@ import (
 	"io"
 	"log/slog"
 	"net"
+	"runtime"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/iomultiplexer"
 	"github.com/dicedb/dice/internal/shardmanager"
+	"github.com/dicedb/dice/internal/wal"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type Server struct {
@@ -25,9 +33,28 @@ type Server struct {
 	shardManager    *shardmanager.ShardManager
 	watchManager    *WatchManager
 	ioThreadManager *IOThreadManager
+	wl              wal.AbstractWAL
+	log             *cmd.CommandLog
+	shutdownTimeout time.Duration
+
+	// idleTimeout is the time after which a connection not sending any
+	// command is closed and watchPingInterval the time after which a
+	// watch connection nothing was written to is pinged. Zero disables them.
+	idleTimeout       time.Duration
+	watchPingInterval time.Duration
+
+	// eventLoops serve the client connections, which are assigned
+	// to them in a round-robin fashion as they are accepted.
+	eventLoops []*eventLoop
+	nextLoop   int
+
+	// eventLoopWg tracks the running event loops so that
+	// the shutdown can wait for the in-flight commands.
+	eventLoopWg sync.WaitGroup
 }
 
-func NewServer(shardManager *shardmanager.ShardManager, ioThreadManager *IOThreadManager, watchManager *WatchManager) *Server {
+func NewServer(shardManager *shardmanager.ShardManager, ioThreadManager *IOThreadManager,
+	watchManager *WatchManager, wl wal.AbstractWAL) *Server {
 	return &Server{
 		Host:            config.Config.Host,
 		Port:            config.Config.Port,
@@ -35,6 +62,12 @@ func NewServer(shardManager *shardmanager.ShardManager, ioThreadManager *IOThrea
 		shardManager:    shardManager,
 		ioThreadManager: ioThreadManager,
 		watchManager:    watchManager,
+		wl:              wl,
+		log:             cmd.NewCommandLog(wl),
+		shutdownTimeout: time.Duration(config.Config.ShutdownTimeoutSec) * time.Second,
+
+		idleTimeout:       time.Duration(config.Config.IdleTimeoutSec) * time.Second,
+		watchPingInterval: time.Duration(config.Config.WatchPingIntervalSec) * time.Second,
 	}
 }
 
@@ -43,16 +76,22 @@ func (s *Server) Run(ctx context.Context) (err error) {
 		slog.Error("failed to bind server", slog.Any("error", err))
 		return err
 	}
+	warnOnFDLimit()
 
-	defer releasePort(s.serverFD)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	errChan := make(chan error, 1)
-	wg := &sync.WaitGroup{}
+	if err = s.startEventLoops(ctx, errChan); err != nil {
+		releasePort(s.serverFD)
+		return err
+	}
 
+	wg := &sync.WaitGroup{}
 	wg.Add(1)
 	go func(wg *sync.WaitGroup) {
 		defer wg.Done()
-		if err := s.AcceptConnectionRequests(ctx, wg); err != nil {
+		if err := s.AcceptConnectionRequests(ctx); err != nil && !errors.Is(err, context.Canceled) {
 			errChan <- fmt.Errorf("failed to accept connections %w", err)
 		}
 	}(wg)
@@ -61,17 +100,65 @@ func (s *Server) Run(ctx context.Context) (err error) {
 	case <-ctx.Done():
 		slog.Info("initiating shutdown")
 	case err = <-errChan:
-		slog.Error("error while accepting connections, initiating shutdown", slog.Any("error", err))
+		slog.Error("error while serving connections, initiating shutdown", slog.Any("error", err))
 	}
 
+	// Stop accepting connections and reading commands,
+	// the commands being executed are drained by the shutdown.
+	cancel()
+	wg.Wait()
 	s.Shutdown()
-
-	wg.Wait() // Wait for the go routines to finish
 	slog.Info("exiting gracefully")
 
 	return err
 }
 
+// startEventLoops starts the event loops serving the client connections.
+// The number of event loops defaults to the number of cores.
+func (s *Server) startEventLoops(ctx context.Context, errChan chan<- error) error {
+	n := runtime.NumCPU()
+	if config.Config.NumEventLoops > 0 {
+		n = config.Config.NumEventLoops
+	}
+
+	s.eventLoops = make([]*eventLoop, n)
+	for i := range s.eventLoops {
+		l, err := newEventLoop(i, config.Config.EventLoopWorkers)
+		if err != nil {
+			return err
+		}
+		s.eventLoops[i] = l
+	}
+
+	for _, l := range s.eventLoops {
+		s.eventLoopWg.Add(1)
+		go func(l *eventLoop) {
+			defer s.eventLoopWg.Done()
+			if err := l.run(ctx, s); err != nil {
+				select {
+				case errChan <- fmt.Errorf("event loop %d failed %w", l.id, err):
+				default: // The shutdown is already initiated
+				}
+			}
+		}(l)
+	}
+	return nil
+}
+
+// warnOnFDLimit warns if the limit on the number of open files
+// does not allow the server to accept max-clients connections.
+func warnOnFDLimit() {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return
+	}
+	if limit.Cur < uint64(config.Config.MaxClients) {
+		slog.Warn("the open files limit is lower than max-clients, raise it with ulimit -n",
+			slog.Any("limit", limit.Cur),
+			slog.Int("max_clients", config.Config.MaxClients))
+	}
+}
+
 func (s *Server) BindAndListen() error {
 	serverFD, socketErr := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)
 	if socketErr != nil {
@@ -126,52 +213,183 @@ func releasePort(serverFD int) {
 	}
 }
 
-func (s *Server) AcceptConnectionRequests(ctx context.Context, wg *sync.WaitGroup) error {
+// AcceptConnectionRequests waits for the listening socket to become
+// readable and accepts the pending connections until the context is canceled.
+func (s *Server) AcceptConnectionRequests(ctx context.Context) error {
+	mux, err := iomultiplexer.New()
+	if err != nil {
+		return err
+	}
+	defer mux.Close()
+
+	if err := mux.Subscribe(iomultiplexer.Event{Fd: s.serverFD, Op: iomultiplexer.OpRead}); err != nil {
+		return err
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			slog.Info("no new connections will be accepted")
 			return ctx.Err()
 		default:
-			clientFD, _, err := syscall.Accept(s.serverFD)
-			if err != nil {
-				if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EWOULDBLOCK) {
-					continue // No more connections to accept at this time
-				}
-				return fmt.Errorf("error accepting connection: %w", err)
+		}
+
+		events, err := mux.Poll(eventLoopPollTimeout)
+		if err != nil {
+			if errors.Is(err, syscall.EINTR) {
+				continue
 			}
+			return err
+		}
+		if len(events) == 0 {
+			continue
+		}
+
+		if err := s.acceptPending(); err != nil {
+			return err
+		}
+	}
+}
 
-			thread, err := NewIOThread(clientFD)
-			if err != nil {
-				slog.Error("failed to create io-thread", slog.String("id", "-xxx"), slog.Any("error", err))
+// acceptPending accepts all the pending connections and
+// assigns each of them to one of the event loops.
+func (s *Server) acceptPending() error {
+	for {
+		clientFD, _, err := syscall.Accept(s.serverFD)
+		if err != nil {
+			switch {
+			case errors.Is(err, syscall.EAGAIN), errors.Is(err, syscall.EWOULDBLOCK):
+				return nil // No more connections to accept at this time
+			case errors.Is(err, syscall.EINTR), errors.Is(err, syscall.ECONNABORTED):
 				continue
+			case errors.Is(err, syscall.EMFILE), errors.Is(err, syscall.ENFILE):
+				// The pending connections are accepted once
+				// some of the connected clients disconnect.
+				slog.Warn("too many open files, not accepting connections", slog.Any("error", err))
+				time.Sleep(eventLoopPollTimeout)
+				return nil
 			}
+			return fmt.Errorf("error accepting connection: %w", err)
+		}
 
-			wg.Add(1)
-			go s.startIOThread(ctx, wg, thread)
+		thread, err := NewIOThread(clientFD)
+		if err != nil {
+			slog.Error("failed to create io-thread", slog.String("id", "-xxx"), slog.Any("error", err))
+			continue
+		}
+
+		if err := s.ioThreadManager.RegisterIOThread(thread); err != nil {
+			slog.Warn("rejecting connection", slog.Any("error", err))
+			_ = thread.IoHandler.Close()
+			continue
+		}
+
+		l := s.eventLoops[s.nextLoop%len(s.eventLoops)]
+		s.nextLoop++
+		if err := l.add(thread); err != nil {
+			slog.Error("failed to add the connection to the event loop", slog.Int("event_loop", l.id), slog.Any("error", err))
+			s.closeIOThread(thread, err)
 		}
 	}
 }
 
-func (s *Server) startIOThread(ctx context.Context, wg *sync.WaitGroup, thread *IOThread) {
-	wg.Done()
-	err := thread.StartSync(ctx, s.shardManager, s.watchManager)
-	if err != nil {
-		if err == io.EOF {
-			s.watchManager.CleanupThreadWatchSubscriptions(thread)
-			slog.Debug("client disconnected. io-thread stopped",
-				slog.String("client_id", thread.ClientID),
-				slog.String("mode", thread.Mode),
-			)
-		} else {
-			slog.Debug("io-thread errored out",
-				slog.String("client_id", thread.ClientID),
-				slog.String("mode", thread.Mode),
-				slog.Any("error", err))
+func (s *Server) handleReadable(thread *IOThread) error {
+	return thread.HandleReadable(s.shardManager, s.watchManager, s.log)
+}
+
+// watchPing is sent to the watch connections to keep them alive. Unlike
+// the watch updates it carries no fingerprint, and its type attribute tells
+// it apart from them.
+var watchPing = &wire.Response{
+	Value: &wire.Response_VStr{VStr: "PING"},
+	Attrs: &structpb.Struct{Fields: map[string]*structpb.Value{
+		"type": structpb.NewStringValue("ping"),
+	}},
+}
+
+// checkIOThread closes the connection if it has been idle for longer than
+// the idle timeout. Watch connections are not expected to send commands
+// and are instead pinged when nothing was written to them for a while.
+// The ping is queued along with the watch updates, for the event loop never
+// to wait on a write to a slow watch client.
+func (s *Server) checkIOThread(thread *IOThread, now time.Time) error {
+	if thread.Mode == "watch" {
+		if s.watchPingInterval > 0 && now.Sub(thread.IoHandler.LastWrite()) >= s.watchPingInterval {
+			s.watchManager.Ping(thread)
 		}
+		return nil
 	}
+	if s.idleTimeout > 0 && !thread.NoEvict && now.Sub(thread.LastActive) >= s.idleTimeout {
+		return ErrIdleTimeout
+	}
+	return nil
 }
 
+// closeIOThread releases the io-thread once its connection is closed or failed.
+func (s *Server) closeIOThread(thread *IOThread, err error) {
+	if thread.Mode == "watch" {
+		s.watchManager.CleanupThreadWatchSubscriptions(thread)
+	}
+	_ = s.ioThreadManager.UnregisterIOThread(thread.ID)
+	_ = thread.IoHandler.Close()
+
+	if err == io.EOF {
+		slog.Debug("client disconnected. io-thread stopped",
+			slog.String("client_id", thread.ClientID),
+			slog.String("mode", thread.Mode),
+		)
+	} else {
+		slog.Debug("io-thread errored out",
+			slog.String("client_id", thread.ClientID),
+			slog.String("mode", thread.Mode),
+			slog.Any("error", err))
+	}
+}
+
+// Shutdown drains the server. It stops accepting connections, lets the
+// watch clients know the server is going away and gives the in-flight
+// commands up to the shutdown timeout to finish. It then flushes and closes
+// the WAL, after which no write can be acknowledged, and closes all the
+// remaining connections.
 func (s *Server) Shutdown() {
-	// Not implemented
+	releasePort(s.serverFD)
+
+	deadline := time.Now().Add(s.shutdownTimeout)
+	slog.Info("draining connections",
+		slog.Int("connections", int(s.ioThreadManager.IOThreadCount())),
+		slog.Duration("timeout", s.shutdownTimeout))
+
+	s.ioThreadManager.ForEach(func(t *IOThread) {
+		// A client that is not reading must not hold up the shutdown.
+		_ = t.IoHandler.SetWriteDeadline(deadline)
+		if t.Mode == "watch" {
+			_ = t.IoHandler.WriteSync(context.Background(), &wire.Response{
+				Err: diceerrors.ErrServerShuttingDown.Error(),
+			})
+		}
+	})
+
+	// The event loops no longer read new commands and
+	// stop once they have responded to the ones being executed.
+	done := make(chan struct{})
+	go func() {
+		s.eventLoopWg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		slog.Info("all in-flight commands completed")
+	case <-time.After(time.Until(deadline)):
+		slog.Warn("timed out waiting for in-flight commands",
+			slog.Int("connections", int(s.ioThreadManager.IOThreadCount())))
+	}
+
+	if err := s.wl.Close(); err != nil {
+		slog.Error("failed to close the WAL", slog.Any("error", err))
+	}
+
+	s.ioThreadManager.ForEach(func(t *IOThread) {
+		_ = t.IoHandler.Close()
+	})
 }
@@ -4,17 +4,21 @@
 package ironhawk
 
 import (
-	"bufio"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -24,11 +28,53 @@ var (
 	ErrorClosed        = errors.New("connection closed")
 )
 
+// readBufPool holds the buffers the connections read into. A connection
+// holds on to a buffer only while it has bytes that are not yet parsed,
+// so that idle connections do not cost an io buffer each.
+var readBufPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, config.IoBufferSize)
+		return &b
+	},
+}
+
+// maxBuffered is the number of bytes a connection buffers at most before
+// a command is complete: the largest request along with its length prefix.
+const maxBuffered = config.MaxRequestSize + binary.MaxVarintLen64
+
 // IOHandler handles I/O operations for a network connection
+//
+// A connection starts in the legacy unframed mode where a command is read
+// until a short read. Once framing is enabled (see EnableFraming) every
+// wire.Command and wire.Response is prefixed with its length encoded as a
+// varint, which allows clients to pipeline commands on the connection.
+//
+// Commands are either read with ReadSync, which blocks until a command is
+// available, or by an event loop calling ReadAvailable once the socket is
+// readable followed by Next until no complete command is left.
 type IOHandler struct {
 	fd   int
-	file *os.File
 	conn net.Conn
+
+	// rbuf holds the bytes read from the connection and roff
+	// the offset of the first byte not yet parsed into a command.
+	rbuf *[]byte
+	roff int
+
+	// wmu guards the write buffer given that watch notifications are
+	// written from io-threads other than the one owning the connection.
+	wmu    sync.Mutex
+	wbuf   []byte
+	framed bool
+
+	// writeTimeout bounds the time a write to a client not reading
+	// its responses can take. A connection is broken once a write
+	// fails since the response may have been partially written.
+	writeTimeout time.Duration
+	broken       bool
+
+	// lastWrite is the time, in unix nanoseconds, of the last write.
+	lastWrite atomic.Int64
 }
 
 // NewIOHandler creates a new IOHandler from a file descriptor
@@ -38,43 +84,107 @@ func NewIOHandler(clientFD int) (*IOHandler, error) {
 		return nil, fmt.Errorf("failed to create file from file descriptor")
 	}
 
-	var conn net.Conn
-	defer func() {
-		// Only close the file if we haven't successfully created a net.Conn
-		if conn == nil {
-			file.Close()
-		}
-	}()
-
-	var err error
-	conn, err = net.FileConn(file)
+	// net.FileConn duplicates the file descriptor, the original one
+	// is closed so that a connection holds a single file descriptor.
+	conn, err := net.FileConn(file)
+	file.Close()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create net.Conn from file descriptor: %w", err)
 	}
 
-	if tcpConn, ok := conn.(*net.TCPConn); ok {
-		if err := tcpConn.SetNoDelay(true); err != nil {
-			return nil, fmt.Errorf("failed to set TCP_NODELAY: %w", err)
-		}
-		if err := tcpConn.SetKeepAlive(true); err != nil {
-			return nil, fmt.Errorf("failed to set keepalive: %w", err)
-		}
-		if err := tcpConn.SetKeepAlivePeriod(time.Duration(config.KeepAlive) * time.Second); err != nil {
-			return nil, fmt.Errorf("failed to set keepalive period: %w", err)
-		}
+	h, err := newIOHandler(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+func newIOHandler(conn net.Conn) (*IOHandler, error) {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil, fmt.Errorf("unsupported connection type %T", conn)
+	}
+
+	if err := tcpConn.SetNoDelay(true); err != nil {
+		return nil, fmt.Errorf("failed to set TCP_NODELAY: %w", err)
+	}
+	if err := tcpConn.SetKeepAlive(true); err != nil {
+		return nil, fmt.Errorf("failed to set keepalive: %w", err)
+	}
+	if err := tcpConn.SetKeepAlivePeriod(time.Duration(config.KeepAlive) * time.Second); err != nil {
+		return nil, fmt.Errorf("failed to set keepalive period: %w", err)
 	}
 
-	return &IOHandler{
-		fd:   clientFD,
-		file: file,
-		conn: conn,
-	}, nil
+	// The event loops poll and read the file descriptor directly.
+	// It is non-blocking as it is managed by the Go runtime.
+	raw, err := tcpConn.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	fd := -1
+	if err := raw.Control(func(f uintptr) { fd = int(f) }); err != nil {
+		return nil, err
+	}
+
+	return newIOHandlerWithFD(fd, conn), nil
 }
 
 func NewIOHandlerWithConn(conn net.Conn) *IOHandler {
-	return &IOHandler{
-		conn: conn,
+	return newIOHandlerWithFD(-1, conn)
+}
+
+func newIOHandlerWithFD(fd int, conn net.Conn) *IOHandler {
+	h := &IOHandler{
+		fd:           fd,
+		conn:         conn,
+		writeTimeout: time.Duration(config.Config.WriteTimeoutMillis) * time.Millisecond,
 	}
+	h.lastWrite.Store(time.Now().UnixNano())
+	return h
+}
+
+// LastWrite returns the time of the last successful write to the connection.
+func (h *IOHandler) LastWrite() time.Time {
+	return time.Unix(0, h.lastWrite.Load())
+}
+
+// FD returns the file descriptor of the connection
+// or -1 if the handler was created from a net.Conn.
+func (h *IOHandler) FD() int {
+	return h.fd
+}
+
+// EnableFraming switches the connection to the length-prefixed framing.
+// It must be called from the io-thread owning the connection, after the
+// response to the command that negotiated the framing has been flushed.
+// The unframed command has no boundary but the read it arrives in, hence
+// the client must wait for its response before sending any framed command,
+// any byte sent along with it being parsed as part of it.
+func (h *IOHandler) EnableFraming() {
+	h.wmu.Lock()
+	defer h.wmu.Unlock()
+	h.framed = true
+}
+
+// Framed returns true if the connection uses length-prefixed framing.
+func (h *IOHandler) Framed() bool {
+	h.wmu.Lock()
+	defer h.wmu.Unlock()
+	return h.framed
+}
+
+// HasPending returns true if a pipelined command is already buffered
+// and can be read without blocking on the network.
+// For unframed connections this is always false since command boundaries
+// cannot be determined.
+func (h *IOHandler) HasPending() bool {
+	if !h.Framed() || h.buffered() == 0 {
+		return false
+	}
+	b := (*h.rbuf)[h.roff:]
+	size, n := protowire.ConsumeVarint(b)
+	return n > 0 && uint64(len(b)-n) >= size
 }
 
 // ReadRequest reads data from the network connection
@@ -82,39 +192,168 @@ func (h *IOHandler) Read(ctx context.Context) ([]byte, error) {
 	return nil, nil
 }
 
-// ReadRequest reads data from the network connection
+// ReadSync reads the next command from the network connection
 func (h *IOHandler) ReadSync() (*wire.Command, error) {
-	var result []byte
-	reader := bufio.NewReaderSize(h.conn, config.IoBufferSize)
-	buf := make([]byte, config.IoBufferSize)
+	if !h.Framed() {
+		return h.readUnframed()
+	}
 
 	for {
-		n, err := reader.Read(buf)
-		if n > 0 {
-			if len(result)+n > config.MaxRequestSize {
-				return nil, fmt.Errorf("request too large")
-			}
-
-			result = append(result, buf[:n]...)
+		c, err := h.Next()
+		if c != nil || err != nil {
+			return c, err
 		}
+		if _, err := h.fill(h.conn.Read); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readUnframed reads until a short read and parses
+// everything read until then as a single command.
+func (h *IOHandler) readUnframed() (*wire.Command, error) {
+	for {
+		n, err := h.fill(h.conn.Read)
 		if err != nil {
-			if err == io.EOF {
+			if err == io.EOF && h.buffered() > 0 {
 				break
 			}
 			return nil, err
 		}
-
-		if n < len(buf) {
+		if n < config.IoBufferSize && h.buffered() > 0 {
 			break
 		}
 	}
+	return h.Next()
+}
+
+// ReadAvailable reads all the bytes available on the socket without
+// blocking. It is meant to be called once the event loop reports the
+// socket as readable, after which the commands are parsed with Next.
+// It returns io.EOF once the client closed the connection, in which
+// case the commands read before the EOF are still available.
+func (h *IOHandler) ReadAvailable() error {
+	for {
+		n, err := h.fill(func(b []byte) (int, error) {
+			n, err := syscall.Read(h.fd, b)
+			if n < 0 {
+				n = 0
+			}
+			if err == nil && n == 0 {
+				return 0, io.EOF
+			}
+			return n, err
+		})
+		switch {
+		case errors.Is(err, syscall.EAGAIN), errors.Is(err, syscall.EWOULDBLOCK):
+			return nil
+		case errors.Is(err, syscall.EINTR):
+			continue
+		case err != nil:
+			return err
+		case n < config.IoBufferSize, h.buffered() > maxBuffered:
+			// The bytes left on the socket past the limit are read once
+			// the commands buffered are executed, the socket being
+			// reported readable again.
+			return nil
+		}
+	}
+}
+
+// fill reads once from the connection into the read buffer
+// growing the buffer if needed. It returns ErrRequestTooLarge, without
+// reading, once more than maxBuffered bytes are buffered and none of
+// them makes a complete command, so that a client never has the
+// connection buffer an unbounded request.
+func (h *IOHandler) fill(read func([]byte) (int, error)) (int, error) {
+	if h.buffered() > maxBuffered && !h.HasPending() {
+		return 0, ErrRequestTooLarge
+	}
+	if h.rbuf == nil {
+		h.rbuf = readBufPool.Get().(*[]byte)
+	}
+
+	b := *h.rbuf
+	if h.roff > 0 && cap(b)-len(b) < config.IoBufferSize {
+		// Move the unparsed bytes to the front to make room.
+		b = b[:copy(b, b[h.roff:])]
+		h.roff = 0
+	}
+	if cap(b)-len(b) < config.IoBufferSize {
+		nb := make([]byte, len(b), 2*cap(b)+config.IoBufferSize)
+		copy(nb, b)
+		b = nb
+	}
+
+	n, err := read(b[len(b) : len(b)+config.IoBufferSize])
+	*h.rbuf = b[:len(b)+n]
+	return n, err
+}
+
+// buffered returns the number of bytes read but not yet parsed.
+func (h *IOHandler) buffered() int {
+	if h.rbuf == nil {
+		return 0
+	}
+	return len(*h.rbuf) - h.roff
+}
+
+// consume marks n of the buffered bytes as parsed
+// and releases the read buffer once it is empty.
+func (h *IOHandler) consume(n int) {
+	h.roff += n
+	if h.buffered() > 0 {
+		return
+	}
+
+	// Buffers grown for large requests are not pooled.
+	if cap(*h.rbuf) <= config.IoBufferSize*4 {
+		*h.rbuf = (*h.rbuf)[:0]
+		readBufPool.Put(h.rbuf)
+	}
+	h.rbuf, h.roff = nil, 0
+}
+
+// Next parses the next command from the bytes read from the connection.
+// It returns nil if no complete command is buffered.
+// For unframed connections all the buffered bytes make a single command.
+func (h *IOHandler) Next() (*wire.Command, error) {
+	if h.buffered() == 0 {
+		return nil, nil
+	}
+
+	b := (*h.rbuf)[h.roff:]
+	if !h.Framed() {
+		if len(b) > config.MaxRequestSize {
+			return nil, ErrRequestTooLarge
+		}
+		c := &wire.Command{}
+		err := proto.Unmarshal(b, c)
+		h.consume(len(b))
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal command: %w", err)
+		}
+		return c, nil
+	}
 
-	if len(result) == 0 {
-		return nil, io.EOF
+	size, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		if err := protowire.ParseError(n); err != io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("failed to read command length: %w", err)
+		}
+		return nil, nil
+	}
+	if size > config.MaxRequestSize {
+		return nil, ErrRequestTooLarge
+	}
+	if uint64(len(b)-n) < size {
+		return nil, nil
 	}
 
 	c := &wire.Command{}
-	if err := proto.Unmarshal(result, c); err != nil {
+	err := proto.Unmarshal(b[n:n+int(size)], c)
+	h.consume(n + int(size))
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal command: %w", err)
 	}
 	return c, nil
@@ -124,30 +363,114 @@ func (h *IOHandler) Write(ctx context.Context, r interface{}) error {
 	return nil
 }
 
+// WriteSync writes the response to the network connection and flushes
+// it along with any responses buffered before it.
 func (h *IOHandler) WriteSync(ctx context.Context, r *wire.Response) error {
-	var b []byte
-	var err error
+	h.wmu.Lock()
+	defer h.wmu.Unlock()
 
-	if b, err = proto.Marshal(r); err != nil {
+	if err := h.write(r); err != nil {
 		return err
 	}
+	return h.flush()
+}
 
-	if _, err := h.conn.Write(b); err != nil {
-		return err
+// WriteBuffered writes the response to the connection buffer without
+// flushing it. This lets the io-thread batch the responses of pipelined
+// commands into fewer writes. Call Flush once the batch is complete.
+func (h *IOHandler) WriteBuffered(r *wire.Response) error {
+	h.wmu.Lock()
+	defer h.wmu.Unlock()
+	return h.write(r)
+}
+
+// Flush writes all the buffered responses to the network connection.
+func (h *IOHandler) Flush() error {
+	h.wmu.Lock()
+	defer h.wmu.Unlock()
+	return h.flush()
+}
+
+func (h *IOHandler) flush() error {
+	if len(h.wbuf) == 0 {
+		return nil
+	}
+	if h.broken {
+		h.wbuf = h.wbuf[:0]
+		return ErrorClosed
+	}
+
+	if h.writeTimeout > 0 {
+		_ = h.conn.SetWriteDeadline(time.Now().Add(h.writeTimeout))
+	}
+	_, err := h.conn.Write(h.wbuf)
+
+	// Keep the buffer around for the next responses
+	// unless it grew for a large response.
+	if cap(h.wbuf) > config.IoBufferSize {
+		h.wbuf = nil
+	} else {
+		h.wbuf = h.wbuf[:0]
 	}
 
+	if err != nil {
+		h.abort()
+		return err
+	}
+	h.lastWrite.Store(time.Now().UnixNano())
 	return nil
 }
 
-// Close underlying network connection
-func (h *IOHandler) Close() error {
-	var err error
-	if h.conn != nil {
-		err = errors.Join(err, h.conn.Close())
+// abort marks the connection as broken and shuts down its reading side,
+// which makes the io-thread owning the connection see an EOF and close it.
+// The connection is not closed right away because its file descriptor is
+// still registered with the event loop owning it.
+func (h *IOHandler) abort() {
+	h.broken = true
+	if c, ok := h.conn.(interface{ CloseRead() error }); ok {
+		_ = c.CloseRead()
 	}
-	if h.file != nil {
-		err = errors.Join(err, h.file.Close())
+}
+
+// shutdown makes the io-thread owning the connection see an EOF and close
+// it, like abort, but without waiting for the write in progress, if any.
+// It is safe to call from any goroutine.
+func (h *IOHandler) shutdown() {
+	if c, ok := h.conn.(interface{ CloseRead() error }); ok {
+		_ = c.CloseRead()
+		return
+	}
+	_ = h.conn.Close()
+}
+
+func (h *IOHandler) write(r *wire.Response) error {
+	if h.broken {
+		return ErrorClosed
 	}
 
+	var err error
+	if h.framed {
+		h.wbuf = protowire.AppendVarint(h.wbuf, uint64(proto.Size(r)))
+	}
+	h.wbuf, err = proto.MarshalOptions{}.MarshalAppend(h.wbuf, r)
 	return err
 }
+
+// SetReadDeadline sets the deadline for the pending and future reads on the connection.
+// A read blocked past the deadline fails with os.ErrDeadlineExceeded.
+func (h *IOHandler) SetReadDeadline(t time.Time) error {
+	return h.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline for the pending and future writes on the connection.
+func (h *IOHandler) SetWriteDeadline(t time.Time) error {
+	return h.conn.SetWriteDeadline(t)
+}
+
+// Close underlying network connection
+func (h *IOHandler) Close() error {
+	if h.conn == nil {
+		return nil
+	}
+	return h.conn.Close()
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// newConnPair returns the server and the client ends of a loopback TCP connection.
+func newConnPair(tb testing.TB) (server, client net.Conn) {
+	tb.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			accepted <- nil
+			return
+		}
+		accepted <- conn
+	}()
+
+	client, err = net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		tb.Fatalf("failed to dial: %v", err)
+	}
+	server = <-accepted
+	if server == nil {
+		tb.Fatalf("failed to accept")
+	}
+	tb.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+	return server, client
+}
+
+func framedBytes(tb testing.TB, cmds ...*wire.Command) []byte {
+	tb.Helper()
+	var b []byte
+	for _, c := range cmds {
+		m, err := proto.Marshal(c)
+		if err != nil {
+			tb.Fatalf("failed to marshal command: %v", err)
+		}
+		b = protowire.AppendVarint(b, uint64(len(m)))
+		b = append(b, m...)
+	}
+	return b
+}
+
+func TestReadSyncFramedPipelined(t *testing.T) {
+	server, client := newConnPair(t)
+	h := NewIOHandlerWithConn(server)
+	h.EnableFraming()
+
+	// Both the commands are sent in a single write and hence
+	// most likely arrive in a single TCP segment.
+	b := framedBytes(t,
+		&wire.Command{Cmd: "SET", Args: []string{"k", "v"}},
+		&wire.Command{Cmd: "GET", Args: []string{"k"}},
+	)
+	if _, err := client.Write(b); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	c1, err := h.ReadSync()
+	if err != nil {
+		t.Fatalf("failed to read first command: %v", err)
+	}
+	if c1.Cmd != "SET" || strings.Join(c1.Args, " ") != "k v" {
+		t.Errorf("unexpected first command: %v", c1)
+	}
+	if !h.HasPending() {
+		t.Errorf("expected the second command to be pending")
+	}
+
+	c2, err := h.ReadSync()
+	if err != nil {
+		t.Fatalf("failed to read second command: %v", err)
+	}
+	if c2.Cmd != "GET" || strings.Join(c2.Args, " ") != "k" {
+		t.Errorf("unexpected second command: %v", c2)
+	}
+	if h.HasPending() {
+		t.Errorf("expected no pending commands")
+	}
+}
+
+func TestReadSyncFramedExactBufferSize(t *testing.T) {
+	server, client := newConnPair(t)
+	h := NewIOHandlerWithConn(server)
+	h.EnableFraming()
+
+	// Pad the value so that the framed command exactly fills the io buffer.
+	c := &wire.Command{Cmd: "SET", Args: []string{"k", ""}}
+	for len(framedBytes(t, c)) < config.IoBufferSize {
+		c.Args[1] += "v"
+	}
+	b := framedBytes(t, c)
+	if len(b) != config.IoBufferSize {
+		t.Skipf("could not pad the command to %d bytes, got %d", config.IoBufferSize, len(b))
+	}
+
+	go func() {
+		_, _ = client.Write(b)
+	}()
+
+	r, err := h.ReadSync()
+	if err != nil {
+		t.Fatalf("failed to read command: %v", err)
+	}
+	if r.Args[1] != c.Args[1] {
+		t.Errorf("value mismatch, expected %d bytes got %d", len(c.Args[1]), len(r.Args[1]))
+	}
+}
+
+func TestReadSyncFramedRequestTooLarge(t *testing.T) {
+	server, client := newConnPair(t)
+	h := NewIOHandlerWithConn(server)
+	h.EnableFraming()
+
+	b := protowire.AppendVarint(nil, uint64(config.MaxRequestSize+1))
+	if _, err := client.Write(b); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	if _, err := h.ReadSync(); !errors.Is(err, ErrRequestTooLarge) {
+		t.Errorf("expected %v, got %v", ErrRequestTooLarge, err)
+	}
+}
+
+func TestReadSyncUnframedRequestTooLarge(t *testing.T) {
+	// A pipe fills every read for the request never to be cut short by
+	// a short read, and the request is rejected before it is buffered in full.
+	server, client := net.Pipe()
+	defer client.Close()
+	h := NewIOHandlerWithConn(server)
+	go func() {
+		_, _ = client.Write(make([]byte, 2*config.MaxRequestSize))
+	}()
+
+	if _, err := h.ReadSync(); !errors.Is(err, ErrRequestTooLarge) {
+		t.Errorf("expected %v, got %v", ErrRequestTooLarge, err)
+	}
+	if h.buffered() > maxBuffered+config.IoBufferSize {
+		t.Errorf("expected at most %d bytes buffered, got %d", maxBuffered+config.IoBufferSize, h.buffered())
+	}
+}
+
+func TestReadSyncFramedEOF(t *testing.T) {
+	server, client := newConnPair(t)
+	h := NewIOHandlerWithConn(server)
+	h.EnableFraming()
+
+	client.Close()
+	if _, err := h.ReadSync(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestWriteFramedResponses(t *testing.T) {
+	server, client := newConnPair(t)
+	h := NewIOHandlerWithConn(server)
+	h.EnableFraming()
+
+	for i := 0; i < 3; i++ {
+		r := &wire.Response{Value: &wire.Response_VInt{VInt: int64(i)}}
+		if err := h.WriteBuffered(r); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}
+	if err := h.Flush(); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+
+	reader := bufio.NewReader(client)
+	for i := 0; i < 3; i++ {
+		r := &wire.Response{}
+		if err := protodelim.UnmarshalFrom(reader, r); err != nil {
+			t.Fatalf("failed to read response %d: %v", i, err)
+		}
+		if r.GetVInt() != int64(i) {
+			t.Errorf("expected %d, got %d", i, r.GetVInt())
+		}
+	}
+}
+
+// serveEcho mimics the io-thread loop by responding to every command
+// with its first argument and flushing only when nothing is pending.
+func serveEcho(h *IOHandler) {
+	for {
+		c, err := h.ReadSync()
+		if err != nil {
+			return
+		}
+		r := &wire.Response{Value: &wire.Response_VStr{VStr: c.Args[0]}}
+		if err := h.WriteBuffered(r); err != nil {
+			return
+		}
+		if !h.HasPending() {
+			if err := h.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func benchmarkFramed(b *testing.B, depth int) {
+	server, client := newConnPair(b)
+	h := NewIOHandlerWithConn(server)
+	h.EnableFraming()
+	go serveEcho(h)
+
+	reader := bufio.NewReaderSize(client, config.IoBufferSize)
+	writer := bufio.NewWriterSize(client, config.IoBufferSize)
+	c := &wire.Command{Cmd: "GET", Args: []string{"k"}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i += depth {
+		n := depth
+		if b.N-i < n {
+			n = b.N - i
+		}
+		for j := 0; j < n; j++ {
+			c.Args[0] = strconv.Itoa(i + j)
+			if _, err := protodelim.MarshalTo(writer, c); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			b.Fatal(err)
+		}
+		for j := 0; j < n; j++ {
+			r := &wire.Response{}
+			if err := protodelim.UnmarshalFrom(reader, r); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkFramedSequential sends one command at a time and waits for its response.
+func BenchmarkFramedSequential(b *testing.B) {
+	benchmarkFramed(b, 1)
+}
+
+// BenchmarkFramedPipelined sends commands in batches of 100 before reading the responses.
+func BenchmarkFramedPipelined(b *testing.B) {
+	benchmarkFramed(b, 100)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"strings"
+
+	"github.com/dicedb/dice/internal/regex"
+)
+
+// patternIndex indexes the glob patterns of the KEYS.WATCH subscriptions by
+// their literal prefix, the part before the first wildcard, in a trie.
+// Matching a key walks the trie along the key, hence only the patterns whose
+// literal prefix is a prefix of the key are matched against it.
+type patternIndex struct {
+	root *patternNode
+	size int
+}
+
+type patternNode struct {
+	children map[byte]*patternNode
+	// fps holds the fingerprints of the subscriptions whose
+	// pattern has the path to the node as its literal prefix.
+	fps map[uint32]string
+}
+
+func newPatternIndex() *patternIndex {
+	return &patternIndex{root: &patternNode{}}
+}
+
+// literalPrefix returns the part of the pattern before its first wildcard.
+func literalPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, "*?"); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
+
+func (p *patternIndex) add(pattern string, fp uint32) {
+	n := p.root
+	prefix := literalPrefix(pattern)
+	for i := 0; i < len(prefix); i++ {
+		if n.children == nil {
+			n.children = make(map[byte]*patternNode)
+		}
+		child, ok := n.children[prefix[i]]
+		if !ok {
+			child = &patternNode{}
+			n.children[prefix[i]] = child
+		}
+		n = child
+	}
+
+	if n.fps == nil {
+		n.fps = make(map[uint32]string)
+	}
+	if _, ok := n.fps[fp]; !ok {
+		p.size++
+	}
+	n.fps[fp] = pattern
+}
+
+// remove removes the subscription from the index along with
+// the nodes of the trie no longer leading to any pattern.
+func (p *patternIndex) remove(pattern string, fp uint32) {
+	prefix := literalPrefix(pattern)
+	path := make([]*patternNode, 0, len(prefix)+1)
+	n := p.root
+	for i := 0; i < len(prefix); i++ {
+		path = append(path, n)
+		if n = n.children[prefix[i]]; n == nil {
+			return
+		}
+	}
+
+	if _, ok := n.fps[fp]; !ok {
+		return
+	}
+	delete(n.fps, fp)
+	p.size--
+
+	for i := len(path) - 1; i >= 0; i-- {
+		if len(n.fps) > 0 || len(n.children) > 0 {
+			return
+		}
+		delete(path[i].children, prefix[i])
+		n = path[i]
+	}
+}
+
+// match calls f with the fingerprint of every subscription matching the key.
+func (p *patternIndex) match(key string, f func(fp uint32)) {
+	n := p.root
+	for i := 0; ; i++ {
+		for fp, pattern := range n.fps {
+			if regex.WildCardMatch(pattern, key) {
+				f(fp)
+			}
+		}
+		if i == len(key) {
+			return
+		}
+		if n = n.children[key[i]]; n == nil {
+			return
+		}
+	}
+}
+
+// len returns the number of subscriptions in the index.
+func (p *patternIndex) len() int {
+	return p.size
+}
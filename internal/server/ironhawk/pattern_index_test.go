@@ -0,0 +1,84 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func matches(p *patternIndex, key string) []uint32 {
+	var fps []uint32
+	p.match(key, func(fp uint32) { fps = append(fps, fp) })
+	slices.Sort(fps)
+	return fps
+}
+
+func TestPatternIndexMatch(t *testing.T) {
+	p := newPatternIndex()
+	p.add("user:*:profile", 1)
+	p.add("user:1*", 2)
+	p.add("*", 3)
+	p.add("user:1:profile", 4)
+	p.add("us?r:*", 5)
+
+	tests := []struct {
+		key      string
+		expected []uint32
+	}{
+		{"user:1:profile", []uint32{1, 2, 3, 4, 5}},
+		{"user:2:profile", []uint32{1, 3, 5}},
+		{"user:10", []uint32{2, 3, 5}},
+		{"usxr:1", []uint32{3, 5}},
+		{"order:1", []uint32{3}},
+		{"", []uint32{3}},
+	}
+	for _, tc := range tests {
+		if got := matches(p, tc.key); !slices.Equal(got, tc.expected) {
+			t.Errorf("match(%q) = %v, expected %v", tc.key, got, tc.expected)
+		}
+	}
+}
+
+func TestPatternIndexRemove(t *testing.T) {
+	p := newPatternIndex()
+	p.add("user:*:profile", 1)
+	p.add("user:*", 2)
+	p.add("order:*", 3)
+	p.add("*", 4)
+
+	p.remove("user:*:profile", 1)
+	if got := matches(p, "user:1:profile"); !slices.Equal(got, []uint32{2, 4}) {
+		t.Errorf("expected [2 4], got %v", got)
+	}
+
+	// Removing an unknown subscription is a no-op.
+	p.remove("user:*", 1)
+	p.remove("unknown:*", 2)
+	if p.len() != 3 {
+		t.Errorf("expected 3 subscriptions, got %d", p.len())
+	}
+
+	p.remove("user:*", 2)
+	p.remove("order:*", 3)
+	p.remove("*", 4)
+	if p.len() != 0 || len(p.root.children) != 0 || len(p.root.fps) != 0 {
+		t.Errorf("expected an empty index, got %d subscriptions and %d children", p.len(), len(p.root.children))
+	}
+}
+
+// BenchmarkPatternIndexMatch matches a key against an index of patterns
+// of which only a few share the literal prefix of the key.
+func BenchmarkPatternIndexMatch(b *testing.B) {
+	p := newPatternIndex()
+	for i := 0; i < 100000; i++ {
+		p.add(fmt.Sprintf("tenant:%d:user:*:profile", i), uint32(i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.match("tenant:42:user:7:profile", func(uint32) {})
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/regex"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// pubSub holds the pub/sub subscriptions of the clients. The messages are
+// not stored, they are queued for the watch connections of the subscribers
+// when published and dropped if a subscriber has no watch connection.
+type pubSub struct {
+	channels       map[string]map[string]bool
+	clientChannels map[string]map[string]bool
+
+	// The patterns are indexed by an ID of their own in
+	// a pattern index, the way the KEYS.WATCH patterns are.
+	patterns       *patternIndex
+	patternIDs     map[string]uint32
+	idPatterns     map[uint32]string
+	nextPatternID  uint32
+	patternClients map[string]map[string]bool
+	clientPatterns map[string]map[string]bool
+}
+
+func newPubSub() *pubSub {
+	return &pubSub{
+		channels:       map[string]map[string]bool{},
+		clientChannels: map[string]map[string]bool{},
+		patterns:       newPatternIndex(),
+		patternIDs:     map[string]uint32{},
+		idPatterns:     map[uint32]string{},
+		patternClients: map[string]map[string]bool{},
+		clientPatterns: map[string]map[string]bool{},
+	}
+}
+
+// indexAdd adds the entry to the index and to its reverse index,
+// and returns true if the entry is the first one of the name.
+func indexAdd(index, reverse map[string]map[string]bool, name, clientID string) bool {
+	first := len(index[name]) == 0
+	if index[name] == nil {
+		index[name] = map[string]bool{}
+	}
+	index[name][clientID] = true
+	if reverse[clientID] == nil {
+		reverse[clientID] = map[string]bool{}
+	}
+	reverse[clientID][name] = true
+	return first
+}
+
+// indexRemove removes the entry from the index and from its reverse
+// index, and returns true if the entry was the last one of the name.
+func indexRemove(index, reverse map[string]map[string]bool, name, clientID string) bool {
+	if !index[name][clientID] {
+		return false
+	}
+	delete(index[name], clientID)
+	delete(reverse[clientID], name)
+	if len(reverse[clientID]) == 0 {
+		delete(reverse, clientID)
+	}
+	if len(index[name]) > 0 {
+		return false
+	}
+	delete(index, name)
+	return true
+}
+
+func (p *pubSub) subscribe(clientID string, channels []string) {
+	for _, ch := range channels {
+		indexAdd(p.channels, p.clientChannels, ch, clientID)
+	}
+}
+
+// unsubscribe unsubscribes the client from the channels, or from all of them if none is given.
+func (p *pubSub) unsubscribe(clientID string, channels []string) {
+	if len(channels) == 0 {
+		channels = slices.Collect(maps.Keys(p.clientChannels[clientID]))
+	}
+	for _, ch := range channels {
+		indexRemove(p.channels, p.clientChannels, ch, clientID)
+	}
+}
+
+func (p *pubSub) psubscribe(clientID string, patterns []string) {
+	for _, pattern := range patterns {
+		if indexAdd(p.patternClients, p.clientPatterns, pattern, clientID) {
+			p.nextPatternID++
+			p.patternIDs[pattern] = p.nextPatternID
+			p.idPatterns[p.nextPatternID] = pattern
+			p.patterns.add(pattern, p.nextPatternID)
+		}
+	}
+}
+
+// punsubscribe unsubscribes the client from the patterns, or from all of them if none is given.
+func (p *pubSub) punsubscribe(clientID string, patterns []string) {
+	if len(patterns) == 0 {
+		patterns = slices.Collect(maps.Keys(p.clientPatterns[clientID]))
+	}
+	for _, pattern := range patterns {
+		if indexRemove(p.patternClients, p.clientPatterns, pattern, clientID) {
+			id := p.patternIDs[pattern]
+			p.patterns.remove(pattern, id)
+			delete(p.patternIDs, pattern)
+			delete(p.idPatterns, id)
+		}
+	}
+}
+
+// count returns the number of channels and patterns the client is subscribed to.
+func (p *pubSub) count(clientID string) int {
+	return len(p.clientChannels[clientID]) + len(p.clientPatterns[clientID])
+}
+
+// publish calls f with the ID of every client subscribed to the channel, along
+// with the pattern it matched, empty for the subscribers to the channel itself.
+func (p *pubSub) publish(channel string, f func(clientID, pattern string)) {
+	for clientID := range p.channels[channel] {
+		f(clientID, "")
+	}
+	p.patterns.match(channel, func(id uint32) {
+		pattern := p.idPatterns[id]
+		for clientID := range p.patternClients[pattern] {
+			f(clientID, pattern)
+		}
+	})
+}
+
+// HandlePubSub executes the pub/sub command for the client of the thread
+// and returns its response, or nil if the command is not a pub/sub command.
+func (w *WatchManager) HandlePubSub(c *cmd.Cmd, t *IOThread) *wire.Response {
+	args := c.C.Args
+	switch c.C.Cmd {
+	case "PUBLISH":
+		return &wire.Response{Value: &wire.Response_VInt{VInt: int64(w.publish(args[0], args[1]))}}
+	case "PUBSUB":
+		return w.pubSubInfo(args)
+	case "SUBSCRIBE", "UNSUBSCRIBE", "PSUBSCRIBE", "PUNSUBSCRIBE":
+		return w.pubSubscribe(c.C.Cmd, t.ClientID, args)
+	}
+	return nil
+}
+
+// pubSubscribe subscribes the client to the channels or the patterns, or
+// unsubscribes it from them, and returns the number it is subscribed to.
+func (w *WatchManager) pubSubscribe(command, clientID string, args []string) *wire.Response {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch command {
+	case "SUBSCRIBE":
+		w.pubsub.subscribe(clientID, args)
+	case "UNSUBSCRIBE":
+		w.pubsub.unsubscribe(clientID, args)
+	case "PSUBSCRIBE":
+		w.pubsub.psubscribe(clientID, args)
+	case "PUNSUBSCRIBE":
+		w.pubsub.punsubscribe(clientID, args)
+	}
+	return &wire.Response{Value: &wire.Response_VInt{VInt: int64(w.pubsub.count(clientID))}}
+}
+
+// publish queues the message for the watch connections of the
+// subscribers and returns the number of clients it was queued for.
+func (w *WatchManager) publish(channel, message string) int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	clients := map[string]bool{}
+	w.pubsub.publish(channel, func(clientID, pattern string) {
+		q := w.clientQueueMap[clientID]
+		if q == nil {
+			return
+		}
+		fields := map[string]*structpb.Value{"channel": structpb.NewStringValue(channel)}
+		if pattern != "" {
+			fields["pattern"] = structpb.NewStringValue(pattern)
+		}
+		q.push(0, &wire.Response{
+			Value: &wire.Response_VStr{VStr: message},
+			Attrs: &structpb.Struct{Fields: fields},
+		})
+		clients[clientID] = true
+	})
+	return len(clients)
+}
+
+// pubSubInfo returns the response to the PUBSUB command.
+func (w *WatchManager) pubSubInfo(args []string) *wire.Response {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if strings.ToUpper(args[0]) == cmd.NUMSUB {
+		counts := map[string]string{}
+		for _, ch := range args[1:] {
+			counts[ch] = strconv.Itoa(len(w.pubsub.channels[ch]))
+		}
+		return &wire.Response{VSsMap: counts}
+	}
+
+	channels := slices.Sorted(maps.Keys(w.pubsub.channels))
+	list := make([]*structpb.Value, 0, len(channels))
+	for _, ch := range channels {
+		if len(args) == 1 || regex.WildCardMatch(args[1], ch) {
+			list = append(list, structpb.NewStringValue(ch))
+		}
+	}
+	return &wire.Response{VList: list}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+func firePubSub(t *testing.T, wm *WatchManager, thread *IOThread, c string, args ...string) *wire.Response {
+	t.Helper()
+	r := wm.HandlePubSub(&cmd.Cmd{C: &wire.Command{Cmd: c, Args: args}, ClientID: thread.ClientID}, thread)
+	if r == nil {
+		t.Fatalf("expected %s to be handled", c)
+	}
+	return r
+}
+
+func TestPubSubPublish(t *testing.T) {
+	wm, _, thread, conn := startWatchManager(t)
+	wm.RegisterThread(thread)
+
+	if n := firePubSub(t, wm, thread, "SUBSCRIBE", "chat", "news").GetVInt(); n != 2 {
+		t.Fatalf("expected 2 subscriptions, got %d", n)
+	}
+	if n := firePubSub(t, wm, thread, "PSUBSCRIBE", "ch*").GetVInt(); n != 3 {
+		t.Fatalf("expected 3 subscriptions, got %d", n)
+	}
+
+	// The client gets the message once for every subscription matching it.
+	if n := firePubSub(t, wm, thread, "PUBLISH", "chat", "hello").GetVInt(); n != 1 {
+		t.Fatalf("expected the message to be sent to 1 client, got %d", n)
+	}
+	patterns := map[string]bool{}
+	for range 2 {
+		r := readUpdate(t, conn)
+		fields := r.GetAttrs().GetFields()
+		if r.GetVStr() != "hello" || fields["channel"].GetStringValue() != "chat" {
+			t.Fatalf("expected the message hello on chat, got %v", r)
+		}
+		patterns[fields["pattern"].GetStringValue()] = true
+	}
+	if !patterns[""] || !patterns["ch*"] {
+		t.Fatalf("expected the message through the channel and the pattern, got %v", patterns)
+	}
+
+	if n := firePubSub(t, wm, thread, "PUBLISH", "other", "hello").GetVInt(); n != 0 {
+		t.Fatalf("expected no subscribers to other, got %d", n)
+	}
+}
+
+func TestPubSubUnsubscribe(t *testing.T) {
+	wm, _, thread, _ := startWatchManager(t)
+	wm.RegisterThread(thread)
+
+	firePubSub(t, wm, thread, "SUBSCRIBE", "chat", "news")
+	firePubSub(t, wm, thread, "PSUBSCRIBE", "ch*", "n*")
+
+	channels := firePubSub(t, wm, thread, "PUBSUB", "CHANNELS")
+	if len(channels.VList) != 2 || channels.VList[0].GetStringValue() != "chat" {
+		t.Fatalf("expected the channels chat and news, got %v", channels.VList)
+	}
+	if counts := firePubSub(t, wm, thread, "PUBSUB", "NUMSUB", "chat", "other").VSsMap; counts["chat"] != "1" || counts["other"] != "0" {
+		t.Fatalf("expected 1 subscriber to chat and none to other, got %v", counts)
+	}
+
+	if n := firePubSub(t, wm, thread, "UNSUBSCRIBE", "chat").GetVInt(); n != 3 {
+		t.Fatalf("expected 3 subscriptions left, got %d", n)
+	}
+	if n := firePubSub(t, wm, thread, "PUNSUBSCRIBE").GetVInt(); n != 1 {
+		t.Fatalf("expected 1 subscription left, got %d", n)
+	}
+	if n := firePubSub(t, wm, thread, "PUBLISH", "chat", "hello").GetVInt(); n != 0 {
+		t.Fatalf("expected no subscribers to chat, got %d", n)
+	}
+
+	// The subscriptions are removed along with the watch connection.
+	wm.CleanupThreadWatchSubscriptions(thread)
+	if len(wm.pubsub.channels) != 0 || len(wm.pubsub.clientChannels) != 0 || wm.pubsub.patterns.len() != 0 ||
+		len(wm.pubsub.patternIDs) != 0 || len(wm.pubsub.clientPatterns) != 0 {
+		t.Fatalf("expected no pub/sub state left, got %+v", wm.pubsub)
+	}
+}
+
+func TestPubSubOtherCommands(t *testing.T) {
+	wm, _, thread, _ := startWatchManager(t)
+
+	// The other commands are left to the caller, without
+	// waiting for the lock of the watch manager.
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	done := make(chan *wire.Response, 1)
+	go func() {
+		done <- wm.HandlePubSub(&cmd.Cmd{C: &wire.Command{Cmd: "GET", Args: []string{"k1"}}}, thread)
+	}()
+	select {
+	case r := <-done:
+		if r != nil {
+			t.Fatalf("expected GET not to be handled, got %v", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected GET not to wait for the watch manager")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+)
+
+// setConfig sets the config value for the duration of the test.
+func setConfig(t *testing.T, v *int, value int) {
+	t.Helper()
+	prev := *v
+	*v = value
+	t.Cleanup(func() { *v = prev })
+}
+
+func dialHandshake(t *testing.T, addr, mode string) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	b, _ := proto.Marshal(&wire.Command{Cmd: "HANDSHAKE", Args: []string{"c1", mode}})
+	if _, err := conn.Write(b); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	if r := readUnframed(t, conn); r.GetVStr() != "OK" {
+		t.Fatalf("handshake failed: %v", r)
+	}
+	return conn
+}
+
+func fire(t *testing.T, conn net.Conn, c *wire.Command) *wire.Response {
+	t.Helper()
+	b, _ := proto.Marshal(c)
+	if _, err := conn.Write(b); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	return readUnframed(t, conn)
+}
+
+func readUnframed(t *testing.T, conn net.Conn) *wire.Response {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	buf := make([]byte, 512)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	r := &wire.Response{}
+	if err := proto.Unmarshal(buf[:n], r); err != nil {
+		t.Fatalf("failed to unmarshal the response: %v", err)
+	}
+	return r
+}
+
+func TestIdleConnectionClosed(t *testing.T) {
+	setConfig(t, &config.Config.IdleTimeoutSec, 1)
+	addr, _ := startEventLoopServer(t)
+
+	idle := dialHandshake(t, addr, "command")
+	noEvict := dialHandshake(t, addr, "command")
+	if r := fire(t, noEvict, &wire.Command{Cmd: "CLIENT", Args: []string{"NO-EVICT", "ON"}}); r.GetVStr() != "OK" {
+		t.Fatalf("expected OK, got %v", r)
+	}
+
+	_ = idle.SetReadDeadline(time.Now().Add(3 * time.Second))
+	if _, err := idle.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("expected the idle connection to be closed, got %v", err)
+	}
+
+	if r := fire(t, noEvict, &wire.Command{Cmd: "PING"}); r.GetVStr() != "PONG" {
+		t.Fatalf("expected PONG on the NO-EVICT connection, got %v", r)
+	}
+}
+
+func TestWatchConnectionPinged(t *testing.T) {
+	setConfig(t, &config.Config.IdleTimeoutSec, 1)
+	setConfig(t, &config.Config.WatchPingIntervalSec, 1)
+	addr, _ := startEventLoopServer(t)
+
+	watch := dialHandshake(t, addr, "watch")
+	for i := 0; i < 2; i++ {
+		r := readUnframed(t, watch)
+		if r.GetVStr() != "PING" || r.GetAttrs().GetFields()["type"].GetStringValue() != "ping" {
+			t.Fatalf("expected a PING of type ping, got %v", r)
+		}
+	}
+}
+
+func TestSlowWatchReaderDisconnected(t *testing.T) {
+	setConfig(t, &config.Config.WriteTimeoutMillis, 100)
+	addr, active := startEventLoopServer(t)
+
+	// The watch connection subscribes to the key and never reads the updates.
+	watch, err := net.DialTCP("tcp", nil, mustResolve(t, addr))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer watch.Close()
+	_ = watch.SetReadBuffer(4096)
+	handshakeFramed(t, watch)
+	b := framedBytes(t,
+		&wire.Command{Cmd: "HANDSHAKE", Args: []string{"c1", "watch"}},
+		&wire.Command{Cmd: "GET.WATCH", Args: []string{"k"}})
+	if _, err := watch.Write(b); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	handshakeFramed(t, conn)
+	r := bufio.NewReader(conn)
+
+	value := strings.Repeat("v", 1<<20)
+	for i := 0; i < 10; i++ {
+		if _, err := conn.Write(framedBytes(t, &wire.Command{Cmd: "SET", Args: []string{"k", value}})); err != nil {
+			t.Fatalf("failed to write: %v", err)
+		}
+		start := time.Now()
+		_ = conn.SetReadDeadline(start.Add(3 * time.Second))
+		res := &wire.Response{}
+		if err := protodelim.UnmarshalFrom(r, res); err != nil || res.GetVStr() != "OK" {
+			t.Fatalf("expected OK, got %v %v", res, err)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Fatalf("SET took %v, the slow watch reader is blocking the writers", d)
+		}
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for active() > 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the slow watch reader to be disconnected, %d connections active", active())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func mustResolve(t *testing.T, addr string) *net.TCPAddr {
+	t.Helper()
+	a, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to resolve %s: %v", addr, err)
+	}
+	return a
+}
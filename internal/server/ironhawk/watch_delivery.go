@@ -0,0 +1,115 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/proto"
+)
+
+// watchDelivery coalesces the updates of a subscription as per its delivery
+// options so that the command is re-executed at most once per update sent,
+// however often the keys it reads are modified.
+type watchDelivery struct {
+	opts cmd.WatchOptions
+
+	// lastRun is when the command was last re-executed for an update.
+	lastRun time.Time
+	// primed is set once the state below reflects an output of the command.
+	primed bool
+	// last is the output of the last update sent, for ONLYCHANGED.
+	last []byte
+	// matched is set if the last output satisfied the WHERE predicate.
+	matched bool
+
+	// due is when the pending update is to be sent,
+	// timer, if not nil, fires at or before then.
+	due   time.Time
+	timer *time.Timer
+}
+
+func newWatchDelivery(opts cmd.WatchOptions) *watchDelivery {
+	return &watchDelivery{opts: opts}
+}
+
+// next returns when the update for a modification made now is due.
+func (d *watchDelivery) next(now time.Time) time.Time {
+	due := now.Add(d.opts.Debounce)
+	if t := d.lastRun.Add(d.opts.Throttle); t.After(due) {
+		due = t
+	}
+	return due
+}
+
+// admit returns true if the update is to be sent as per the WHERE predicate
+// and ONLYCHANGED, and records its output.
+func (d *watchDelivery) admit(r *wire.Response) bool {
+	d.primed = true
+	if p := d.opts.Where; p != nil {
+		matched, was := p.Eval(r), d.matched
+		d.matched = matched
+		if !matched || (was && !d.opts.Level) {
+			return false
+		}
+	}
+	return d.changed(r)
+}
+
+// observe records the output sent to a new subscriber, unless the
+// state already reflects the updates sent to the other subscribers.
+func (d *watchDelivery) observe(r *wire.Response) {
+	if d.primed {
+		return
+	}
+	d.primed = true
+	if p := d.opts.Where; p != nil {
+		d.matched = p.Eval(r)
+	}
+	d.changed(r)
+}
+
+// changed returns false if the output is the same as the one of the
+// last update sent with ONLYCHANGED set. Otherwise it records the output.
+func (d *watchDelivery) changed(r *wire.Response) bool {
+	if !d.opts.OnlyChanged {
+		return true
+	}
+
+	// The attributes, e.g. the sequence number, are not part of the output.
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(&wire.Response{
+		Err:    r.Err,
+		Value:  r.Value,
+		VList:  r.VList,
+		VSsMap: r.VSsMap,
+	})
+	if err != nil {
+		return true
+	}
+	if d.last != nil && bytes.Equal(b, d.last) {
+		return false
+	}
+	if b == nil {
+		b = []byte{}
+	}
+	d.last = b
+	return true
+}
+
+// reset forgets the outputs recorded so that the state reflects the
+// output sent to the next subscriber rather than the updates sent before.
+func (d *watchDelivery) reset() {
+	d.primed, d.last, d.matched = false, nil, false
+}
+
+// stop cancels the pending update, if any.
+func (d *watchDelivery) stop() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dicedb/dice/internal/shardmanager"
+)
+
+// watchDispatcher holds the subscriptions whose command is to be re-executed
+// for an update, along with the keys whose value is to be sent to the
+// KEYS.WATCH subscriptions matching them, which the watch manager handles on
+// a goroutine of its own, neither holding up the writers notifying it nor
+// holding its lock while reading the stores.
+//
+// A subscription is held at most once until it is re-executed, the output
+// of the command covering all the modifications notified until then, hence
+// the dispatcher holds no more than one entry per subscription and client.
+// Likewise, a key is held at most once, along with the last operation
+// modifying it, its value being read once it is dispatched.
+type watchDispatcher struct {
+	mu      sync.Mutex
+	pending []watchDispatch
+	queued  map[watchDispatch]bool
+	// queuedKeys holds the position of the queued keys in pending.
+	queuedKeys map[string]int
+	// ready is signaled when a subscription or a key is queued.
+	ready chan struct{}
+}
+
+// watchDispatch is the re-execution of the command of a subscription, or
+// the notification of the KEYS.WATCH subscriptions matching a key.
+type watchDispatch struct {
+	fp uint32
+	// clientID is the only client the output is sent to, as the initial
+	// output of a new subscriber, empty for all the clients.
+	clientID string
+	// refresh marks the output sent to the client resuming the subscription
+	// from updates no longer retained, see WatchManager.Resume.
+	refresh bool
+
+	// keyEvent marks the notification of the KEYS.WATCH subscriptions
+	// matching the key, modified by the operation.
+	keyEvent  bool
+	key       string
+	operation string
+}
+
+func newWatchDispatcher() *watchDispatcher {
+	return &watchDispatcher{
+		queued:     make(map[watchDispatch]bool),
+		queuedKeys: make(map[string]int),
+		ready:      make(chan struct{}, 1),
+	}
+}
+
+// push queues the re-execution of the subscription, unless already queued.
+func (d *watchDispatcher) push(fp uint32, clientID string) {
+	d.pushDispatch(watchDispatch{fp: fp, clientID: clientID})
+}
+
+// pushRefresh queues the re-execution of the subscription for its output
+// to refresh the client resuming it, unless already queued.
+func (d *watchDispatcher) pushRefresh(fp uint32, clientID string) {
+	d.pushDispatch(watchDispatch{fp: fp, clientID: clientID, refresh: true})
+}
+
+func (d *watchDispatcher) pushDispatch(e watchDispatch) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.queued[e] {
+		return
+	}
+	d.queued[e] = true
+	d.pending = append(d.pending, e)
+	d.signal()
+}
+
+// pushKey queues the notification of the KEYS.WATCH subscriptions matching
+// the key modified by the operation. A key already queued takes the
+// operation, which is the one its value is left by.
+func (d *watchDispatcher) pushKey(key, operation string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if i, ok := d.queuedKeys[key]; ok {
+		d.pending[i].operation = operation
+		return
+	}
+	d.queuedKeys[key] = len(d.pending)
+	d.pending = append(d.pending, watchDispatch{keyEvent: true, key: key, operation: operation})
+	d.signal()
+}
+
+func (d *watchDispatcher) signal() {
+	select {
+	case d.ready <- struct{}{}:
+	default:
+	}
+}
+
+// take returns the queued re-executions in the order they were queued.
+func (d *watchDispatcher) take() []watchDispatch {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	pending := d.pending
+	d.pending = nil
+	clear(d.queued)
+	clear(d.queuedKeys)
+	return pending
+}
+
+// dispatch re-executes the queued subscriptions, and notifies the KEYS.WATCH
+// subscriptions of the queued keys, until the context is canceled.
+func (w *WatchManager) dispatch(ctx context.Context, shardManager *shardmanager.ShardManager) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.dispatcher.ready:
+		}
+
+		for _, e := range w.dispatcher.take() {
+			w.deliver(e, shardManager)
+		}
+	}
+}
+
+// deliver handles the queued re-execution or key notification.
+func (w *WatchManager) deliver(e watchDispatch, shardManager *shardmanager.ShardManager) {
+	if e.keyEvent {
+		w.deliverKeyUpdate(e.key, e.operation, shardManager)
+		return
+	}
+	w.deliverUpdate(e, shardManager)
+}
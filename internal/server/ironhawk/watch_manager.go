@@ -6,30 +6,157 @@ package ironhawk
 import (
 	"context"
 	"log/slog"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type WatchManager struct {
-	mu                   sync.RWMutex
-	clientWatchThreadMap map[string]*IOThread
+	mu sync.RWMutex
+
+	// clientQueueMap holds the outbound queue of the watch connection of every client.
+	clientQueueMap map[string]*watchQueue
+	queueStats     watchQueueStats
 
 	keyFPMap    map[string]map[uint32]bool
 	fpClientMap map[uint32]map[string]bool
 	fpCmdMap    map[uint32]*cmd.Cmd
+
+	// fpKeyMap and clientFPMap are the reverse indexes of keyFPMap and
+	// fpClientMap, so that removing the subscriptions of a client, or a
+	// subscription, costs only as much as the entries it owns.
+	fpKeyMap    map[uint32][]string
+	clientFPMap map[string]map[uint32]bool
+
+	// fpOrphans holds the timers removing the subscriptions left without
+	// clients by disconnects, once the clients had the time to resume them.
+	fpOrphans map[uint32]*time.Timer
+
+	// fpStreams numbers the updates of every subscription
+	// and retains the recent ones for the resuming clients.
+	fpStreams map[uint32]*watchStream
+
+	// fpDeliveries holds the state of the subscriptions with delivery options.
+	fpDeliveries map[uint32]*watchDelivery
+
+	// dispatcher holds the subscriptions to re-execute for an update, and
+	// the keys to send to the KEYS.WATCH subscriptions matching them.
+	dispatcher *watchDispatcher
+
+	// patterns indexes the KEYS.WATCH subscriptions by their pattern.
+	patterns *patternIndex
+
+	// keyspaceEvents are the events sent to the KEYS.WATCH subscribers.
+	keyspaceEvents map[string]bool
+
+	// pubsub holds the SUBSCRIBE and PSUBSCRIBE subscriptions.
+	pubsub *pubSub
+
+	// cdc streams the WAL to the CDC.SUBSCRIBE subscribers, nil if the WAL is disabled.
+	cdc *cdcHub
 }
 
 func NewWatchManager() *WatchManager {
 	return &WatchManager{
-		clientWatchThreadMap: map[string]*IOThread{},
+		clientQueueMap: map[string]*watchQueue{},
 
 		keyFPMap:    map[string]map[uint32]bool{},
 		fpClientMap: map[uint32]map[string]bool{},
 		fpCmdMap:    map[uint32]*cmd.Cmd{},
+		fpStreams:   map[uint32]*watchStream{},
+
+		fpKeyMap:    map[uint32][]string{},
+		clientFPMap: map[string]map[uint32]bool{},
+		fpOrphans:   map[uint32]*time.Timer{},
+
+		fpDeliveries: map[uint32]*watchDelivery{},
+		dispatcher:   newWatchDispatcher(),
+
+		patterns:       newPatternIndex(),
+		keyspaceEvents: parseKeyspaceEvents(config.Config.KeyspaceEvents),
+
+		pubsub: newPubSub(),
+	}
+}
+
+// The keyspace events, as named in the keyspace-events config.
+const (
+	keyspaceEventSet     = "set"
+	keyspaceEventDel     = "del"
+	keyspaceEventExpired = "expired"
+	keyspaceEventEvicted = "evicted"
+)
+
+func parseKeyspaceEvents(s string) map[string]bool {
+	events := make(map[string]bool)
+	for _, e := range strings.Split(s, ",") {
+		switch e = strings.ToLower(strings.TrimSpace(e)); e {
+		case "":
+		case keyspaceEventSet, keyspaceEventDel, keyspaceEventExpired, keyspaceEventEvicted:
+			events[e] = true
+		default:
+			slog.Warn("ignoring unknown keyspace event", slog.String("event", e))
+		}
+	}
+	return events
+}
+
+// keyspaceEvent returns the keyspace event of the
+// operation that left the key with the value.
+func keyspaceEvent(operation string, value *wire.Response) string {
+	switch operation {
+	case dstore.Expired:
+		return keyspaceEventExpired
+	case dstore.Evict:
+		return keyspaceEventEvicted
+	}
+	if value.GetVNil() {
+		return keyspaceEventDel
+	}
+	return keyspaceEventSet
+}
+
+// Run notifies the watchers of the changes the stores make on their own,
+// i.e. the expiry and eviction of keys, and re-executes the subscriptions
+// notified of a change, until the context is canceled. The changes made by
+// the commands are notified by NotifyWatchers.
+//
+// The stores coalesce their changes until they are taken, and are sent to
+// the channel once they have changes pending, hence they are never held up
+// by the watchers, nor once Run has returned.
+func (w *WatchManager) Run(ctx context.Context, events <-chan *dstore.Store, shardManager *shardmanager.ShardManager) {
+	go w.dispatch(ctx, shardManager)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s := <-events:
+			w.notifyStoreEvents(s.TakeWatchEvents())
+		}
+	}
+}
+
+// notifyStoreEvents notifies the watchers of the keys changed by a store,
+// along with the operation that changed them.
+func (w *WatchManager) notifyStoreEvents(events map[string]string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for key, operation := range events {
+		keys := []string{key}
+		w.notifyPatternWatchers(operation, keys)
+		w.notifyKeyWatchers(keys, "")
 	}
 }
 
@@ -37,41 +164,170 @@ func (w *WatchManager) RegisterThread(t *IOThread) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	if t.Mode == "watch" {
-		w.clientWatchThreadMap[t.ClientID] = t
+		w.register(t)
+	}
+}
+
+// register returns the outbound queue of the thread, creating it if needed.
+// The queue of the previous connection of the client, if any, is closed.
+func (w *WatchManager) register(t *IOThread) *watchQueue {
+	q := w.clientQueueMap[t.ClientID]
+	if q != nil && q.thread == t {
+		return q
 	}
+	if q != nil {
+		q.close()
+	}
+
+	q = newWatchQueue(t, config.Config.WatchQueueSize, config.Config.WatchQueuePolicy, &w.queueStats)
+	w.clientQueueMap[t.ClientID] = q
+	go q.run()
+	return q
+}
+
+// Ping queues the keep-alive ping of the watch connection of the thread, to
+// be written by the goroutine draining its queue rather than by the caller.
+func (w *WatchManager) Ping(t *IOThread) {
+	w.mu.RLock()
+	q := w.clientQueueMap[t.ClientID]
+	w.mu.RUnlock()
+	if q != nil && q.thread == t {
+		q.ping(watchPing)
+	}
+}
+
+// WatchStats are the metrics of the subscriptions and of the outbound queues
+// of the watch connections.
+type WatchStats struct {
+	// Subscriptions is the number of subscriptions, Orphaned the number of
+	// them left without clients, and Keys and Patterns the number of keys
+	// and KEYS.WATCH patterns they watch.
+	Subscriptions int
+	Orphaned      int
+	Keys          int
+	Patterns      int
+
+	// Clients is the number of watch connections.
+	Clients int
+	// QueueDepth and MaxQueueDepth are the total and the largest
+	// number of updates waiting to be written to the connections.
+	QueueDepth    int
+	MaxQueueDepth int
+	// Dropped is the number of updates dropped as the queues were full,
+	// and Disconnected the number of clients disconnected because of it.
+	Dropped      int64
+	Disconnected int64
+}
+
+// Stats returns the metrics of the subscriptions and of the watch connections.
+func (w *WatchManager) Stats() WatchStats {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	stats := WatchStats{
+		Subscriptions: len(w.fpCmdMap),
+		Orphaned:      len(w.fpOrphans),
+		Keys:          len(w.keyFPMap),
+		Patterns:      w.patterns.len(),
+		Clients:       len(w.clientQueueMap),
+		Dropped:       w.queueStats.dropped.Load(),
+		Disconnected:  w.queueStats.disconnected.Load(),
+	}
+	for _, q := range w.clientQueueMap {
+		n := q.len()
+		stats.QueueDepth += n
+		stats.MaxQueueDepth = max(stats.MaxQueueDepth, n)
+	}
+	return stats
+}
+
+// StatsResponse returns the response to the WATCH.STATS command.
+func (w *WatchManager) StatsResponse() *wire.Response {
+	stats := w.Stats()
+	return &wire.Response{VSsMap: map[string]string{
+		"subscriptions":   strconv.Itoa(stats.Subscriptions),
+		"orphaned":        strconv.Itoa(stats.Orphaned),
+		"keys":            strconv.Itoa(stats.Keys),
+		"patterns":        strconv.Itoa(stats.Patterns),
+		"clients":         strconv.Itoa(stats.Clients),
+		"queue_depth":     strconv.Itoa(stats.QueueDepth),
+		"max_queue_depth": strconv.Itoa(stats.MaxQueueDepth),
+		"dropped":         strconv.FormatInt(stats.Dropped, 10),
+		"disconnected":    strconv.FormatInt(stats.Disconnected, 10),
+	}}
+}
+
+// ListResponse returns the response to the WATCH.LIST command,
+// the subscriptions in the order of their fingerprints.
+func (w *WatchManager) ListResponse() *wire.Response {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	fps := slices.Sorted(maps.Keys(w.fpCmdMap))
+	list := make([]*structpb.Value, 0, len(fps))
+	for _, fp := range fps {
+		clients := slices.Sorted(maps.Keys(w.fpClientMap[fp]))
+		clientValues := make([]*structpb.Value, len(clients))
+		for i, id := range clients {
+			clientValues[i] = structpb.NewStringValue(id)
+		}
+
+		var seq uint64
+		if s := w.fpStreams[fp]; s != nil {
+			seq = s.seq
+		}
+		_, orphaned := w.fpOrphans[fp]
+
+		list = append(list, structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+			"fingerprint": structpb.NewStringValue(strconv.FormatUint(uint64(fp), 10)),
+			"command":     structpb.NewStringValue(w.fpCmdMap[fp].String()),
+			"clients":     structpb.NewListValue(&structpb.ListValue{Values: clientValues}),
+			"seq":         structpb.NewNumberValue(float64(seq)),
+			"orphaned":    structpb.NewBoolValue(orphaned),
+		}}))
+	}
+	return &wire.Response{VList: list}
 }
 
 func (w *WatchManager) HandleWatch(c *cmd.Cmd, t *IOThread) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	fp, key := c.Fingerprint(), c.Key()
+	fp, keys := c.Fingerprint(), c.Keys()
 	slog.Debug("creating a new subscription",
-		slog.String("key", key),
+		slog.Any("keys", keys),
 		slog.String("cmd", c.String()),
 		slog.Any("fingerprint", fp),
 		slog.String("client_id", t.ClientID))
 
-	// For the key that will be watched through any .WATCH command
+	// For every key read by the .WATCH command
 	// Create an entry in the map that holds, key <--> [command fingerprint] as map
-	if _, ok := w.keyFPMap[key]; !ok {
-		w.keyFPMap[key] = make(map[uint32]bool)
-	}
-	w.keyFPMap[key][fp] = true
+	if _, ok := w.fpCmdMap[fp]; !ok {
+		for _, key := range keys {
+			if _, ok := w.keyFPMap[key]; !ok {
+				w.keyFPMap[key] = make(map[uint32]bool)
+			}
+			w.keyFPMap[key][fp] = true
+		}
+		w.fpKeyMap[fp] = keys
 
-	// For the fingerprint
-	// Create an entry in the map that holds, fingerprint <--> [client id] as map
-	// This tells us which clients are subscribed to a particular fingerprint
-	if _, ok := w.fpClientMap[fp]; !ok {
-		w.fpClientMap[fp] = make(map[string]bool)
-	}
-	w.fpClientMap[fp][t.ClientID] = true
+		// KEYS.WATCH subscribes to the keys matching its pattern instead
+		if c.C.Cmd == "KEYS.WATCH" {
+			w.patterns.add(c.C.Args[0], fp)
+		}
+
+		// Store the fingerprint <--> command mapping
+		// so that we understand what should we execute when the data changes
+		w.fpCmdMap[fp] = c
 
-	// Store the fingerprint <--> command mapping
-	// so that we understand what should we execute when the data changes
-	w.fpCmdMap[fp] = c
+		// The subscriptions with delivery options have their updates coalesced
+		if opts, err := c.WatchOptions(); err == nil && opts != (cmd.WatchOptions{}) {
+			w.fpDeliveries[fp] = newWatchDelivery(opts)
+		}
+	}
 
-	w.clientWatchThreadMap[t.ClientID] = t
+	w.subscribe(t.ClientID, fp)
+	w.register(t)
 }
 
 func (w *WatchManager) HandleUnwatch(c *cmd.Cmd, t *IOThread) {
@@ -89,89 +345,409 @@ func (w *WatchManager) HandleUnwatch(c *cmd.Cmd, t *IOThread) {
 	}
 	fp := uint32(_fp)
 
+	// If a fingerprint has no clients subscribed to it, delete the subscription.
+	if w.unsubscribe(t.ClientID, fp) {
+		w.removeSubscription(fp)
+	}
+}
+
+// subscribe subscribes the client to the subscription with the fingerprint.
+func (w *WatchManager) subscribe(clientID string, fp uint32) {
+	// For the fingerprint
+	// Create an entry in the map that holds, fingerprint <--> [client id] as map
+	// This tells us which clients are subscribed to a particular fingerprint
+	if _, ok := w.fpClientMap[fp]; !ok {
+		w.fpClientMap[fp] = make(map[string]bool)
+	}
+	w.fpClientMap[fp][clientID] = true
+
+	if _, ok := w.clientFPMap[clientID]; !ok {
+		w.clientFPMap[clientID] = make(map[uint32]bool)
+	}
+	w.clientFPMap[clientID][fp] = true
+
+	// The subscription is no longer left without clients. The updates
+	// are compared to the output sent to the client rather than to the
+	// ones sent before it was left without clients.
+	if timer, ok := w.fpOrphans[fp]; ok {
+		timer.Stop()
+		delete(w.fpOrphans, fp)
+		if d := w.fpDeliveries[fp]; d != nil {
+			d.reset()
+		}
+	}
+}
+
+// unsubscribe unsubscribes the client from the subscription with the
+// fingerprint and returns true if the subscription is left without clients.
+func (w *WatchManager) unsubscribe(clientID string, fp uint32) bool {
+	if !w.clientFPMap[clientID][fp] {
+		return false
+	}
+
 	// Multiple clients can unsubscribe from the same fingerprint
 	// So, we need to delete the one that is unsubscribing
-	delete(w.fpClientMap[fp], t.ClientID)
+	delete(w.fpClientMap[fp], clientID)
+	delete(w.clientFPMap[clientID], fp)
+	if len(w.clientFPMap[clientID]) == 0 {
+		delete(w.clientFPMap, clientID)
+	}
+
+	if len(w.fpClientMap[fp]) > 0 {
+		return false
+	}
+	delete(w.fpClientMap, fp)
+	return true
+}
 
-	// If a fingerprint has no clients subscribed to it, delete the fingerprint from the map.
-	if len(w.fpClientMap[fp]) == 0 {
-		delete(w.fpClientMap, fp)
+// removeSubscription deletes the subscription with the fingerprint from all the indexes.
+//
+// The subscriptions outlive the keys they read: a deleted key is notified like any
+// other modification and the subscription keeps watching the key in case it gets
+// created again. The index entries of a key hence go away with the last subscription
+// reading the key, not with the key.
+func (w *WatchManager) removeSubscription(fp uint32) {
+	for _, key := range w.fpKeyMap[fp] {
+		delete(w.keyFPMap[key], fp)
+		if len(w.keyFPMap[key]) == 0 {
+			delete(w.keyFPMap, key)
+		}
+	}
+	delete(w.fpKeyMap, fp)
 
-		// If we have deleted the fingerprint, delete the command from the map
-		delete(w.fpCmdMap, fp)
+	if c := w.fpCmdMap[fp]; c != nil && c.C.Cmd == "KEYS.WATCH" {
+		w.patterns.remove(c.C.Args[0], fp)
+	}
+	delete(w.fpCmdMap, fp)
+	delete(w.fpStreams, fp)
+	if d := w.fpDeliveries[fp]; d != nil {
+		d.stop()
+		delete(w.fpDeliveries, fp)
+	}
+	if timer, ok := w.fpOrphans[fp]; ok {
+		timer.Stop()
+		delete(w.fpOrphans, fp)
 	}
+}
 
-	// Delete the mapping where we have the key <--> [command fingerprint]
-	// This seems to be an O(n) operation.
-	// Downside of keeping this entry laying around is that
-	// If key k changes, then we may be iterating through the fingerprint that does not have any active watcher
-	// Hence we should do a lazy deletion.
+// orphan removes the subscription left without clients by a disconnect after
+// watch-retention-sec seconds, unless a client subscribes to it in between.
+// Until then, its updates are still recorded for the clients to resume it.
+func (w *WatchManager) orphan(fp uint32) {
+	retention := time.Duration(config.Config.WatchRetentionSec) * time.Second
+	if retention <= 0 {
+		w.removeSubscription(fp)
+		return
+	}
 
-	// TODO: If the key gets deleted from the database
-	// delete the subscriptions against that key from all the places.
+	var timer *time.Timer
+	timer = time.AfterFunc(retention, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.fpOrphans[fp] == timer {
+			w.removeSubscription(fp)
+		}
+	})
+	w.fpOrphans[fp] = timer
+}
+
+// stream returns the stream of updates of the subscription, creating it if needed.
+func (w *WatchManager) stream(fp uint32) *watchStream {
+	s, ok := w.fpStreams[fp]
+	if !ok {
+		s = newWatchStream(config.Config.WatchReplayBufferSize)
+		w.fpStreams[fp] = s
+	}
+	return s
+}
+
+// Resume resubscribes the client to the subscriptions it had before losing
+// its watch connection and sends it the updates it missed since the given
+// sequence numbers. If the missed updates are no longer retained, the client
+// gets the current output of the subscription with the refresh attribute set,
+// or an error if the subscription is not known.
+func (w *WatchManager) Resume(t *IOThread, points []cmd.ResumePoint) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	q := w.register(t)
+	for _, p := range points {
+		for _, r := range w.resume(t, p) {
+			q.push(p.Fingerprint, r)
+		}
+	}
+}
+
+// resume resubscribes the client to the subscription and
+// returns the updates to send it for the resume point.
+func (w *WatchManager) resume(t *IOThread, p cmd.ResumePoint) []*wire.Response {
+	fp := strconv.FormatUint(uint64(p.Fingerprint), 10)
+	c := w.fpCmdMap[p.Fingerprint]
+	if c == nil {
+		return []*wire.Response{{
+			Err: errors.ErrUnknownSubscription.Error(),
+			Attrs: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"fingerprint": structpb.NewStringValue(fp),
+				"refresh":     structpb.NewBoolValue(true),
+			}},
+		}}
+	}
+
+	w.subscribe(t.ClientID, p.Fingerprint)
+
+	s := w.stream(p.Fingerprint)
+	if updates, ok := s.since(p.Seq); ok {
+		// The updates are compared to the last one the client gets.
+		if d := w.fpDeliveries[p.Fingerprint]; d != nil {
+			if last, ok := s.since(s.seq - 1); ok && len(last) == 1 {
+				d.observe(last[0])
+			}
+		}
+		return updates
+	}
+
+	// The refresh is sent by the dispatcher, the command being re-executed
+	// without holding the lock of the watch manager.
+	w.dispatcher.pushRefresh(p.Fingerprint, t.ClientID)
+	return nil
 }
 
 func (w *WatchManager) CleanupThreadWatchSubscriptions(t *IOThread) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	// Delete the mapping of Watch thread to client id
-	delete(w.clientWatchThreadMap, t.ClientID)
+	// The client has already reconnected and resumed its subscriptions.
+	q := w.clientQueueMap[t.ClientID]
+	if q == nil || q.thread != t {
+		return
+	}
 
-	// Delete all the subscriptions of the client from the fingerprint maps
-	// Note: this is an O(n) operation and hence if there are large number of clients, this might be expensive.
-	// We can do a lazy deletion of the fingerprint map if this becomes a problem.
-	for fp := range w.fpClientMap {
-		delete(w.fpClientMap[fp], t.ClientID)
-		if len(w.fpClientMap[fp]) == 0 {
-			delete(w.fpClientMap, fp)
+	// Delete the mapping of Watch thread to client id
+	q.close()
+	delete(w.clientQueueMap, t.ClientID)
+	if w.cdc != nil {
+		w.cdc.unsubscribe(t.ClientID, q)
+	}
+	w.pubsub.unsubscribe(t.ClientID, nil)
+	w.pubsub.punsubscribe(t.ClientID, nil)
+
+	// Delete all the subscriptions of the client from the fingerprint maps,
+	// retaining the ones left without clients for the client to resume them.
+	for fp := range w.clientFPMap[t.ClientID] {
+		if w.unsubscribe(t.ClientID, fp) {
+			w.orphan(fp)
 		}
 	}
 }
 
+// NotifyWatchers queues the re-execution of the subscriptions reading the keys
+// of the write command, or the initial output of the .WATCH command for the
+// subscribing client. The subscriptions are re-executed by the dispatcher and
+// their updates written to the connections by the goroutines draining the
+// queues, never by the caller. The other commands modify no data and are
+// notified of nothing.
 func (w *WatchManager) NotifyWatchers(c *cmd.Cmd, shardManager *shardmanager.ShardManager, t *IOThread) {
+	isWatch := strings.HasSuffix(c.C.Cmd, ".WATCH")
+	if !isWatch && !c.IsWrite() {
+		return
+	}
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	key := c.Key()
-	for fp := range w.keyFPMap[key] {
-		_c := w.fpCmdMap[fp]
-		if _c == nil {
-			// TODO: Not having a command for a fingerprint is a bug.
-			continue
+	keys := c.Keys()
+	if !isWatch {
+		w.notifyPatternWatchers(c.C.Cmd, keys)
+	}
+
+	// If this is first time a client is connecting it'd be sending a .WATCH command
+	// in that case we don't need to notify all other clients subscribed to the key
+	var clientID string
+	if isWatch {
+		clientID = t.ClientID
+	}
+	w.notifyKeyWatchers(keys, clientID)
+}
+
+// notifyKeyWatchers queues the re-execution of the subscriptions reading
+// any of the keys, for their output to be sent to the subscribed clients, or
+// only to the client with the given ID if not empty.
+func (w *WatchManager) notifyKeyWatchers(keys []string, onlyClientID string) {
+	// A command modifying multiple keys read by the same
+	// subscription notifies its clients only once.
+	var fps map[uint32]bool
+	for _, key := range keys {
+		for fp := range w.keyFPMap[key] {
+			if fps == nil {
+				fps = make(map[uint32]bool)
+			}
+			fps[fp] = true
 		}
+	}
 
-		r, err := _c.Execute(shardManager)
-		if err != nil {
-			slog.Error("failed to execute command as part of watch notification",
-				slog.Any("cmd", _c.String()),
-				slog.Any("error", err))
-			continue
+	for fp := range fps {
+		if onlyClientID != "" {
+			w.dispatcher.push(fp, onlyClientID)
+		} else {
+			w.scheduleUpdate(fp)
 		}
+	}
+}
 
-		for clientID := range w.fpClientMap[fp] {
-			thread := w.clientWatchThreadMap[clientID]
-			if thread == nil {
-				// if there is no thread against the client, delete the client from the map
-				delete(w.clientWatchThreadMap, clientID)
-				continue
-			}
+// scheduleUpdate queues the update of the subscription right away, or
+// coalesces it into a later update as per its delivery options.
+func (w *WatchManager) scheduleUpdate(fp uint32) {
+	d := w.fpDeliveries[fp]
+	if d == nil {
+		w.dispatcher.push(fp, "")
+		return
+	}
 
-			// If this is first time a client is connecting it'd be sending a .WATCH command
-			// in that case we don't need to notify all other clients subscribed to the key
-			if strings.HasSuffix(c.C.Cmd, ".WATCH") && t.ClientID != clientID {
-				continue
-			}
+	now := time.Now()
+	d.due = d.next(now)
+	if d.timer != nil {
+		// The pending update is sent once due, covering the modification.
+		return
+	}
+	if !d.due.After(now) {
+		d.lastRun = now
+		w.dispatcher.push(fp, "")
+		return
+	}
+	d.timer = time.AfterFunc(d.due.Sub(now), func() {
+		w.sendDueUpdate(fp, d)
+	})
+}
 
-			err := thread.IoHandler.WriteSync(context.Background(), r.R)
-			if err != nil {
-				slog.Error("failed to write response to thread",
-					slog.Any("client_id", thread.ClientID),
-					slog.String("mode", thread.Mode),
-					slog.Any("error", err))
-			}
+// sendDueUpdate queues the pending update of the subscription,
+// unless further modifications have pushed its due time back.
+func (w *WatchManager) sendDueUpdate(fp uint32, d *watchDelivery) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// The subscription is gone.
+	if w.fpDeliveries[fp] != d {
+		return
+	}
+
+	if wait := time.Until(d.due); wait > 0 {
+		d.timer = time.AfterFunc(wait, func() {
+			w.sendDueUpdate(fp, d)
+		})
+		return
+	}
+	d.timer = nil
+	d.lastRun = time.Now()
+	w.dispatcher.push(fp, "")
+}
+
+// deliverUpdate re-executes the command of the subscription and sends its
+// output to the subscribed clients, or only to the client of the dispatch
+// if set. The command is re-executed without holding the lock of the watch
+// manager, the output being dropped if the subscription is gone in the
+// meantime.
+func (w *WatchManager) deliverUpdate(e watchDispatch, shardManager *shardmanager.ShardManager) {
+	fp, onlyClientID := e.fp, e.clientID
+	w.mu.RLock()
+	_c := w.fpCmdMap[fp]
+	w.mu.RUnlock()
+	if _c == nil {
+		return
+	}
+
+	r, err := _c.Execute(shardManager)
+	if err != nil && e.refresh {
+		r = &cmd.CmdRes{R: &wire.Response{Err: err.Error()}}
+	} else if err != nil {
+		slog.Error("failed to execute command as part of watch notification",
+			slog.Any("cmd", _c.String()),
+			slog.Any("error", err))
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.fpCmdMap[fp] != _c {
+		return
+	}
+
+	// The initial output sent to a new subscriber carries
+	// the sequence number of the last update it has seen.
+	var update *wire.Response
+	if d := w.fpDeliveries[fp]; onlyClientID != "" {
+		if d != nil {
+			d.observe(r.R)
+		}
+		update = withSeq(r.R, w.stream(fp).seq)
+		if e.refresh {
+			update.Attrs.Fields["fingerprint"] = structpb.NewStringValue(strconv.FormatUint(uint64(fp), 10))
+			update.Attrs.Fields["refresh"] = structpb.NewBoolValue(true)
 		}
+	} else {
+		if d != nil && !d.admit(r.R) {
+			return
+		}
+		update = w.stream(fp).add(r.R)
+	}
+
+	for clientID := range w.fpClientMap[fp] {
+		if onlyClientID != "" && onlyClientID != clientID {
+			continue
+		}
+		if q := w.clientQueueMap[clientID]; q != nil {
+			q.push(fp, update)
+		}
+	}
+
+	slog.Debug("notifying watchers", slog.String("cmd", _c.String()), slog.Int("watchers", len(w.fpClientMap[fp])))
+}
+
+// notifyPatternWatchers queues the notification of the clients subscribed to
+// a pattern matching any of the keys modified by the operation. The value of
+// the keys is read, and sent to the clients, by the dispatcher.
+func (w *WatchManager) notifyPatternWatchers(operation string, keys []string) {
+	if w.patterns.len() == 0 {
+		return
+	}
+	for _, key := range keys {
+		w.dispatcher.pushKey(key, operation)
+	}
+}
+
+// deliverKeyUpdate sends the value of the key modified by the operation to the
+// clients subscribed to a pattern matching the key, if the keyspace event of
+// the operation is enabled. The value is read without holding the lock of the
+// watch manager.
+func (w *WatchManager) deliverKeyUpdate(key, operation string, shardManager *shardmanager.ShardManager) {
+	w.mu.RLock()
+	matched := false
+	w.patterns.match(key, func(uint32) { matched = true })
+	w.mu.RUnlock()
+	if !matched {
+		return
+	}
 
-		slog.Debug("notifying watchers for key", slog.String("key", key), slog.Int("watchers", len(w.fpClientMap[fp])))
+	value := cmd.KeyValue(shardManager, key)
+	if !w.keyspaceEvents[keyspaceEvent(operation, value)] {
+		return
 	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.patterns.match(key, func(fp uint32) {
+		r := w.stream(fp).add(&wire.Response{
+			Value:  value.Value,
+			VSsMap: value.VSsMap,
+			Attrs: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"fingerprint": structpb.NewStringValue(strconv.FormatUint(uint64(fp), 10)),
+				"key":         structpb.NewStringValue(key),
+				"operation":   structpb.NewStringValue(operation),
+			}},
+		})
+		for clientID := range w.fpClientMap[fp] {
+			if q := w.clientQueueMap[clientID]; q != nil {
+				q.push(fp, r)
+			}
+		}
+	})
 }
@@ -0,0 +1,343 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// watchClient is the client end of a framed watch connection, on
+// which the updates written in a single batch can be told apart.
+type watchClient struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// startWatchManager runs a watch manager receiving the events of the stores
+// and returns it along with a watch client connected to it.
+func startWatchManager(t *testing.T) (*WatchManager, *shardmanager.ShardManager, *IOThread, *watchClient) {
+	t.Helper()
+	events := make(chan *dstore.Store, config.WatchChanBufSize)
+	sm := shardmanager.NewShardManager(2, events, make(chan error))
+	wm := NewWatchManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wm.Run(ctx, events, sm)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+	thread := &IOThread{ClientID: "c1", Mode: "watch", IoHandler: NewIOHandlerWithConn(server)}
+	thread.IoHandler.EnableFraming()
+	return wm, sm, thread, &watchClient{Conn: client, r: bufio.NewReader(client)}
+}
+
+func watch(t *testing.T, wm *WatchManager, thread *IOThread, c string, args ...string) {
+	t.Helper()
+	wm.HandleWatch(&cmd.Cmd{C: &wire.Command{Cmd: c, Args: args}, ClientID: thread.ClientID}, thread)
+}
+
+// flushDispatcher re-executes the subscriptions queued by the notifications
+// made so far, rather than letting the dispatcher coalesce them with the
+// ones made afterwards.
+func flushDispatcher(wm *WatchManager, sm *shardmanager.ShardManager) {
+	for _, e := range wm.dispatcher.take() {
+		wm.deliver(e, sm)
+	}
+}
+
+func readUpdate(t *testing.T, conn *watchClient) *wire.Response {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	r := &wire.Response{}
+	if err := protodelim.UnmarshalFrom(conn.r, r); err != nil {
+		t.Fatalf("failed to read the update: %v", err)
+	}
+	return r
+}
+
+func TestWatchManagerStoreEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		remove    func(s *dstore.Store, key string)
+		operation string
+	}{
+		{
+			name: "evicted",
+			remove: func(s *dstore.Store, key string) {
+				s.Del(key, dstore.WithDelCmd(dstore.Evict))
+			},
+			operation: dstore.Evict,
+		},
+		{
+			name: "expired",
+			remove: func(s *dstore.Store, key string) {
+				s.SetKeyExpiry(key, s.GetNoTouch(key), 1)
+				time.Sleep(5 * time.Millisecond)
+				// The key is deleted by the shard cron, the reads leaving it be.
+				dstore.DeleteExpiredKeys(s, time.Second)
+			},
+			operation: dstore.Expired,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			wm, sm, thread, conn := startWatchManager(t)
+			watch(t, wm, thread, "GET.WATCH", "k1")
+			watch(t, wm, thread, "KEYS.WATCH", "k*")
+
+			s := sm.GetShardForKey("k1").Thread.Store()
+			s.Put("k1", s.NewObj("v1", -1, object.ObjTypeString))
+			tc.remove(s, "k1")
+
+			// The subscribers to the pattern are notified first.
+			r := readUpdate(t, conn)
+			if op := r.GetAttrs().GetFields()["operation"].GetStringValue(); op != tc.operation || !r.GetVNil() {
+				t.Fatalf("expected a nil value for %s, got %v", tc.operation, r)
+			}
+			if r = readUpdate(t, conn); !r.GetVNil() || r.GetAttrs().GetFields()["fingerprint"] == nil {
+				t.Fatalf("expected the GET.WATCH subscription to get nil, got %v", r)
+			}
+		})
+	}
+}
+
+func TestWatchManagerKeyspaceEvents(t *testing.T) {
+	prev := config.Config.KeyspaceEvents
+	config.Config.KeyspaceEvents = "set, expired"
+	t.Cleanup(func() { config.Config.KeyspaceEvents = prev })
+
+	wm, sm, thread, conn := startWatchManager(t)
+	watch(t, wm, thread, "KEYS.WATCH", "k*")
+
+	// The eviction is not notified, the expiry is.
+	s := sm.GetShardForKey("k1").Thread.Store()
+	s.Put("k1", s.NewObj("v1", -1, object.ObjTypeString))
+	s.Del("k1", dstore.WithDelCmd(dstore.Evict))
+	s.Put("k1", s.NewObj("v1", 1, object.ObjTypeString))
+	time.Sleep(5 * time.Millisecond)
+	dstore.DeleteExpiredKeys(s, time.Second)
+
+	r := readUpdate(t, conn)
+	if op := r.GetAttrs().GetFields()["operation"].GetStringValue(); op != dstore.Expired {
+		t.Fatalf("expected only the expiry to be notified, got %v", r)
+	}
+}
+
+func newWatchThread(t *testing.T, clientID string) *IOThread {
+	t.Helper()
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+	// The updates are never read, they are dropped once the queue is full.
+	return &IOThread{ClientID: clientID, Mode: "watch", IoHandler: NewIOHandlerWithConn(server)}
+}
+
+func unwatch(wm *WatchManager, thread *IOThread, c string, args ...string) {
+	fp := (&cmd.Cmd{C: &wire.Command{Cmd: c, Args: args}}).Fingerprint()
+	wm.HandleUnwatch(&cmd.Cmd{C: &wire.Command{Cmd: "UNWATCH", Args: []string{strconv.FormatUint(uint64(fp), 10)}}}, thread)
+}
+
+// assertNoWatchState fails the test if any index of the watch manager has entries left.
+func assertNoWatchState(t *testing.T, wm *WatchManager) {
+	t.Helper()
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+
+	sizes := map[string]int{
+		"keyFPMap":       len(wm.keyFPMap),
+		"fpClientMap":    len(wm.fpClientMap),
+		"fpCmdMap":       len(wm.fpCmdMap),
+		"fpKeyMap":       len(wm.fpKeyMap),
+		"clientFPMap":    len(wm.clientFPMap),
+		"clientQueueMap": len(wm.clientQueueMap),
+		"fpStreams":      len(wm.fpStreams),
+		"fpDeliveries":   len(wm.fpDeliveries),
+		"fpOrphans":      len(wm.fpOrphans),
+		"patterns":       wm.patterns.len(),
+	}
+	for name, n := range sizes {
+		if n != 0 {
+			t.Errorf("expected %s to be empty, got %d entries", name, n)
+		}
+	}
+}
+
+func TestWatchManagerNotifyWrites(t *testing.T) {
+	wm, sm, thread, conn := startWatchManager(t)
+	watch(t, wm, thread, "GET.WATCH", "k1")
+	s := sm.GetShardForKey("k1").Thread.Store()
+	s.Put("k1", s.NewObj("v1", -1, object.ObjTypeString))
+	writer := &IOThread{ClientID: "c2"}
+
+	// The reads notify nothing, without waiting for the watch manager.
+	wm.mu.Lock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wm.NotifyWatchers(&cmd.Cmd{C: &wire.Command{Cmd: "GET", Args: []string{"k1"}}}, sm, writer)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the read not to wait for the watch manager")
+	}
+	wm.mu.Unlock()
+
+	wm.NotifyWatchers(&cmd.Cmd{C: &wire.Command{Cmd: "SET", Args: []string{"k1", "v1"}}}, sm, writer)
+	if r := readUpdate(t, conn); r.GetVStr() != "v1" {
+		t.Fatalf("expected the update v1, got %v", r)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Fatalf("expected only the write to be notified")
+	}
+}
+
+func TestWatchManagerPatternWatchersOffWritePath(t *testing.T) {
+	wm, sm, thread, conn := startWatchManager(t)
+	watch(t, wm, thread, "KEYS.WATCH", "k*")
+	sh := sm.GetShardForKey("k1")
+	s := sh.Thread.Store()
+	writer := &IOThread{ClientID: "c2"}
+
+	// The value of the key is read by the dispatcher, not by the writer
+	// notifying the watch manager, which may still hold the shard lock.
+	sh.Lock()
+	s.Put("k1", s.NewObj("v1", -1, object.ObjTypeString))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wm.NotifyWatchers(&cmd.Cmd{C: &wire.Command{Cmd: "SET", Args: []string{"k1", "v1"}}}, sm, writer)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the write not to wait for the pattern watchers")
+	}
+	sh.Unlock()
+
+	r := readUpdate(t, conn)
+	if r.GetVStr() != "v1" || r.GetAttrs().GetFields()["key"].GetStringValue() != "k1" {
+		t.Fatalf("expected the update v1 of k1, got %v", r)
+	}
+}
+
+func TestWatchManagerCleanup(t *testing.T) {
+	setConfig(t, &config.Config.WatchRetentionSec, 0)
+	wm, sm, _, _ := startWatchManager(t)
+	set := &cmd.Cmd{C: &wire.Command{Cmd: "SET", Args: []string{"k1", "v"}}}
+
+	for i := 0; i < 50; i++ {
+		thread := newWatchThread(t, fmt.Sprintf("c%d", i))
+		key := fmt.Sprintf("k%d", i%5)
+		watch(t, wm, thread, "GET.WATCH", key)
+		watch(t, wm, thread, "GET.WATCH", key, "THROTTLE", "1/s")
+		watch(t, wm, thread, "MGET.WATCH", "k1", key)
+		watch(t, wm, thread, "KEYS.WATCH", key+"*")
+		wm.NotifyWatchers(set, sm, thread)
+
+		// Half of the clients unwatch their subscriptions, the
+		// other half disconnects with the subscriptions still live.
+		if i%2 == 0 {
+			unwatch(wm, thread, "GET.WATCH", key)
+			unwatch(wm, thread, "GET.WATCH", key, "THROTTLE", "1/s")
+			unwatch(wm, thread, "MGET.WATCH", "k1", key)
+			unwatch(wm, thread, "KEYS.WATCH", key+"*")
+		}
+		wm.CleanupThreadWatchSubscriptions(thread)
+	}
+
+	assertNoWatchState(t, wm)
+}
+
+func TestWatchManagerRetention(t *testing.T) {
+	setConfig(t, &config.Config.WatchRetentionSec, 1)
+	wm, _, _, _ := startWatchManager(t)
+
+	thread := newWatchThread(t, "c1")
+	watch(t, wm, thread, "GET.WATCH", "k1")
+	wm.CleanupThreadWatchSubscriptions(thread)
+	if stats := wm.Stats(); stats.Subscriptions != 1 || stats.Orphaned != 1 {
+		t.Fatalf("expected the subscription to be retained, got %+v", stats)
+	}
+
+	// Resuming the subscription keeps it live past the retention.
+	fp := (&cmd.Cmd{C: &wire.Command{Cmd: "GET.WATCH", Args: []string{"k1"}}}).Fingerprint()
+	thread = newWatchThread(t, "c1")
+	wm.Resume(thread, []cmd.ResumePoint{{Fingerprint: fp}})
+	time.Sleep(1500 * time.Millisecond)
+	if stats := wm.Stats(); stats.Subscriptions != 1 || stats.Orphaned != 0 {
+		t.Fatalf("expected the resumed subscription to be live, got %+v", stats)
+	}
+
+	// Left without clients again, it is removed after the retention.
+	wm.CleanupThreadWatchSubscriptions(thread)
+	time.Sleep(1500 * time.Millisecond)
+	assertNoWatchState(t, wm)
+}
+
+func TestWatchManagerOnlyChangedAfterRetention(t *testing.T) {
+	setConfig(t, &config.Config.WatchRetentionSec, 60)
+	wm, sm, thread, conn := startWatchManager(t)
+	s := sm.GetShardForKey("k1").Thread.Store()
+	set := func(v string) {
+		s.Put("k1", s.NewObj(v, -1, object.ObjTypeString))
+		wm.NotifyWatchers(&cmd.Cmd{C: &wire.Command{Cmd: "SET", Args: []string{"k1", v}}}, sm, thread)
+	}
+	subscribe := func() {
+		c := &cmd.Cmd{C: &wire.Command{Cmd: "GET.WATCH", Args: []string{"k1", "ONLYCHANGED"}}, ClientID: thread.ClientID}
+		wm.HandleWatch(c, thread)
+		wm.NotifyWatchers(c, sm, thread)
+	}
+
+	subscribe()
+	if r := readUpdate(t, conn); !r.GetVNil() {
+		t.Fatalf("expected the output nil, got %v", r)
+	}
+	set("v1")
+	if r := readUpdate(t, conn); r.GetVStr() != "v1" {
+		t.Fatalf("expected the update v1, got %v", r)
+	}
+	wm.CleanupThreadWatchSubscriptions(thread)
+
+	// The client subscribing to the retained subscription gets the
+	// updates changing the output it was sent, v2, back to v1.
+	s.Put("k1", s.NewObj("v2", -1, object.ObjTypeString))
+	subscribe()
+	if r := readUpdate(t, conn); r.GetVStr() != "v2" {
+		t.Fatalf("expected the output v2, got %v", r)
+	}
+	set("v1")
+	if r := readUpdate(t, conn); r.GetVStr() != "v1" {
+		t.Fatalf("expected the update v1, got %v", r)
+	}
+}
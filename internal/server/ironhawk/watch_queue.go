@@ -0,0 +1,226 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+// The policies applied when the outbound queue of a watch connection is full,
+// as named in the watch-queue-policy config.
+const (
+	// WatchQueueDropOldest drops the oldest queued update.
+	WatchQueueDropOldest = "drop-oldest"
+	// WatchQueueDisconnect disconnects the client.
+	WatchQueueDisconnect = "disconnect"
+	// WatchQueueCoalesceLatest replaces the queued update of the same
+	// subscription with the new one, and drops the oldest update otherwise.
+	WatchQueueCoalesceLatest = "coalesce-latest"
+)
+
+// watchUpdate is an update of the subscription with the fingerprint fp.
+type watchUpdate struct {
+	fp uint32
+	r  *wire.Response
+}
+
+// watchQueueStats are the counters shared by the outbound queues.
+type watchQueueStats struct {
+	dropped      atomic.Int64
+	disconnected atomic.Int64
+}
+
+// watchQueue is the bounded outbound queue of a watch connection. The updates
+// are queued by the watch manager and written to the connection by a goroutine
+// of the queue, so that a slow client never stalls the clients modifying the
+// keys it watches, nor the other watchers.
+type watchQueue struct {
+	thread *IOThread
+	size   int
+	policy string
+	stats  *watchQueueStats
+
+	mu      sync.Mutex
+	updates []watchUpdate
+	closed  bool
+	// ready is signaled when updates are queued or the queue is closed,
+	// and drained when the queued updates are taken to be written.
+	ready   chan struct{}
+	drained chan struct{}
+}
+
+func newWatchQueue(t *IOThread, size int, policy string, stats *watchQueueStats) *watchQueue {
+	return &watchQueue{
+		thread:  t,
+		size:    max(size, 1),
+		policy:  policy,
+		stats:   stats,
+		ready:   make(chan struct{}, 1),
+		drained: make(chan struct{}, 1),
+	}
+}
+
+// push queues the update, applying the policy of the queue if it is full.
+func (q *watchQueue) push(fp uint32, r *wire.Response) {
+	q.pushWithPolicy(fp, r, q.policy)
+}
+
+func (q *watchQueue) pushWithPolicy(fp uint32, r *wire.Response, policy string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+
+	u := watchUpdate{fp: fp, r: r}
+	if len(q.updates) < q.size {
+		q.updates = append(q.updates, u)
+		q.signal()
+		return
+	}
+
+	q.stats.dropped.Add(1)
+	switch policy {
+	case WatchQueueDisconnect:
+		q.disconnectLocked()
+		return
+	case WatchQueueCoalesceLatest:
+		// The messages and the change records, queued with no
+		// fingerprint, are never replaced by one another.
+		for i := len(q.updates) - 1; fp != 0 && i >= 0; i-- {
+			if q.updates[i].fp == fp {
+				q.updates[i] = u
+				return
+			}
+		}
+	}
+	q.updates = append(q.updates[1:], u)
+}
+
+// ping queues the keep-alive ping, unless updates are queued, which keep
+// the connection alive on their own.
+func (q *watchQueue) ping(r *wire.Response) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed || len(q.updates) > 0 {
+		return
+	}
+	q.updates = append(q.updates, watchUpdate{r: r})
+	q.signal()
+}
+
+// disconnect disconnects the client not keeping up with the updates.
+func (q *watchQueue) disconnect() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.disconnectLocked()
+	}
+}
+
+func (q *watchQueue) disconnectLocked() {
+	slog.Warn("disconnecting the watch client not keeping up with the updates",
+		slog.String("client_id", q.thread.ClientID))
+	q.stats.disconnected.Add(1)
+	q.closed = true
+	q.updates = nil
+	q.thread.IoHandler.shutdown()
+	q.signal()
+	q.signalDrained()
+}
+
+func (q *watchQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// waitRoom waits until the queue is not full, and returns false if it is closed.
+func (q *watchQueue) waitRoom() bool {
+	for {
+		q.mu.Lock()
+		closed, full := q.closed, len(q.updates) >= q.size
+		q.mu.Unlock()
+		if closed {
+			return false
+		}
+		if !full {
+			return true
+		}
+		<-q.drained
+	}
+}
+
+func (q *watchQueue) signalDrained() {
+	select {
+	case q.drained <- struct{}{}:
+	default:
+	}
+}
+
+// len returns the number of updates queued.
+func (q *watchQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.updates)
+}
+
+// close stops the queue, dropping the updates not yet written.
+func (q *watchQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.updates = nil
+	q.signal()
+	q.signalDrained()
+}
+
+// run writes the queued updates to the connection until the queue is closed
+// or the connection breaks. The updates queued while a write is in progress
+// are written together on a framed connection. On an unframed connection,
+// whose client tells the updates apart by the reads they arrive in, every
+// update is flushed on its own.
+func (q *watchQueue) run() {
+	for range q.ready {
+		q.mu.Lock()
+		updates, closed := q.updates, q.closed
+		q.updates = nil
+		q.mu.Unlock()
+		if closed {
+			return
+		}
+		q.signalDrained()
+
+		framed := q.thread.IoHandler.Framed()
+		for _, u := range updates {
+			if err := q.thread.IoHandler.WriteBuffered(u.r); err != nil {
+				q.fail(err)
+				return
+			}
+			if !framed {
+				if err := q.thread.IoHandler.Flush(); err != nil {
+					q.fail(err)
+					return
+				}
+			}
+		}
+		if err := q.thread.IoHandler.Flush(); err != nil {
+			q.fail(err)
+			return
+		}
+	}
+}
+
+func (q *watchQueue) fail(err error) {
+	slog.Error("failed to write response to thread",
+		slog.Any("client_id", q.thread.ClientID),
+		slog.String("mode", q.thread.Mode),
+		slog.Any("error", err))
+	q.close()
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWatchQueuePolicies(t *testing.T) {
+	type push struct {
+		fp uint32
+		v  string
+	}
+	tests := []struct {
+		policy   string
+		pushes   []push
+		expected []string
+	}{
+		{
+			policy:   WatchQueueDropOldest,
+			pushes:   []push{{1, "a"}, {2, "b"}, {1, "c"}},
+			expected: []string{"b", "c"},
+		},
+		{
+			policy:   WatchQueueCoalesceLatest,
+			pushes:   []push{{1, "a"}, {2, "b"}, {1, "c"}},
+			expected: []string{"c", "b"},
+		},
+		{
+			policy:   WatchQueueCoalesceLatest,
+			pushes:   []push{{1, "a"}, {2, "b"}, {3, "c"}},
+			expected: []string{"b", "c"},
+		},
+		{
+			policy:   WatchQueueDisconnect,
+			pushes:   []push{{1, "a"}, {2, "b"}, {1, "c"}},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.policy, func(t *testing.T) {
+			server, client := net.Pipe()
+			defer client.Close()
+			stats := &watchQueueStats{}
+			thread := &IOThread{ClientID: "c1", Mode: "watch", IoHandler: NewIOHandlerWithConn(server)}
+			q := newWatchQueue(thread, 2, tc.policy, stats)
+
+			for _, p := range tc.pushes {
+				q.push(p.fp, &wire.Response{Value: &wire.Response_VStr{VStr: p.v}})
+			}
+
+			if len(q.updates) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, q.updates)
+			}
+			for i, u := range q.updates {
+				if u.r.GetVStr() != tc.expected[i] {
+					t.Fatalf("expected %v, got %v", tc.expected, q.updates)
+				}
+			}
+			if stats.dropped.Load() != 1 {
+				t.Fatalf("expected 1 update dropped, got %d", stats.dropped.Load())
+			}
+
+			if tc.policy == WatchQueueDisconnect {
+				if stats.disconnected.Load() != 1 {
+					t.Fatalf("expected the client to be disconnected")
+				}
+				if _, err := client.Read(make([]byte, 1)); err != io.EOF {
+					t.Fatalf("expected the connection to be closed, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchQueueUnframed(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	thread := &IOThread{ClientID: "c1", Mode: "watch", IoHandler: NewIOHandlerWithConn(server)}
+	q := newWatchQueue(thread, 4, WatchQueueDropOldest, &watchQueueStats{})
+	defer q.close()
+
+	// The updates queued together are written one per read, which is
+	// how the unframed clients tell them apart.
+	expected := []string{"a", "b", "c"}
+	for i, v := range expected {
+		q.push(uint32(i), &wire.Response{Value: &wire.Response_VStr{VStr: v}})
+	}
+	go q.run()
+
+	buf := make([]byte, 512)
+	for _, v := range expected {
+		_ = client.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := client.Read(buf)
+		if err != nil {
+			t.Fatalf("failed to read the update: %v", err)
+		}
+		r := &wire.Response{}
+		if err := proto.Unmarshal(buf[:n], r); err != nil || r.GetVStr() != v {
+			t.Fatalf("expected the update %s alone, got %v %v", v, r, err)
+		}
+	}
+}
+
+func TestWatchManagerSlowSubscriber(t *testing.T) {
+	setConfig(t, &config.Config.WatchQueueSize, 4)
+	wm, sm, thread, _ := startWatchManager(t)
+
+	// The subscriber never reads the updates.
+	watch(t, wm, thread, "GET.WATCH", "k1")
+
+	s := sm.GetShardForKey("k1").Thread.Store()
+	set := &cmd.Cmd{C: &wire.Command{Cmd: "SET", Args: []string{"k1", "v"}}}
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		s.Put("k1", s.NewObj(int64(i), -1, object.ObjTypeInt))
+		wm.NotifyWatchers(set, sm, &IOThread{ClientID: "c2"})
+		flushDispatcher(wm, sm)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("notifying took %v, the slow subscriber is blocking the writer", d)
+	}
+
+	stats := wm.Stats()
+	if stats.Clients != 1 || stats.QueueDepth > 4 || stats.MaxQueueDepth != stats.QueueDepth || stats.Dropped == 0 {
+		t.Fatalf("expected the updates to be dropped from a full queue, got %+v", stats)
+	}
+}
+
+func TestWatchPingQueued(t *testing.T) {
+	wm := NewWatchManager()
+	server, client := net.Pipe()
+	defer client.Close()
+	thread := &IOThread{ClientID: "c1", Mode: "watch", IoHandler: NewIOHandlerWithConn(server)}
+	wm.RegisterThread(thread)
+	q := wm.clientQueueMap["c1"]
+
+	// The client reads nothing, hence the first ping is stuck being
+	// written, while the pings after it neither wait nor pile up.
+	wm.Ping(thread)
+	for deadline := time.Now().Add(time.Second); q.len() > 0; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the ping to be taken to be written")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			wm.Ping(thread)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the pings not to wait for the write in progress")
+	}
+	if n := q.len(); n != 1 {
+		t.Fatalf("expected a single ping queued, got %d", n)
+	}
+
+	if r := readUnframed(t, client); r.GetAttrs().GetFields()["type"].GetStringValue() != "ping" {
+		t.Fatalf("expected a ping, got %v", r)
+	}
+	wm.CleanupThreadWatchSubscriptions(thread)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"maps"
+	"strconv"
+
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// watchStream numbers the updates of a subscription and retains the most
+// recent of them so that the clients resuming the subscription after a
+// disconnect get the updates they missed.
+type watchStream struct {
+	// seq is the sequence number of the last update, 0 if none.
+	seq uint64
+	// ring holds the last updates, the update with
+	// the sequence number n at the index (n-1) % len(ring).
+	ring []*wire.Response
+}
+
+func newWatchStream(size int) *watchStream {
+	return &watchStream{ring: make([]*wire.Response, max(size, 0))}
+}
+
+// add assigns the next sequence number to the update
+// and returns the update carrying it in its attributes.
+func (s *watchStream) add(r *wire.Response) *wire.Response {
+	s.seq++
+	r = withSeq(r, s.seq)
+	if len(s.ring) > 0 {
+		s.ring[(s.seq-1)%uint64(len(s.ring))] = r
+	}
+	return r
+}
+
+// since returns the updates following the one with the sequence number,
+// in order. It returns false if the updates are no longer retained or
+// the sequence number is ahead of the stream.
+func (s *watchStream) since(seq uint64) ([]*wire.Response, bool) {
+	if seq > s.seq || s.seq-seq > uint64(len(s.ring)) {
+		return nil, false
+	}
+
+	updates := make([]*wire.Response, 0, s.seq-seq)
+	for n := seq + 1; n <= s.seq; n++ {
+		updates = append(updates, s.ring[(n-1)%uint64(len(s.ring))])
+	}
+	return updates, true
+}
+
+// withSeq returns a copy of the response carrying the sequence number.
+func withSeq(r *wire.Response, seq uint64) *wire.Response {
+	fields := make(map[string]*structpb.Value)
+	if r.Attrs != nil {
+		maps.Copy(fields, r.Attrs.Fields)
+	}
+	fields["seq"] = structpb.NewStringValue(strconv.FormatUint(seq, 10))
+
+	return &wire.Response{
+		Err:    r.Err,
+		Value:  r.Value,
+		Attrs:  &structpb.Struct{Fields: fields},
+		VList:  r.VList,
+		VSsMap: r.VSsMap,
+	}
+}
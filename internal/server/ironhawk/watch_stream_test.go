@@ -0,0 +1,44 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+func TestWatchStream(t *testing.T) {
+	s := newWatchStream(3)
+	for i := int64(1); i <= 5; i++ {
+		r := s.add(&wire.Response{Value: &wire.Response_VInt{VInt: i}})
+		if got := r.GetAttrs().GetFields()["seq"].GetStringValue(); got != strconv.FormatInt(i, 10) {
+			t.Fatalf("expected seq %d, got %s", i, got)
+		}
+	}
+
+	tests := []struct {
+		since    uint64
+		expected []int64
+		ok       bool
+	}{
+		{since: 5, expected: []int64{}, ok: true},
+		{since: 3, expected: []int64{4, 5}, ok: true},
+		{since: 2, expected: []int64{3, 4, 5}, ok: true},
+		{since: 1, ok: false},
+		{since: 6, ok: false},
+	}
+	for _, tc := range tests {
+		updates, ok := s.since(tc.since)
+		if ok != tc.ok || len(updates) != len(tc.expected) {
+			t.Fatalf("since(%d): expected %v %v, got %v %v", tc.since, tc.expected, tc.ok, updates, ok)
+		}
+		for i, r := range updates {
+			if r.GetVInt() != tc.expected[i] {
+				t.Fatalf("since(%d): expected %v, got %v", tc.since, tc.expected, updates)
+			}
+		}
+	}
+}
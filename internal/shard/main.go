@@ -3,9 +3,30 @@
 
 package shard
 
-import "github.com/dicedb/dice/internal/shardthread"
+import (
+	"sync"
+
+	"github.com/dicedb/dice/internal/shardthread"
+)
 
 type Shard struct {
 	ID     int
 	Thread *shardthread.ShardThread
+
+	// mu isolates the writes to the shard from the other commands
+	// executing on it. The reads hold the read lock while the writes,
+	// the atomic batches and the cron tasks of the shard hold the
+	// write lock.
+	mu sync.RWMutex
+}
+
+func (s *Shard) Lock()    { s.mu.Lock() }
+func (s *Shard) RLock()   { s.mu.RLock() }
+func (s *Shard) RUnlock() { s.mu.RUnlock() }
+
+// Unlock releases the write lock, once the values evicted by the command
+// or cron task holding it are handed over to be freed in the background.
+func (s *Shard) Unlock() {
+	s.Thread.Store().FreeEvicted()
+	s.mu.Unlock()
 }
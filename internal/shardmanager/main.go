@@ -23,14 +23,20 @@ type ShardManager struct {
 }
 
 // NewShardManager creates a new ShardManager instance with the given number of Shards and a parent context.
-func NewShardManager(shardCount int, globalErrorChan chan error) *ShardManager {
+// The stores of the shards are sent to cmdWatchChan, if not nil, once they have changes to notify the watchers of.
+func NewShardManager(shardCount int, cmdWatchChan chan *store.Store, globalErrorChan chan error) *ShardManager {
 	shards := make([]*shard.Shard, shardCount)
-	maxKeysPerShard := config.DefaultKeysLimit / shardCount
+	// The key limit is shared by the shards, rather than split evenly across
+	// them, for the shards not to evict while the others have room to spare.
+	keyLimit := store.NewKeyLimit(config.Config.MaxKeys, shardCount)
+	maxBytesPerShard := int64(config.Config.MaxMemory) / int64(shardCount)
 	for i := 0; i < shardCount; i++ {
 		shards[i] = &shard.Shard{
-			ID:     i,
-			Thread: shardthread.NewShardThread(i, globalErrorChan, store.NewPrimitiveEvictionStrategy(maxKeysPerShard)),
+			ID: i,
+			Thread: shardthread.NewShardThread(i, cmdWatchChan, globalErrorChan,
+				store.NewEvictionStrategy(config.Config.EvictionPolicy, 0, maxBytesPerShard)),
 		}
+		shards[i].Thread.Store().UseKeyLimit(keyLimit)
 	}
 
 	return &ShardManager{
@@ -65,7 +71,7 @@ func (manager *ShardManager) start(ctx context.Context, wg *sync.WaitGroup) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			sh.Thread.Start(ctx)
+			sh.Thread.Start(ctx, sh)
 		}()
 	}
 }
@@ -5,6 +5,7 @@ package shardthread
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/dicedb/dice/config"
@@ -21,25 +22,32 @@ type ShardThread struct {
 }
 
 // NewShardThread creates a new ShardThread instance with the given shard id and error channel.
-func NewShardThread(id int, gec chan error, evictionStrategy dstore.EvictionStrategy) *ShardThread {
+// The store of the shard is sent to cmdWatchChan, if not nil, once it has changes to notify the watchers of.
+func NewShardThread(id int, cmdWatchChan chan *dstore.Store, gec chan error,
+	evictionStrategy dstore.EvictionStrategy) *ShardThread {
+	store := dstore.NewStore(cmdWatchChan, evictionStrategy, id)
+	store.UseTable(config.Config.KeyspaceTable)
+	store.TrackHotKeys(config.Config.HotKeysSampleRate)
 	return &ShardThread{
 		id:               id,
-		store:            dstore.NewStore(nil, evictionStrategy, id),
+		store:            store,
 		globalErrorChan:  gec,
 		lastCronExecTime: utils.GetCurrentTime(),
 		cronFrequency:    config.ShardCronFrequency,
 	}
 }
 
-// Start starts the shard thread, listening for incoming requests.
-func (shard *ShardThread) Start(ctx context.Context) {
+// Start starts the shard thread, listening for incoming requests. The cron
+// tasks modifying the store hold mu, the write lock of the shard, for them
+// not to run concurrently with the commands executing on the shard.
+func (shard *ShardThread) Start(ctx context.Context, mu sync.Locker) {
 	ticker := time.NewTicker(shard.cronFrequency)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			shard.runCronTasks()
+			shard.runCronTasks(mu)
 		case <-ctx.Done():
 			shard.cleanup()
 			return
@@ -47,9 +55,14 @@ func (shard *ShardThread) Start(ctx context.Context) {
 	}
 }
 
-// runCronTasks runs the cron tasks for the shard. This includes deleting expired keys.
-func (shard *ShardThread) runCronTasks() {
-	dstore.DeleteExpiredKeys(shard.store)
+// runCronTasks runs the cron tasks for the shard. This includes deleting expired keys
+// and evicting the keys in excess of the memory limit left by the writes.
+func (shard *ShardThread) runCronTasks(mu sync.Locker) {
+	mu.Lock()
+	dstore.DeleteExpiredKeys(shard.store, config.ExpiryCronBudget)
+	shard.store.EvictExcess(config.EvictionCronBudget)
+	mu.Unlock()
+
 	shard.lastCronExecTime = utils.GetCurrentTime()
 }
 
@@ -0,0 +1,44 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package shardthread
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+)
+
+func TestCronTasksHoldShardLock(t *testing.T) {
+	config.ForceInit(&config.DiceDBConfig{})
+	shard := NewShardThread(0, nil, make(chan error), nil)
+	s := shard.Store()
+	s.Put("k1", s.NewObj(int64(1), 1, object.ObjTypeInt))
+	time.Sleep(5 * time.Millisecond)
+
+	// The cron tasks wait for the commands holding the lock of the shard.
+	var mu sync.Mutex
+	mu.Lock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		shard.runCronTasks(&mu)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("expected the cron tasks to wait for the lock of the shard")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, ok := s.GetStore().Get("k1"); !ok {
+		t.Fatalf("expected the expired key not to be deleted while the lock is held")
+	}
+
+	mu.Unlock()
+	<-done
+	if _, ok := s.GetStore().Get("k1"); ok {
+		t.Fatalf("expected the expired key to be deleted once the lock is released")
+	}
+}
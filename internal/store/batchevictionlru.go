@@ -48,11 +48,14 @@ func (h *evictionItemHeap) pop() evictionItem {
 type PrimitiveEvictionStrategy struct {
 	BaseEvictionStrategy
 	maxKeys int
+	// maxBytes is the memory limit of the store, 0 for no limit.
+	maxBytes int64
 }
 
-func NewPrimitiveEvictionStrategy(maxKeys int) *PrimitiveEvictionStrategy {
+func NewPrimitiveEvictionStrategy(maxKeys int, maxBytes int64) *PrimitiveEvictionStrategy {
 	return &PrimitiveEvictionStrategy{
-		maxKeys: maxKeys,
+		maxKeys:  maxKeys,
+		maxBytes: maxBytes,
 	}
 }
 
@@ -76,6 +79,12 @@ func (e *PrimitiveEvictionStrategy) ShouldEvict(store *Store) int {
 	return toEvict
 }
 
+// ShouldEvictBytes returns the number of keys to evict for the store to grow by
+// the given number of bytes within maxBytes.
+func (e *PrimitiveEvictionStrategy) ShouldEvictBytes(store *Store, grow int64) int {
+	return keysToEvictForBytes(store, e.maxBytes, grow)
+}
+
 // EvictVictims deletes keys with the lowest LastAccessedAt values from the store.
 func (e *PrimitiveEvictionStrategy) EvictVictims(store *Store, toEvict int) {
 	if toEvict <= 0 {
@@ -86,6 +95,9 @@ func (e *PrimitiveEvictionStrategy) EvictVictims(store *Store, toEvict int) {
 	heap.Init(&h)
 
 	store.GetStore().All(func(k string, obj *object.Obj) bool {
+		if !store.evictable(k) {
+			return true
+		}
 		item := evictionItem{
 			key:          k,
 			lastAccessed: obj.LastAccessedAt,
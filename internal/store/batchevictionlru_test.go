@@ -4,18 +4,20 @@
 package store
 
 import (
+	"math"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/object"
 	"github.com/dicedb/dice/internal/server/utils"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestEvictVictims_BelowMaxKeys(t *testing.T) {
-	eviction := NewPrimitiveEvictionStrategy(5, 0.2)
-	s := NewStore(nil, eviction)
+	eviction := NewPrimitiveEvictionStrategy(5, 0)
+	s := NewStore(nil, eviction, 0)
 
 	// Add 3 keys (below maxKeys of 5)
 	for i := 1; i <= 3; i++ {
@@ -36,8 +38,8 @@ func TestEvictVictims_BelowMaxKeys(t *testing.T) {
 
 func TestEvictVictims_ExceedsMaxKeys(t *testing.T) {
 	maxKeys := 5
-	eviction := NewPrimitiveEvictionStrategy(maxKeys)
-	s := NewStore(nil, eviction)
+	eviction := NewPrimitiveEvictionStrategy(maxKeys, 0)
+	s := NewStore(nil, eviction, 0)
 
 	// Add 10 keys, exceeding maxKeys of 5
 	for i := 1; i <= 10; i++ {
@@ -54,12 +56,18 @@ func TestEvictVictims_ExceedsMaxKeys(t *testing.T) {
 	assert.True(t, keyCount <= maxKeys, "Should have max or lesser number of keys remaining after eviction")
 }
 
+// evictionTarget returns the number of keys left by the batch eviction of
+// a store holding maxKeys keys.
+func evictionTarget(maxKeys int) int {
+	return int(math.Ceil(float64(maxKeys) * (1 - config.EvictionRatio)))
+}
+
 func TestEvictVictims_EvictsLRU(t *testing.T) {
 	mockTime := &utils.MockClock{CurrTime: time.Now()}
 	utils.CurrentTime = mockTime
 
-	eviction := NewPrimitiveEvictionStrategy(10, 0.4)
-	s := NewStore(nil, eviction)
+	eviction := NewPrimitiveEvictionStrategy(10, 0)
+	s := NewStore(nil, eviction, 0)
 
 	// Add keys with varying LastAccessedAt
 	keyIDs := []int{0, 7, 1, 9, 4, 6, 5, 2, 8, 3, 10}
@@ -71,16 +79,17 @@ func TestEvictVictims_EvictsLRU(t *testing.T) {
 		s.Put(key, obj)
 	}
 
-	// Expected to evict 4 keys with lowest LastAccessedAt, i.e. the first 4 keys added to the store
-	evictedKeys := []string{"key0", "key7", "key1", "key9"} // Indices correspond to accessTimes
-
-	remainingKeyCount := s.GetKeyCount()
-	assert.Equal(t, 7, remainingKeyCount, "Should have seven, 6(Post Eviction) + 1(new key added post eviction) keys remaining after eviction")
+	// The keys with the lowest LastAccessedAt are evicted, i.e. the first keys added to the store
+	target := evictionTarget(10)
+	assert.Equal(t, target+1, s.GetKeyCount(), "Should have the keys left post eviction and the new key added post eviction")
 
-	// Verify that evicted keys are no longer in the store
-	for _, key := range evictedKeys {
-		obj := s.GetNoTouch(key)
-		assert.Nil(t, obj, "Key %s should have been evicted", key)
+	for i, id := range keyIDs[:10] {
+		obj := s.GetNoTouch("key" + strconv.Itoa(id))
+		if i < 10-target {
+			assert.Nil(t, obj, "Key %d should have been evicted", id)
+		} else {
+			assert.NotNil(t, obj, "Key %d should remain after eviction", id)
+		}
 	}
 }
 
@@ -88,8 +97,8 @@ func TestEvictVictims_IdenticalLastAccessedAt(t *testing.T) {
 	currentTime := time.Now()
 	mockTime := &utils.MockClock{CurrTime: currentTime}
 	utils.CurrentTime = mockTime
-	eviction := NewPrimitiveEvictionStrategy(10, 0.5)
-	s := NewStore(nil, eviction)
+	eviction := NewPrimitiveEvictionStrategy(10, 0)
+	s := NewStore(nil, eviction, 0)
 
 	// Add 10 keys with identical LastAccessedAt
 	for i := 0; i <= 10; i++ {
@@ -99,28 +108,23 @@ func TestEvictVictims_IdenticalLastAccessedAt(t *testing.T) {
 		s.Put(key, obj)
 	}
 
-	expectedRemainingKeys := 6 // 5(Post Eviction) + 1 (key added after eviction)
-	assert.Equal(t, expectedRemainingKeys, s.GetKeyCount(), "Should have evicted 5 keys")
+	expectedRemainingKeys := evictionTarget(10) + 1 // post eviction + the key added after eviction
+	assert.Equal(t, expectedRemainingKeys, s.GetKeyCount(), "Should have evicted the keys in excess")
 }
 
 func TestEvictVictims_EvictsAtLeastOne(t *testing.T) {
-	eviction := NewPrimitiveEvictionStrategy(10, 0.000) // 0% eviction rate
-	s := NewStore(nil, eviction)
-
-	// Add 10 keys (equals maxKeys)
-	for i := 0; i < 10; i++ {
-		key := "key" + strconv.Itoa(i)
-		obj := &object.Obj{}
-		s.Put(key, obj)
-	}
+	// A single key is left by the eviction ratio, hence none would be evicted.
+	eviction := NewPrimitiveEvictionStrategy(1, 0)
+	s := NewStore(nil, eviction, 0)
+	s.Put("key0", &object.Obj{})
 
 	toEvict := eviction.ShouldEvict(s)
 	assert.Equal(t, 1, toEvict, "Should evict at least one key")
 }
 
 func TestEvictVictims_EmptyStore(t *testing.T) { // Handles Empty Store Gracefully
-	eviction := NewPrimitiveEvictionStrategy(5)
-	s := NewStore(nil, eviction)
+	eviction := NewPrimitiveEvictionStrategy(5, 0)
+	s := NewStore(nil, eviction, 0)
 
 	toEvict := eviction.ShouldEvict(s)
 	assert.Equal(t, 0, toEvict, "Should not evict any keys when store is empty")
@@ -133,8 +137,8 @@ func TestEvictVictims_LastAccessedAtUpdated(t *testing.T) {
 	currentTime := time.Now()
 	mockTime := &utils.MockClock{CurrTime: currentTime}
 	utils.CurrentTime = mockTime
-	eviction := NewPrimitiveEvictionStrategy(10, 0.4)
-	s := NewStore(nil, eviction)
+	eviction := NewPrimitiveEvictionStrategy(10, 0)
+	s := NewStore(nil, eviction, 0)
 
 	// Add keys with initial LastAccessedAt
 	for i := 1; i <= 10; i++ {
@@ -163,10 +167,10 @@ func TestEvictVictims_LastAccessedAtUpdated(t *testing.T) {
 	unaccessedKeys := []string{"key1", "key9"}
 	for _, key := range unaccessedKeys {
 		obj := s.GetNoTouch(key)
-		assert.Nil(t, obj, "Key %s should remain after eviction", key)
+		assert.Nil(t, obj, "Key %s should have been evicted", key)
 	}
 
-	// Verify that some of the accessed keys were evicted
+	// Verify that the accessed keys were evicted but the most recently accessed ones
 	numRemovedKeys := 0
 	for _, key := range accessedKeys {
 		obj := s.GetNoTouch(key)
@@ -175,5 +179,5 @@ func TestEvictVictims_LastAccessedAtUpdated(t *testing.T) {
 		}
 	}
 
-	assert.True(t, numRemovedKeys == 2, "2 accessed key should have been evicted")
+	assert.Equal(t, len(accessedKeys)-evictionTarget(10), numRemovedKeys, "The least recently accessed keys should have been evicted")
 }
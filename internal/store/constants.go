@@ -18,6 +18,7 @@ const (
 	PFMERGE          string = "PFMERGE"
 	KEYSPERSHARD     string = "KEYSPERSHARD"
 	Evict            string = "EVICT"
+	Expired          string = "EXPIRED"
 	SingleShardSize  string = "SINGLEDBSIZE"
 	SingleShardTouch string = "SINGLETOUCH"
 	SingleShardKeys  string = "SINGLEKEYS"
@@ -4,6 +4,9 @@
 package store
 
 import (
+	"math"
+	"math/rand"
+	"sync/atomic"
 	"time"
 
 	"github.com/dicedb/dice/internal/object"
@@ -52,6 +55,10 @@ type EvictionStrategy interface {
 	// Returns the number of items that should be evicted, or 0 if no eviction is needed
 	ShouldEvict(store *Store) int
 
+	// ShouldEvictBytes checks if eviction should be triggered for the store to grow by the given number of bytes
+	// Returns the number of items that should be evicted, or 0 if no eviction is needed
+	ShouldEvictBytes(store *Store, grow int64) int
+
 	// EvictVictims evicts items from the store based on the eviction strategy
 	EvictVictims(store *Store, toEvict int)
 
@@ -64,6 +71,39 @@ type EvictionStrategy interface {
 	OnAccess(key string, obj *object.Obj, accessType AccessType)
 }
 
+// WriteRejecter is implemented by the strategies rejecting the writes growing
+// the store once it is full, rather than evicting keys.
+type WriteRejecter interface {
+	// RejectWrites returns true if the writes growing the store are to be rejected.
+	RejectWrites(store *Store) bool
+}
+
+// keysToEvictForBytes returns the number of keys to evict for the store to grow by
+// the given number of bytes within maxBytes, estimated from the average size of
+// the keys of the store. The store may be over maxBytes before growing, given
+// that the writes evict a bounded number of keys.
+func keysToEvictForBytes(store *Store, maxBytes, grow int64) int {
+	keyCount := store.GetKeyCount()
+	if maxBytes <= 0 || keyCount == 0 {
+		return 0
+	}
+
+	used := store.UsedMemory()
+	over := used + grow - maxBytes
+	if over <= 0 {
+		return 0
+	}
+
+	avgSize := max(used/int64(keyCount), 1)
+	return int(min((over+avgSize-1)/avgSize, int64(keyCount)))
+}
+
+// isFull returns true if the store holds maxKeys keys or uses maxBytes bytes, 0 for no limit.
+func isFull(store *Store, maxKeys int, maxBytes int64) bool {
+	return (maxKeys > 0 && store.GetKeyCount() >= maxKeys) ||
+		(maxBytes > 0 && store.UsedMemory() >= maxBytes)
+}
+
 // BaseEvictionStrategy provides common functionality for all eviction strategies
 type BaseEvictionStrategy struct {
 	stats EvictionStats
@@ -77,15 +117,87 @@ func (b *BaseEvictionStrategy) GetStats() EvictionStats {
 	return b.stats
 }
 
+// The LastAccessedAt of an object holds the time of its last access, in seconds,
+// in its low 24 bits, and the LFU access counter of the object in its high 8 bits.
+const (
+	clockMask = 0x00FFFFFF
+	lfuShift  = 24
+
+	// LFUInitVal is the access counter of a new key, such that new keys are
+	// not evicted before they get a chance to be accessed.
+	LFUInitVal = 5
+)
+
 func getCurrentClock() uint32 {
-	return uint32(utils.GetCurrentTime().Unix()) & 0x00FFFFFF
+	return uint32(utils.GetCurrentTime().Unix()) & clockMask
 }
 
 func GetIdleTime(lastAccessedAt uint32) uint32 {
 	c := getCurrentClock()
-	lastAccessedAt &= 0x00FFFFFF
+	lastAccessedAt &= clockMask
 	if c >= lastAccessedAt {
 		return c - lastAccessedAt
 	}
-	return (0x00FFFFFF - lastAccessedAt) + c
+	return (clockMask - lastAccessedAt) + c
+}
+
+// LastAccessedAt returns the LastAccessedAt of the object. The reads update it
+// while holding only the read lock of the shard, hence it is read atomically.
+func LastAccessedAt(obj *object.Obj) uint32 {
+	return atomic.LoadUint32(&obj.LastAccessedAt)
+}
+
+// updateAccess sets the LastAccessedAt of the object to f of its current
+// value, retrying if a concurrent read of the object updates it meanwhile.
+func updateAccess(obj *object.Obj, f func(lastAccessedAt uint32) uint32) {
+	for {
+		old := atomic.LoadUint32(&obj.LastAccessedAt)
+		if atomic.CompareAndSwapUint32(&obj.LastAccessedAt, old, f(old)) {
+			return
+		}
+	}
+}
+
+// touchObj sets the time of the last access of the object to now, keeping its access counter.
+func touchObj(obj *object.Obj) {
+	updateAccess(obj, func(lastAccessedAt uint32) uint32 {
+		return lastAccessedAt&^clockMask | getCurrentClock()
+	})
+}
+
+// GetLFUCounter returns the access counter of the object with the given LastAccessedAt.
+func GetLFUCounter(lastAccessedAt uint32) uint8 {
+	return uint8(lastAccessedAt >> lfuShift)
+}
+
+// withLFUCounter returns the LastAccessedAt with its access counter set to counter.
+func withLFUCounter(lastAccessedAt uint32, counter uint8) uint32 {
+	return lastAccessedAt&clockMask | uint32(counter)<<lfuShift
+}
+
+// lfuDecayedCounter returns the access counter of the given LastAccessedAt
+// decremented once for every decayTime minutes the object has been idle for.
+func lfuDecayedCounter(lastAccessedAt uint32, decayTime int) uint8 {
+	counter := GetLFUCounter(lastAccessedAt)
+	if decayTime <= 0 {
+		return counter
+	}
+	periods := GetIdleTime(lastAccessedAt) / 60 / uint32(decayTime)
+	if periods >= uint32(counter) {
+		return 0
+	}
+	return counter - uint8(periods)
+}
+
+// lfuLogIncr increments the access counter with a probability decreasing as the
+// counter grows, such that the 8 bits of the counter cover millions of accesses.
+func lfuLogIncr(counter uint8, logFactor int) uint8 {
+	if counter == math.MaxUint8 {
+		return counter
+	}
+	baseval := max(float64(counter)-LFUInitVal, 0)
+	if rand.Float64() < 1/(baseval*float64(logFactor)+1) {
+		counter++
+	}
+	return counter
 }
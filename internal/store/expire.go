@@ -4,7 +4,10 @@
 package store
 
 import (
+	"math"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	diceerrors "github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/object"
@@ -35,44 +38,74 @@ func DelExpiry(obj *object.Obj, store *Store) {
 	store.expires.Delete(obj)
 }
 
-// TODO: Optimize
-//   - Sampling
-//   - Unnecessary iteration
-func expireSample(store *Store) float32 {
-	var limit = 20
-	var expiredCount = 0
-	var keysToDelete []string
-
-	// Collect keys to be deleted
-	store.store.All(func(keyPtr string, obj *object.Obj) bool {
-		limit--
-		if hasExpired(obj, store) {
-			keysToDelete = append(keysToDelete, keyPtr)
-			expiredCount++
-		}
-		// once we iterated to 20 keys that have some expiration set
-		// we break the loop
-		return limit >= 0
-	})
-
-	// Delete the keys outside the read lock
-	for _, keyPtr := range keysToDelete {
-		store.DelByPtr(keyPtr, WithDelCmd(Del))
+// expiryStats are the counters of the expired keys of a store.
+type expiryStats struct {
+	// expiredKeys is the number of keys expired, actively or passively.
+	expiredKeys atomic.Uint64
+	// expiredPerSec is the rate of expiry over the last cron interval, as
+	// float64 bits, computed from the count and the time of the last run.
+	expiredPerSec atomic.Uint64
+	lastCount     uint64
+	lastRunAt     time.Time
+}
+
+// ExpiryStats are the statistics of the keys with a TTL of a store.
+type ExpiryStats struct {
+	// Keys is the number of keys with a TTL.
+	Keys int
+	// AvgTTLMs is the average time left before the keys with a TTL expire, in milliseconds.
+	AvgTTLMs uint64
+	// Expired is the number of keys expired since the store was created.
+	Expired uint64
+	// ExpiredPerSec is the number of keys expired per second, as of the last shard cron run.
+	ExpiredPerSec float64
+}
+
+// ExpiryStats returns the statistics of the keys with a TTL of the store.
+func (store *Store) ExpiryStats() ExpiryStats {
+	return ExpiryStats{
+		Keys:          store.expires.Len(),
+		AvgTTLMs:      store.expires.avgTTL(uint64(utils.GetCurrentTime().UnixMilli())),
+		Expired:       store.expiredKeys.Load(),
+		ExpiredPerSec: math.Float64frombits(store.expiredPerSec.Load()),
 	}
+}
 
-	return float32(expiredCount) / float32(20.0)
+// updateExpiryRate computes the rate of expiry since its previous call.
+func (s *expiryStats) updateExpiryRate() {
+	now := time.Now()
+	count := s.expiredKeys.Load()
+	if !s.lastRunAt.IsZero() {
+		if elapsed := now.Sub(s.lastRunAt).Seconds(); elapsed > 0 {
+			s.expiredPerSec.Store(math.Float64bits(float64(count-s.lastCount) / elapsed))
+		}
+	}
+	s.lastCount, s.lastRunAt = count, now
 }
 
-// DeleteExpiredKeys deletes all the expired keys - the active way
-func DeleteExpiredKeys(store *Store) {
-	for {
-		frac := expireSample(store)
-		// if the sample had less than 25% keys expired
-		// we break the loop.
-		if frac < 0.25 {
+// DeleteExpiredKeys deletes the expired keys in the order of their deadlines -
+// the active way - until none is left or the budget is spent. The keys are
+// found through the expiry index, without a scan of the keyspace. The caller
+// holds the write lock of the shard of the store.
+func DeleteExpiredKeys(store *Store, budget time.Duration) {
+	start := time.Now()
+	now := uint64(utils.GetCurrentTime().UnixMilli())
+	for i := 0; ; i++ {
+		// The clock is read once every few keys, which is costlier than deleting a key.
+		if i%16 == 0 && time.Since(start) >= budget {
 			break
 		}
+		e, ok := store.expires.popExpired(now)
+		if !ok {
+			break
+		}
+		// The objects replaced since are only dropped from the index.
+		if obj, ok := store.store.Get(e.key); ok && obj == e.obj {
+			store.deleteKey(e.key, obj, WithDelCmd(Expired))
+		}
 	}
+	store.expires.dropPending()
+	store.updateExpiryRate()
 }
 
 // NX: Set the expiration only if the key does not already have an expiration time.
@@ -97,7 +130,7 @@ func EvaluateAndSetExpiry(subCommands []string, newExpiry int64, key string,
 
 	// If no sub-command is provided, set the expiry
 	if len(subCommands) == 0 {
-		store.SetUnixTimeExpiry(obj, newExpiry)
+		store.SetUnixTimeExpiry(key, obj, newExpiry)
 		return true, nil
 	}
 
@@ -154,7 +187,7 @@ func EvaluateAndSetExpiry(subCommands []string, newExpiry int64, key string,
 	}
 
 	if shouldSetExpiry {
-		store.SetUnixTimeExpiry(obj, newExpiry)
+		store.SetUnixTimeExpiry(key, obj, newExpiry)
 	}
 	return shouldSetExpiry, nil
 }
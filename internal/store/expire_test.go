@@ -4,16 +4,17 @@
 package store
 
 import (
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/dicedb/dice/internal/object"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestDelExpiry(t *testing.T) {
-	store := NewStore(nil, nil)
-	// Initialize the test environment
-	store.store = NewStoreMap()
-	store.expires = NewExpireMap()
+	store := NewStore(nil, nil, 0)
 
 	// Define test cases
 	tests := []struct {
@@ -57,3 +58,96 @@ func TestDelExpiry(t *testing.T) {
 		})
 	}
 }
+
+func TestWatchEventsExpired(t *testing.T) {
+	events := make(chan *Store, 1)
+	store := NewStore(events, nil, 0)
+	for _, k := range []string{"k1", "k2", "k3"} {
+		store.Put(k, store.NewObj(int64(1), -1, object.ObjTypeInt))
+	}
+	store.Del("k1")
+
+	// Only the changes the store makes on its own are recorded, and
+	// they are coalesced until taken, the store being sent only once.
+	store.Del("k2", WithDelCmd(Expired))
+	store.Del("k3", WithDelCmd(Evict))
+	assert.Equal(t, store, <-events)
+	select {
+	case <-events:
+		t.Fatalf("expected the store to be sent once")
+	default:
+	}
+	assert.Equal(t, map[string]string{"k2": Expired, "k3": Evict}, store.TakeWatchEvents())
+	assert.Nil(t, store.TakeWatchEvents())
+
+	// Once taken, the next change sends the store again.
+	store.Put("k2", store.NewObj(int64(1), -1, object.ObjTypeInt))
+	store.Del("k2", WithDelCmd(Expired))
+	assert.Equal(t, store, <-events)
+	assert.Equal(t, map[string]string{"k2": Expired}, store.TakeWatchEvents())
+}
+
+func TestExpiredKeysOnReads(t *testing.T) {
+	events := make(chan *Store, 1)
+	store := NewStore(events, nil, 0)
+	store.Put("k", store.NewObj(int64(1), 0, object.ObjTypeInt))
+
+	// The reads, holding only the read lock of the shard, never delete
+	// the expired keys, which are left to the shard cron.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, store.Get("k"))
+			assert.Equal(t, []*object.Obj{nil}, store.GetAll([]string{"k"}))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, store.GetKeyCount())
+
+	DeleteExpiredKeys(store, time.Second)
+	assert.Equal(t, 0, store.GetKeyCount())
+	assert.Equal(t, store, <-events)
+	assert.Equal(t, map[string]string{"k": Expired}, store.TakeWatchEvents())
+}
+
+func TestWriteOverExpiredKey(t *testing.T) {
+	store := NewStore(nil, nil, 0)
+	store.Put("k", store.NewObj(int64(1), 0, object.ObjTypeInt))
+
+	// The TTL of the expired key is not kept by the write replacing it.
+	store.Put("k", store.NewObj(int64(2), -1, object.ObjTypeInt), WithKeepTTL(true))
+	obj := store.Get("k")
+	assert.NotNil(t, obj)
+	_, ok := GetExpiry(obj, store)
+	assert.False(t, ok)
+	assert.Equal(t, 1, store.GetKeyCount())
+
+	// Nor is an expired key counted as deleted.
+	store.Put("e", store.NewObj(int64(1), 0, object.ObjTypeInt))
+	assert.False(t, store.Del("e"))
+	assert.Equal(t, 1, store.GetKeyCount())
+}
+
+func TestScanSkipsExpiredKeys(t *testing.T) {
+	for _, table := range []string{TableMap, TableSwiss} {
+		store := NewStore(nil, nil, 0)
+		store.UseTable(table)
+		for i := 0; i < 100; i++ {
+			store.Put("k"+strconv.Itoa(i), store.NewObj(int64(i), -1, object.ObjTypeInt))
+		}
+		store.Put("e", store.NewObj(int64(1), 0, object.ObjTypeInt))
+
+		// Every key but the expired one is scanned once, whatever the table.
+		seen := map[string]int{}
+		for cursor := store.Scan(0, 10, func(k string, _ *object.Obj) { seen[k]++ }); cursor != 0; {
+			cursor = store.Scan(cursor, 10, func(k string, _ *object.Obj) { seen[k]++ })
+		}
+		assert.Len(t, seen, 100, table)
+		assert.NotContains(t, seen, "e", table)
+		for k, n := range seen {
+			assert.Equal(t, 1, n, k)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package store
+
+import (
+	"container/heap"
+	"math/rand"
+	"sync"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+// expiryEntry is the deadline of an object, in milliseconds since the epoch,
+// along with the key the object is stored under.
+type expiryEntry struct {
+	obj      *object.Obj
+	key      string
+	deadline uint64
+	// index is the position of the entry in the heap.
+	index int
+}
+
+// expiryHeap is a min-heap of expiryEntries based on deadline.
+type expiryHeap []*expiryEntry
+
+func (h expiryHeap) Len() int { return len(h) }
+
+func (h expiryHeap) Less(i, j int) bool { return h[i].deadline < h[j].deadline }
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap) Push(x interface{}) {
+	e := x.(*expiryEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
+
+// expiryIndex holds the deadlines of the objects with a TTL, ordered by
+// deadline, such that the expired keys are found without a scan of the
+// keyspace. It implements the methods of common.ITable[*object.Obj, uint64]
+// but Scan, the index being walked in the order of the deadlines instead.
+//
+// The objects given a deadline before being stored are only indexed once
+// stored, see stored, for the objects never stored not to be left in the heap.
+type expiryIndex struct {
+	mu      sync.RWMutex
+	entries map[*object.Obj]*expiryEntry
+	heap    expiryHeap
+	// pending are the deadlines of the objects not stored yet.
+	pending map[*object.Obj]uint64
+	// offsetSum is the sum of the offsets of the deadlines from base, from
+	// which the average TTL is computed without overflowing, base being the
+	// time the index was created at.
+	base      int64
+	offsetSum int64
+}
+
+func newExpiryIndex() *expiryIndex {
+	return &expiryIndex{
+		entries: make(map[*object.Obj]*expiryEntry),
+		pending: make(map[*object.Obj]uint64),
+		base:    utils.GetCurrentTime().UnixMilli(),
+	}
+}
+
+// Put sets the deadline of the stored object, keeping the key it is stored under.
+func (x *expiryIndex) Put(obj *object.Obj, deadline uint64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.put(obj, deadline)
+}
+
+func (x *expiryIndex) put(obj *object.Obj, deadline uint64) {
+	if e, ok := x.entries[obj]; ok {
+		x.offsetSum += int64(deadline) - int64(e.deadline)
+		e.deadline = deadline
+		heap.Fix(&x.heap, e.index)
+		return
+	}
+	e := &expiryEntry{obj: obj, deadline: deadline}
+	x.entries[obj] = e
+	x.offsetSum += int64(deadline) - x.base
+	heap.Push(&x.heap, e)
+}
+
+func (x *expiryIndex) Get(obj *object.Obj) (uint64, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if e, ok := x.entries[obj]; ok {
+		return e.deadline, true
+	}
+	deadline, ok := x.pending[obj]
+	return deadline, ok
+}
+
+func (x *expiryIndex) Delete(obj *object.Obj) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if e, ok := x.entries[obj]; ok {
+		x.remove(e)
+	}
+	delete(x.pending, obj)
+}
+
+func (x *expiryIndex) remove(e *expiryEntry) {
+	heap.Remove(&x.heap, e.index)
+	delete(x.entries, e.obj)
+	x.offsetSum -= int64(e.deadline) - x.base
+}
+
+func (x *expiryIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.entries)
+}
+
+// All calls f with the objects and their deadlines, in no particular order.
+func (x *expiryIndex) All(f func(obj *object.Obj, deadline uint64) bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	for _, e := range x.heap {
+		if !f(e.obj, e.deadline) {
+			break
+		}
+	}
+}
+
+// setKey sets the key the object is stored under, if the object has a deadline.
+func (x *expiryIndex) setKey(obj *object.Obj, key string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if e, ok := x.entries[obj]; ok {
+		e.key = key
+	}
+}
+
+// setPending sets the deadline of the object about to be stored.
+func (x *expiryIndex) setPending(obj *object.Obj, deadline uint64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.pending[obj] = deadline
+}
+
+// stored indexes the object stored under the key, along with the deadline
+// it was given before being stored, if any.
+func (x *expiryIndex) stored(obj *object.Obj, key string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if deadline, ok := x.pending[obj]; ok {
+		delete(x.pending, obj)
+		x.put(obj, deadline)
+	}
+	if e, ok := x.entries[obj]; ok {
+		e.key = key
+	}
+}
+
+// dropPending drops the deadlines of the objects never stored. The objects
+// are stored by the commands creating them, hence the deadlines still
+// pending once the commands are done are dropped.
+func (x *expiryIndex) dropPending() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if len(x.pending) > 0 {
+		x.pending = make(map[*object.Obj]uint64)
+	}
+}
+
+// sample returns copies of n entries picked at random, fewer if the index
+// holds fewer, skipping the entries of the objects not stored under a key.
+func (x *expiryIndex) sample(n int) []expiryEntry {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	entries := make([]expiryEntry, 0, n)
+	for i := 0; i < min(n, len(x.heap)); i++ {
+		e := x.heap[i]
+		if len(x.heap) > n {
+			e = x.heap[rand.Intn(len(x.heap))]
+		}
+		if e.key != "" {
+			entries = append(entries, *e)
+		}
+	}
+	return entries
+}
+
+// popExpired removes the entry with the earliest deadline from the index
+// and returns it, if its deadline is not after now.
+func (x *expiryIndex) popExpired(now uint64) (*expiryEntry, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if len(x.heap) == 0 || x.heap[0].deadline > now {
+		return nil, false
+	}
+	e := x.heap[0]
+	x.remove(e)
+	return e, true
+}
+
+// avgTTL returns the average time left before the deadlines, in milliseconds.
+func (x *expiryIndex) avgTTL(now uint64) uint64 {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if len(x.entries) == 0 {
+		return 0
+	}
+	avg := x.base + x.offsetSum/int64(len(x.entries))
+	if avg <= int64(now) {
+		return 0
+	}
+	return uint64(avg) - now
+}
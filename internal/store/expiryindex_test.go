@@ -0,0 +1,91 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package store
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiryIndexOrder(t *testing.T) {
+	x := newExpiryIndex()
+	objs := make([]*object.Obj, 5)
+	for i, deadline := range []uint64{50, 10, 40, 20, 30} {
+		objs[i] = &object.Obj{}
+		x.Put(objs[i], deadline)
+		x.setKey(objs[i], "key"+strconv.Itoa(i))
+	}
+	// Updating and deleting entries keeps them ordered.
+	x.Put(objs[0], 5)
+	x.Delete(objs[3])
+
+	var keys []string
+	for {
+		e, ok := x.popExpired(40)
+		if !ok {
+			break
+		}
+		keys = append(keys, e.key)
+	}
+	assert.Equal(t, []string{"key0", "key1", "key4", "key2"}, keys)
+	assert.Equal(t, 0, x.Len())
+}
+
+func TestDeleteExpiredKeys(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Now()}
+	utils.CurrentTime = mockTime
+	s := NewStore(nil, nil, 0)
+
+	for i := 0; i < 10; i++ {
+		s.Put("key"+strconv.Itoa(i), s.NewObj("v", int64(i+1)*1000, object.ObjTypeString))
+	}
+	s.Put("persistent", s.NewObj("v", -1, object.ObjTypeString))
+	// The TTL of a key set once stored is indexed under the key.
+	s.SetKeyExpiry("persistent", s.GetNoTouch("persistent"), 20000)
+	assert.InDelta(t, (55000+20000)/11, s.ExpiryStats().AvgTTLMs, 1)
+
+	mockTime.SetTime(mockTime.GetTime().Add(5500 * time.Millisecond))
+	DeleteExpiredKeys(s, time.Second)
+
+	stats := s.ExpiryStats()
+	assert.Equal(t, 6, s.GetKeyCount())
+	assert.Equal(t, 6, stats.Keys)
+	assert.Equal(t, uint64(5), stats.Expired)
+	assert.Nil(t, s.GetNoTouch("key4"))
+	assert.NotNil(t, s.GetNoTouch("key5"))
+
+	mockTime.SetTime(mockTime.GetTime().Add(20 * time.Second))
+	DeleteExpiredKeys(s, time.Second)
+	assert.Equal(t, 0, s.GetKeyCount())
+}
+
+func TestExpiryIndexStoredObjects(t *testing.T) {
+	s := NewStore(nil, nil, 0)
+
+	// The objects given a TTL are indexed only once stored.
+	unstored := s.NewObj("v", 1000, object.ObjTypeString)
+	obj := s.NewObj("v", 1000, object.ObjTypeString)
+	assert.Equal(t, 0, s.ExpiryStats().Keys)
+	s.Put("k", obj)
+	assert.Equal(t, 1, s.ExpiryStats().Keys)
+
+	// The deadlines of the objects never stored are dropped by the cron.
+	DeleteExpiredKeys(s, time.Second)
+	_, ok := GetExpiry(unstored, s)
+	assert.False(t, ok)
+	assert.Equal(t, 1, s.ExpiryStats().Keys)
+
+	// The overwritten and the deleted keys leave the index.
+	s.Put("k", s.NewObj("v", -1, object.ObjTypeString))
+	assert.Equal(t, 0, s.ExpiryStats().Keys)
+	s.Put("k", s.NewObj("v", 1000, object.ObjTypeString))
+	s.Del("k")
+	assert.Equal(t, 0, s.ExpiryStats().Keys)
+	assert.Empty(t, s.expires.heap)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package store
+
+import "sync/atomic"
+
+// KeyLimit is the limit of the number of keys of the stores of all the
+// shards together. Every store has a soft limit of its share of the keys:
+// once the stores hold the maximum number of keys, only the stores holding
+// more than their share evict keys, such that a store is never made to
+// evict by the keys of the others. The stores under their share keep taking
+// new keys, the stores over theirs evicting the keys in excess on their next
+// write or the next run of the shard cron.
+//
+// The limit is hence a soft one: a store under its share takes new keys even
+// once used >= max, unless the writes are rejected as per noeviction, and the
+// stores over their share catch up only on their next write or cron run, such
+// that the keys of all the stores together may exceed max for a while.
+type KeyLimit struct {
+	max  int64
+	soft int64
+	used atomic.Int64
+}
+
+// KeyLimitStats are the limit and the usage of the keys of a store.
+type KeyLimitStats struct {
+	// Max is the maximum number of keys of all the stores, 0 for no limit.
+	Max int64
+	// Used is the number of keys of all the stores.
+	Used int64
+	// Soft is the share of the keys of the store.
+	Soft int64
+}
+
+// NewKeyLimit returns the limit of maxKeys keys, 0 for no limit, shared by
+// the given number of stores.
+func NewKeyLimit(maxKeys, stores int) *KeyLimit {
+	return &KeyLimit{
+		max:  int64(max(maxKeys, 0)),
+		soft: int64(max(maxKeys, 0) / max(stores, 1)),
+	}
+}
+
+func (l *KeyLimit) add(n int) {
+	if l != nil {
+		l.used.Add(int64(n))
+	}
+}
+
+// excess returns the number of keys to evict by a store holding n keys for
+// it to add grow new keys, which is 0 unless the stores are full and the
+// store holds more than its share of the keys once grown.
+func (l *KeyLimit) excess(n, grow int) int {
+	if l == nil || l.max == 0 {
+		return 0
+	}
+	over := l.used.Load() + int64(grow) - l.max
+	if over <= 0 || int64(n+grow) <= l.soft {
+		return 0
+	}
+	return int(min(over, int64(n+grow)-l.soft))
+}
+
+// full returns true if the stores hold the maximum number of keys.
+func (l *KeyLimit) full() bool {
+	return l != nil && l.max > 0 && l.used.Load() >= l.max
+}
+
+// UseKeyLimit makes the store share the key limit with the other stores
+// sharing it, the keys the store holds being accounted for.
+func (store *Store) UseKeyLimit(l *KeyLimit) {
+	store.keyLimit.add(-store.numKeys)
+	store.keyLimit = l
+	store.keyLimit.add(store.numKeys)
+}
+
+// KeyLimitStats returns the key limit of the store and the usage of it,
+// and false if the store has no key limit.
+func (store *Store) KeyLimitStats() (KeyLimitStats, bool) {
+	l := store.keyLimit
+	if l == nil {
+		return KeyLimitStats{}, false
+	}
+	return KeyLimitStats{Max: l.max, Used: l.used.Load(), Soft: l.soft}, true
+}
+
+// KeyLimitReached returns true if the stores sharing the key limit of the
+// store hold the maximum number of keys.
+func (store *Store) KeyLimitReached() bool {
+	return store.keyLimit.full()
+}
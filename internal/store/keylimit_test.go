@@ -0,0 +1,62 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package store
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyLimit(t *testing.T) {
+	l := NewKeyLimit(10, 2)
+	s1, s2 := newSampledStore(PolicyAllKeysLRU, 0), newSampledStore(PolicyAllKeysLRU, 0)
+	s1.UseKeyLimit(l)
+	s2.UseKeyLimit(l)
+
+	// A store exceeds its share while the stores together are under the limit.
+	for i := 0; i < 12; i++ {
+		s1.Put("key"+strconv.Itoa(i), s1.NewObj("v", -1, object.ObjTypeString))
+	}
+	assert.Equal(t, 10, s1.GetKeyCount())
+	assert.True(t, s1.KeyLimitReached())
+
+	// The store under its share takes new keys without evicting, the store
+	// over its share evicting the keys in excess.
+	for i := 0; i < 3; i++ {
+		s2.Put("key"+strconv.Itoa(i), s2.NewObj("v", -1, object.ObjTypeString))
+	}
+	assert.Equal(t, 3, s2.GetKeyCount())
+	s1.EvictExcess(time.Second)
+	assert.Equal(t, 7, s1.GetKeyCount())
+
+	stats, ok := s1.KeyLimitStats()
+	assert.True(t, ok)
+	assert.Equal(t, KeyLimitStats{Max: 10, Used: 10, Soft: 5}, stats)
+
+	Reset(s1)
+	stats, _ = s2.KeyLimitStats()
+	assert.Equal(t, int64(3), stats.Used)
+}
+
+func TestKeyLimitNoEviction(t *testing.T) {
+	l := NewKeyLimit(4, 2)
+	s1, s2 := NewStore(nil, NewNoEvictionStrategy(0, 0), 0), NewStore(nil, NewNoEvictionStrategy(0, 0), 0)
+	s1.UseKeyLimit(l)
+	s2.UseKeyLimit(l)
+
+	for i := 0; i < 4; i++ {
+		s1.Put("key"+strconv.Itoa(i), s1.NewObj("v", -1, object.ObjTypeString))
+	}
+	// The writes are rejected by all the stores once the limit is reached,
+	// whatever their share of the keys.
+	assert.True(t, s2.RejectsWrites())
+	assert.True(t, s2.KeyLimitReached())
+
+	s1.Del("key0")
+	assert.False(t, s2.RejectsWrites())
+}
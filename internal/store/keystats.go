@@ -0,0 +1,259 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package store
+
+import (
+	"cmp"
+	"hash/maphash"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/dicedb/dice/internal/object"
+)
+
+// The hot keys are the keys of the highest access frequencies, estimated by a
+// Count-Min Sketch of the sampled accesses to the keys of the store. It is the
+// sketch of eval/countminsketch.go, which the store cannot import, made compact
+// for every access to afford it: the counters are held in a single array, the
+// positions of a key are derived from a single hash of it, and the counters
+// are halved every so often for the estimates to be the ones of the recent
+// accesses. The keys of the highest estimates are kept along the way, hence
+// HOTKEYS reads them without scanning the keys of the store. The same goes
+// for the largest keys of every type, kept as the keys are written.
+const (
+	hotKeysDepth = 4
+	hotKeysWidth = 1 << 12
+	// hotKeysAgeSamples is the number of samples after which the counters
+	// of the sketch and the estimates of the hot keys are halved.
+	hotKeysAgeSamples = 10 * hotKeysWidth
+	// hotKeysTracked is the number of the hot keys kept by a store.
+	hotKeysTracked = 64
+	// bigKeysTracked is the number of the largest keys of every type kept
+	// by a store.
+	bigKeysTracked = 16
+)
+
+// HotKey is a key of the store along with its estimated number of recent accesses.
+type HotKey struct {
+	Key       string
+	Frequency uint64
+}
+
+// BigKey is a key of the store along with the type and the size of its value.
+type BigKey struct {
+	Key  string
+	Type object.ObjectType
+	Size uint64
+}
+
+// topKeys are the keys of the highest values among the ones offered, up to a
+// number of keys, the keys offered with lower values than all of them being
+// left out.
+type topKeys struct {
+	values map[string]uint64
+	size   int
+	// floor is a lower bound of the values kept, for the keys offered with
+	// a lower value to be left out without looking for the lowest value.
+	floor uint64
+}
+
+func newTopKeys(size int) *topKeys {
+	return &topKeys{values: make(map[string]uint64, size), size: size}
+}
+
+// offer sets the value of the key if the key is kept, or if its value is
+// higher than the lowest value kept, in which case it replaces the key of the
+// lowest value.
+func (t *topKeys) offer(k string, v uint64) {
+	if _, ok := t.values[k]; ok || len(t.values) < t.size {
+		t.values[k] = v
+		t.floor = min(t.floor, v)
+		return
+	}
+	if v <= t.floor {
+		return
+	}
+
+	lowestKey, lowest, second := "", uint64(math.MaxUint64), uint64(math.MaxUint64)
+	for key, value := range t.values {
+		if value < lowest {
+			lowestKey, lowest, second = key, value, lowest
+		} else if value < second {
+			second = value
+		}
+	}
+	if v <= lowest {
+		t.floor = lowest
+		return
+	}
+	delete(t.values, lowestKey)
+	t.values[k] = v
+	t.floor = min(v, second)
+}
+
+func (t *topKeys) remove(k string) {
+	delete(t.values, k)
+}
+
+// halve halves the values, the keys left with no value being removed.
+func (t *topKeys) halve() {
+	for k, v := range t.values {
+		if v/2 == 0 {
+			delete(t.values, k)
+		} else {
+			t.values[k] = v / 2
+		}
+	}
+	t.floor /= 2
+}
+
+// top calls f with the keys of the n highest values, in decreasing order.
+func (t *topKeys) top(n int, f func(k string, v uint64)) {
+	keys := make([]string, 0, len(t.values))
+	for k := range t.values {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Compare(t.values[b], t.values[a])
+	})
+	for _, k := range keys[:min(n, len(keys))] {
+		f(k, t.values[k])
+	}
+}
+
+// hotKeys is the sketch of the sampled accesses to the keys of a store and
+// the keys of the highest estimates.
+type hotKeys struct {
+	sampleRate int
+	seed       maphash.Seed
+
+	// mu guards the sketch and the keys of the highest estimates, the reads
+	// sampling the accesses concurrently under the read lock of the shard.
+	mu       sync.Mutex
+	counters [hotKeysDepth * hotKeysWidth]uint32
+	samples  int
+	top      *topKeys
+}
+
+func newHotKeys(sampleRate int) *hotKeys {
+	return &hotKeys{sampleRate: sampleRate, seed: maphash.MakeSeed(), top: newTopKeys(hotKeysTracked)}
+}
+
+// sample counts one in sampleRate accesses to the key.
+func (h *hotKeys) sample(k string) {
+	if h == nil || (h.sampleRate > 1 && rand.IntN(h.sampleRate) != 0) {
+		return
+	}
+
+	hash := maphash.String(h.seed, k)
+	h1, h2 := uint32(hash), uint32(hash>>32)|1
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	estimate := uint32(math.MaxUint32)
+	for row := uint32(0); row < hotKeysDepth; row++ {
+		c := &h.counters[row*hotKeysWidth+(h1+h2*row)%hotKeysWidth]
+		if *c < math.MaxUint32 {
+			*c++
+		}
+		estimate = min(estimate, *c)
+	}
+	h.top.offer(k, uint64(estimate))
+
+	if h.samples++; h.samples >= hotKeysAgeSamples {
+		for i := range h.counters {
+			h.counters[i] /= 2
+		}
+		h.top.halve()
+		h.samples = 0
+	}
+}
+
+func (h *hotKeys) remove(k string) {
+	if h != nil {
+		h.mu.Lock()
+		h.top.remove(k)
+		h.mu.Unlock()
+	}
+}
+
+// hottest calls f with the n keys of the highest estimates, in decreasing order.
+func (h *hotKeys) hottest(n int, f func(k string, v uint64)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.top.top(n, f)
+}
+
+// TrackHotKeys makes the store sample one in sampleRate accesses to its keys
+// to estimate the hot keys, 0 not to track the hot keys.
+func (store *Store) TrackHotKeys(sampleRate int) {
+	store.hotKeys = nil
+	if sampleRate > 0 {
+		store.hotKeys = newHotKeys(sampleRate)
+	}
+}
+
+// HotKeys returns the n keys of the store accessed the most recently, by
+// decreasing estimated number of accesses, and false if the hot keys are not
+// tracked.
+func (store *Store) HotKeys(n int) ([]HotKey, bool) {
+	if store.hotKeys == nil {
+		return nil, false
+	}
+	keys := make([]HotKey, 0, n)
+	store.hotKeys.hottest(n, func(k string, v uint64) {
+		keys = append(keys, HotKey{Key: k, Frequency: v * uint64(store.hotKeys.sampleRate)})
+	})
+	return keys, true
+}
+
+// trackBigKey keeps the key if its value is among the largest of its type.
+func (store *Store) trackBigKey(k string, obj *object.Obj) {
+	if store.bigKeys == nil {
+		store.bigKeys = make(map[object.ObjectType]*topKeys)
+	}
+	t, ok := store.bigKeys[obj.Type]
+	if !ok {
+		t = newTopKeys(bigKeysTracked)
+		store.bigKeys[obj.Type] = t
+	}
+	t.offer(k, uint64(obj.Size))
+}
+
+// untrackBigKey forgets the key of the object among the largest of its type.
+func (store *Store) untrackBigKey(k string, obj *object.Obj) {
+	if t, ok := store.bigKeys[obj.Type]; ok {
+		t.remove(k)
+	}
+}
+
+// untrackKey forgets the key of the object deleted from the store.
+func (store *Store) untrackKey(k string, obj *object.Obj) {
+	store.hotKeys.remove(k)
+	store.untrackBigKey(k, obj)
+}
+
+// resetKeyStats forgets the hot keys and the largest keys of the store,
+// keeping the hot keys tracked if they are.
+func (store *Store) resetKeyStats() {
+	store.bigKeys = nil
+	if store.hotKeys != nil {
+		store.hotKeys = newHotKeys(store.hotKeys.sampleRate)
+	}
+}
+
+// BigKeys returns the n largest keys of every type of the store, by decreasing
+// size. The keys are the largest among the ones written since the store was
+// reset, a large key deleted leaving room for the keys written afterwards.
+func (store *Store) BigKeys(n int) []BigKey {
+	var keys []BigKey
+	for typ, t := range store.bigKeys {
+		t.top(n, func(k string, v uint64) {
+			keys = append(keys, BigKey{Key: k, Type: typ, Size: v})
+		})
+	}
+	return keys
+}
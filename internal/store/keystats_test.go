@@ -0,0 +1,106 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package store
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHotKeys(t *testing.T) {
+	s := NewStore(nil, nil, 0)
+	_, ok := s.HotKeys(1)
+	assert.False(t, ok)
+
+	s.TrackHotKeys(1)
+	for i := 0; i < 1000; i++ {
+		s.Put("key"+strconv.Itoa(i), s.NewObj("v", -1, object.ObjTypeString))
+	}
+	// The reads of the keys missing are not counted.
+	for i := 0; i < 100; i++ {
+		s.Get("hot")
+		s.Get("key1")
+		s.Get("key2")
+		s.Get("key2")
+	}
+	s.Put("hot", s.NewObj("v", -1, object.ObjTypeString))
+	for i := 0; i < 150; i++ {
+		s.Get("hot")
+	}
+
+	keys, ok := s.HotKeys(3)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"key2", "hot", "key1"}, []string{keys[0].Key, keys[1].Key, keys[2].Key})
+	assert.GreaterOrEqual(t, keys[0].Frequency, uint64(200))
+
+	// The keys deleted are no longer hot.
+	s.Del("key2")
+	keys, _ = s.HotKeys(1)
+	assert.Equal(t, "hot", keys[0].Key)
+
+	Reset(s)
+	keys, ok = s.HotKeys(1)
+	assert.True(t, ok)
+	assert.Empty(t, keys)
+}
+
+func TestBigKeys(t *testing.T) {
+	s := NewStore(nil, nil, 0)
+	for i := 0; i < 100; i++ {
+		s.Put("key"+strconv.Itoa(i), s.NewObj(strings.Repeat("v", i), -1, object.ObjTypeString))
+	}
+	s.Put("int", s.NewObj(int64(1), -1, object.ObjTypeInt))
+
+	keys := s.BigKeys(2)
+	assert.Len(t, keys, 3)
+	sizes := map[string]uint64{}
+	for _, k := range keys {
+		sizes[k.Key] = k.Size
+	}
+	assert.Equal(t, uint64(s.GetNoTouch("key99").Size), sizes["key99"])
+	assert.Contains(t, sizes, "key98")
+	assert.Contains(t, sizes, "int")
+
+	// The keys deleted, or of which the type changed, leave room for the
+	// keys written afterwards.
+	s.Del("key99")
+	s.Put("key98", s.NewObj(int64(98), -1, object.ObjTypeInt))
+	s.Put("key50", s.NewObj(strings.Repeat("v", 50), -1, object.ObjTypeString))
+	var strs []string
+	for _, k := range s.BigKeys(bigKeysTracked) {
+		if k.Type == object.ObjTypeString {
+			strs = append(strs, k.Key)
+		}
+	}
+	assert.NotContains(t, strs, "key99")
+	assert.NotContains(t, strs, "key98")
+	assert.Equal(t, "key97", strs[0])
+}
+
+func TestHotKeysConcurrentSamples(t *testing.T) {
+	h := newHotKeys(1)
+
+	// The reads sample the accesses concurrently, under the read lock of the shard.
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				h.sample("key" + strconv.Itoa(i%100))
+				h.hottest(3, func(string, uint64) {})
+			}
+		}()
+	}
+	wg.Wait()
+
+	n := 0
+	h.hottest(3, func(string, uint64) { n++ })
+	assert.Equal(t, 3, n)
+}
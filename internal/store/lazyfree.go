@@ -0,0 +1,151 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package store
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/common"
+	"github.com/dicedb/dice/internal/object"
+)
+
+// ChunkFreer is implemented by the values holding many elements, which are
+// released a chunk at a time once the value is detached from the keyspace,
+// instead of all at once on the command path.
+type ChunkFreer interface {
+	// Len returns the number of elements left in the value.
+	Len() int
+	// FreeChunk releases at most n elements of the value, and returns
+	// true once the value holds no element.
+	FreeChunk(n int) bool
+}
+
+// lazyFreeJob is a value detached from the keyspace, along with its
+// estimated size and the counters of the store it was detached from.
+type lazyFreeJob struct {
+	v     ChunkFreer
+	size  int64
+	stats *lazyFreeStats
+}
+
+// lazyFreeStats are the counters of the values of a store freed in the background.
+type lazyFreeStats struct {
+	// pendingBytes and pendingObjects are the values detached from
+	// the keyspace and not freed yet.
+	pendingBytes   atomic.Int64
+	pendingObjects atomic.Int64
+	// freedObjects is the number of values freed in the background.
+	freedObjects atomic.Uint64
+}
+
+// LazyFreeStats are the statistics of the values of a store freed in the background.
+type LazyFreeStats struct {
+	// PendingBytes is the estimated size of the values not freed yet.
+	PendingBytes int64
+	// PendingObjects is the number of values not freed yet.
+	PendingObjects int64
+	// Freed is the number of values freed since the store was created.
+	Freed uint64
+}
+
+// LazyFreeStats returns the statistics of the values of the store freed in the background.
+func (store *Store) LazyFreeStats() LazyFreeStats {
+	return LazyFreeStats{
+		PendingBytes:   store.freeing.pendingBytes.Load(),
+		PendingObjects: store.freeing.pendingObjects.Load(),
+		Freed:          store.freeing.freedObjects.Load(),
+	}
+}
+
+var (
+	lazyFreeQueue chan lazyFreeJob
+	lazyFreeOnce  sync.Once
+)
+
+// lazyFree hands the value over to the background goroutine to be freed, if
+// it holds more than config.LazyFreeThreshold elements. The smaller values
+// are cheaper to free on the spot, as are the values handed over while the
+// queue is full, such that the command path never blocks on it.
+func (store *Store) lazyFree(v interface{}, size int64) {
+	f, ok := v.(ChunkFreer)
+	if !ok || f.Len() <= config.LazyFreeThreshold {
+		return
+	}
+	lazyFreeOnce.Do(func() {
+		lazyFreeQueue = make(chan lazyFreeJob, config.LazyFreeQueueSize)
+		go runLazyFree()
+	})
+
+	store.freeing.pendingBytes.Add(size)
+	store.freeing.pendingObjects.Add(1)
+	select {
+	case lazyFreeQueue <- lazyFreeJob{v: f, size: size, stats: &store.freeing}:
+	default:
+		store.freeing.pendingBytes.Add(-size)
+		store.freeing.pendingObjects.Add(-1)
+	}
+}
+
+// detachedValue is a value detached from the keyspace, along with its estimated size.
+type detachedValue struct {
+	v    interface{}
+	size int64
+}
+
+// FreeEvicted hands the values of the keys evicted with the lazy eviction over
+// to the background goroutine, see lazyFree. The values are held until the
+// command evicting them is done, which may still reach them, e.g. through
+// the keys it read before writing, hence FreeEvicted is called as the shard
+// write lock is released.
+func (store *Store) FreeEvicted() {
+	for _, d := range store.evicted {
+		store.lazyFree(d.v, d.size)
+	}
+	clear(store.evicted)
+	store.evicted = store.evicted[:0]
+}
+
+// runLazyFree frees the values handed over to it, a chunk of
+// config.LazyFreeChunkSize elements at a time, yielding to the
+// other goroutines between the chunks.
+func runLazyFree() {
+	for job := range lazyFreeQueue {
+		for !job.v.FreeChunk(config.LazyFreeChunkSize) {
+			runtime.Gosched()
+		}
+		job.stats.pendingBytes.Add(-job.size)
+		job.stats.pendingObjects.Add(-1)
+		job.stats.freedObjects.Add(1)
+	}
+}
+
+// tableFreer frees a keyspace detached from its store, freeing the values
+// it holds along with it.
+type tableFreer struct {
+	t common.ITable[string, *object.Obj]
+}
+
+func (f tableFreer) Len() int {
+	return f.t.Len()
+}
+
+func (f tableFreer) FreeChunk(n int) bool {
+	keys := make([]string, 0, n)
+	f.t.All(func(k string, obj *object.Obj) bool {
+		keys = append(keys, k)
+		if v, ok := obj.Value.(ChunkFreer); ok {
+			for !v.FreeChunk(n) {
+				runtime.Gosched()
+			}
+		}
+		return len(keys) < n
+	})
+	for _, k := range keys {
+		f.t.Delete(k)
+	}
+	return f.t.Len() == 0
+}
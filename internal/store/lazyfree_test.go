@@ -0,0 +1,115 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package store
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// chunkedValue is a value of n elements, counting the chunks it is freed in.
+type chunkedValue struct {
+	m      map[int]struct{}
+	chunks int
+}
+
+func newChunkedValue(n int) *chunkedValue {
+	v := &chunkedValue{m: make(map[int]struct{}, n)}
+	for i := 0; i < n; i++ {
+		v.m[i] = struct{}{}
+	}
+	return v
+}
+
+func (v *chunkedValue) Len() int { return len(v.m) }
+
+func (v *chunkedValue) Size() int { return 16 * len(v.m) }
+
+func (v *chunkedValue) FreeChunk(n int) bool {
+	v.chunks++
+	for k := range v.m {
+		if n--; n < 0 {
+			break
+		}
+		delete(v.m, k)
+	}
+	return len(v.m) == 0
+}
+
+func waitForLazyFree(t *testing.T, s *Store) LazyFreeStats {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		return s.LazyFreeStats().PendingObjects == 0
+	}, time.Second, time.Millisecond)
+	return s.LazyFreeStats()
+}
+
+func TestLazyFreeDel(t *testing.T) {
+	s := NewStore(nil, nil, 0)
+	large := newChunkedValue(10 * config.LazyFreeChunkSize)
+	small := newChunkedValue(config.LazyFreeThreshold)
+	s.Put("large", s.NewObj(large, -1, object.ObjTypeSSMap))
+	s.Put("small", s.NewObj(small, -1, object.ObjTypeSSMap))
+
+	// The key is detached at once, its value being freed in chunks in the background.
+	assert.True(t, s.Del("large", WithLazyFree(true)))
+	assert.Nil(t, s.GetNoTouch("large"))
+	assert.Equal(t, uint64(1), waitForLazyFree(t, s).Freed)
+	assert.Equal(t, 0, large.Len())
+	assert.Equal(t, 10, large.chunks)
+
+	// The small values are freed on the spot.
+	assert.True(t, s.Del("small", WithLazyFree(true)))
+	assert.Equal(t, uint64(1), s.LazyFreeStats().Freed)
+	assert.Equal(t, 0, small.chunks)
+}
+
+func TestResetAsync(t *testing.T) {
+	s := NewStore(nil, nil, 0)
+	large := newChunkedValue(10 * config.LazyFreeChunkSize)
+	s.Put("large", s.NewObj(large, -1, object.ObjTypeSSMap))
+	for i := 0; i < 2*config.LazyFreeChunkSize; i++ {
+		s.Put("key"+strconv.Itoa(i), s.NewObj("v", -1, object.ObjTypeString))
+	}
+
+	ResetAsync(s)
+	assert.Equal(t, 0, s.GetKeyCount())
+	assert.Equal(t, int64(0), s.UsedMemory())
+	assert.Nil(t, s.GetNoTouch("large"))
+
+	// The values of the keyspace are freed along with it.
+	waitForLazyFree(t, s)
+	assert.Equal(t, int64(0), s.LazyFreeStats().PendingBytes)
+	assert.Equal(t, 0, large.Len())
+}
+
+func TestLazyEvictionForWrite(t *testing.T) {
+	e := NewSampledEvictionStrategy(PolicyAllKeysLRU, 0, 1, 5, 10, 1)
+	e.lazyFree = true
+	s := NewStore(nil, e, 0)
+	large := newChunkedValue(10 * config.LazyFreeChunkSize)
+	obj := s.NewObj(large, -1, object.ObjTypeSSMap)
+	s.Put("large", obj)
+
+	// The key being written is never evicted for the write.
+	s.Put("large", obj)
+	s.FreeEvicted()
+	assert.Equal(t, obj, s.GetNoTouch("large"))
+	assert.Equal(t, int64(0), s.LazyFreeStats().PendingObjects)
+
+	// The evicted values are freed only once the write is done.
+	s.Put("key", s.NewObj("v", -1, object.ObjTypeString))
+	assert.Nil(t, s.GetNoTouch("large"))
+	assert.Equal(t, int64(0), s.LazyFreeStats().PendingObjects)
+	assert.Equal(t, 10*config.LazyFreeChunkSize, large.Len())
+
+	s.FreeEvicted()
+	assert.Equal(t, uint64(1), waitForLazyFree(t, s).Freed)
+	assert.Equal(t, 0, large.Len())
+}
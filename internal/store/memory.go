@@ -0,0 +1,38 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package store
+
+import (
+	"maps"
+
+	"github.com/dicedb/dice/internal/object"
+)
+
+// TypeUsage is the number of the keys of a type in a store, along with the
+// estimated number of bytes they use.
+type TypeUsage struct {
+	Keys  int
+	Bytes int64
+}
+
+// accountType adds the key, or removes it for n = -1, to the usage of its type.
+func (store *Store) accountType(obj *object.Obj, n int) {
+	if store.byType == nil {
+		store.byType = make(map[object.ObjectType]TypeUsage)
+	}
+	u := store.byType[obj.Type]
+	u.Keys += n
+	u.Bytes += int64(n) * int64(obj.Size)
+	if u.Keys == 0 {
+		delete(store.byType, obj.Type)
+		return
+	}
+	store.byType[obj.Type] = u
+}
+
+// MemoryByType returns the usage of the keys of the store by type, as
+// accounted for when the keys are set, such that no key is visited.
+func (store *Store) MemoryByType() map[object.ObjectType]TypeUsage {
+	return maps.Clone(store.byType)
+}
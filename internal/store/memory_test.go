@@ -0,0 +1,35 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryByType(t *testing.T) {
+	s := NewStore(nil, nil, 0)
+	s.Put("s1", s.NewObj("v", -1, object.ObjTypeString))
+	s.Put("s2", s.NewObj("value", -1, object.ObjTypeString))
+	s.Put("i1", s.NewObj(int64(1), -1, object.ObjTypeInt))
+
+	usage := s.MemoryByType()
+	assert.Equal(t, 2, usage[object.ObjTypeString].Keys)
+	assert.Equal(t, int64(s.GetNoTouch("s1").Size+s.GetNoTouch("s2").Size), usage[object.ObjTypeString].Bytes)
+	assert.Equal(t, s.UsedMemory(), usage[object.ObjTypeString].Bytes+usage[object.ObjTypeInt].Bytes)
+
+	// A key moves to the type of its new value, and its usage follows the key when renamed.
+	s.Put("s2", s.NewObj(int64(2), -1, object.ObjTypeInt))
+	s.Rename("s1", "s3")
+	s.Del("i1")
+	usage = s.MemoryByType()
+	assert.Equal(t, 1, usage[object.ObjTypeString].Keys)
+	assert.Equal(t, 1, usage[object.ObjTypeInt].Keys)
+	assert.Equal(t, s.UsedMemory(), usage[object.ObjTypeString].Bytes+usage[object.ObjTypeInt].Bytes)
+
+	Reset(s)
+	assert.Empty(t, s.MemoryByType())
+}
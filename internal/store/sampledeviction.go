@@ -0,0 +1,269 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package store
+
+import (
+	"log/slog"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+)
+
+// The eviction policies, selected by the eviction-policy config.
+const (
+	PolicyAllKeysLRU  = "allkeys-lru"
+	PolicyAllKeysLFU  = "allkeys-lfu"
+	PolicyVolatileLRU = "volatile-lru"
+	PolicyVolatileLFU = "volatile-lfu"
+	PolicyVolatileTTL = "volatile-ttl"
+	PolicyNoEviction  = "noeviction"
+)
+
+// evictionPoolSize is the number of candidates retained across the samplings.
+const evictionPoolSize = 16
+
+// NewEvictionStrategy returns the strategy of the eviction policy for a store
+// holding up to maxKeys keys and maxBytes bytes, 0 for no memory limit.
+func NewEvictionStrategy(policy string, maxKeys int, maxBytes int64) EvictionStrategy {
+	switch policy {
+	case PolicyNoEviction:
+		return NewNoEvictionStrategy(maxKeys, maxBytes)
+	case PolicyAllKeysLRU, PolicyAllKeysLFU, PolicyVolatileLRU, PolicyVolatileLFU, PolicyVolatileTTL:
+	default:
+		slog.Warn("unknown eviction policy, using "+PolicyAllKeysLRU, slog.String("policy", policy))
+		policy = PolicyAllKeysLRU
+	}
+	e := NewSampledEvictionStrategy(policy, maxKeys, maxBytes, config.Config.EvictionSamples,
+		config.Config.LFULogFactor, config.Config.LFUDecayTime)
+	e.lazyFree = config.Config.LazyFreeLazyEviction
+	return e
+}
+
+// evictionCandidate is a key sampled for eviction, along with its score
+// at the time of the sampling, the key with the highest score being the
+// first evicted.
+type evictionCandidate struct {
+	key   string
+	score uint64
+}
+
+// SampledEvictionStrategy implements the approximate LRU, LFU and TTL policies.
+// Rather than ordering all the keys, every eviction samples a few keys and
+// evicts the best candidate among them and the best ones of the previous
+// samplings, retained in a pool.
+type SampledEvictionStrategy struct {
+	BaseEvictionStrategy
+	policy   string
+	maxKeys  int
+	maxBytes int64
+	samples  int
+
+	lfuLogFactor int
+	// lfuDecayTime is the number of minutes of idleness decrementing the access counters.
+	lfuDecayTime int
+	// lazyFree frees the values of the evicted keys in the background.
+	lazyFree bool
+
+	// pool holds the best candidates sampled, in the ascending order of their
+	// scores. It is guarded by mu given that the writes of the commands and the
+	// shard cron may evict concurrently.
+	mu   sync.Mutex
+	pool []evictionCandidate
+}
+
+func NewSampledEvictionStrategy(policy string, maxKeys int, maxBytes int64, samples, lfuLogFactor,
+	lfuDecayTime int) *SampledEvictionStrategy {
+	return &SampledEvictionStrategy{
+		policy:       policy,
+		maxKeys:      maxKeys,
+		maxBytes:     maxBytes,
+		samples:      max(samples, 1),
+		lfuLogFactor: lfuLogFactor,
+		lfuDecayTime: lfuDecayTime,
+		pool:         make([]evictionCandidate, 0, evictionPoolSize),
+	}
+}
+
+func (e *SampledEvictionStrategy) volatile() bool {
+	return e.policy == PolicyVolatileLRU || e.policy == PolicyVolatileLFU || e.policy == PolicyVolatileTTL
+}
+
+func (e *SampledEvictionStrategy) lfu() bool {
+	return e.policy == PolicyAllKeysLFU || e.policy == PolicyVolatileLFU
+}
+
+// ShouldEvict returns the number of keys to evict for the store to take a new key within maxKeys.
+func (e *SampledEvictionStrategy) ShouldEvict(store *Store) int {
+	if e.maxKeys <= 0 || store.GetKeyCount() < e.maxKeys {
+		return 0
+	}
+	return store.GetKeyCount() - e.maxKeys + 1
+}
+
+// ShouldEvictBytes returns the number of keys to evict for the store to grow by
+// the given number of bytes within maxBytes.
+func (e *SampledEvictionStrategy) ShouldEvictBytes(store *Store, grow int64) int {
+	return keysToEvictForBytes(store, e.maxBytes, grow)
+}
+
+// EvictVictims evicts the given number of keys, or fewer if the
+// policy runs out of keys to evict.
+func (e *SampledEvictionStrategy) EvictVictims(store *Store, toEvict int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	evicted := 0
+	for ; evicted < toEvict; evicted++ {
+		key, ok := e.nextVictim(store)
+		if !ok {
+			break
+		}
+		store.Del(key, WithDelCmd(Evict), WithLazyFree(e.lazyFree))
+	}
+	if evicted > 0 {
+		e.stats.recordEviction(int64(evicted))
+	}
+}
+
+// RejectWrites returns true if the store is full, or over its share of the
+// reached key limit, and the volatile policy has no key to evict, given that
+// the keys without a TTL are never evicted.
+func (e *SampledEvictionStrategy) RejectWrites(store *Store) bool {
+	return e.volatile() && store.expires.Len() == 0 &&
+		(isFull(store, e.maxKeys, e.maxBytes) || store.keyLimit.excess(store.numKeys, 1) > 0)
+}
+
+// OnAccess updates the access counter of the object for the LFU policies.
+// It is called before the time of the access is updated.
+func (e *SampledEvictionStrategy) OnAccess(key string, obj *object.Obj, accessType AccessType) {
+	if !e.lfu() || accessType == AccessDel {
+		return
+	}
+	updateAccess(obj, func(lastAccessedAt uint32) uint32 {
+		counter := GetLFUCounter(lastAccessedAt)
+		if accessType == AccessSet && counter == 0 {
+			counter = LFUInitVal
+		} else {
+			counter = lfuLogIncr(lfuDecayedCounter(lastAccessedAt, e.lfuDecayTime), e.lfuLogFactor)
+		}
+		return withLFUCounter(lastAccessedAt, counter)
+	})
+}
+
+// score returns how good a candidate for eviction the object is, the higher the better.
+func (e *SampledEvictionStrategy) score(store *Store, obj *object.Obj) uint64 {
+	switch e.policy {
+	case PolicyAllKeysLFU, PolicyVolatileLFU:
+		return uint64(math.MaxUint8 - lfuDecayedCounter(LastAccessedAt(obj), e.lfuDecayTime))
+	case PolicyVolatileTTL:
+		exp, _ := store.expires.Get(obj)
+		return math.MaxUint64 - exp
+	default:
+		return uint64(GetIdleTime(LastAccessedAt(obj)))
+	}
+}
+
+// nextVictim returns the best candidate for eviction after sampling the store.
+// The candidates whose score dropped since they were sampled, which is the
+// case of the keys accessed since, are discarded.
+func (e *SampledEvictionStrategy) nextVictim(store *Store) (string, bool) {
+	e.sample(store)
+	for len(e.pool) > 0 {
+		c := e.pool[len(e.pool)-1]
+		e.pool = e.pool[:len(e.pool)-1]
+
+		obj, ok := store.store.Get(c.key)
+		if !ok || !store.evictable(c.key) {
+			continue
+		}
+		if _, ok := store.expires.Get(obj); e.volatile() && !ok {
+			continue
+		}
+		if e.score(store, obj) < c.score {
+			continue
+		}
+		return c.key, true
+	}
+	return "", false
+}
+
+// sample offers the given number of keys of the store to the pool. The
+// volatile policies sample the expiry index, holding only the keys with a TTL,
+// and the others the keys in the order of the iteration of the table, which
+// starts at a random position.
+func (e *SampledEvictionStrategy) sample(store *Store) {
+	if e.volatile() {
+		for _, x := range store.expires.sample(e.samples) {
+			e.offer(x.key, e.score(store, x.obj))
+		}
+		return
+	}
+
+	sampled := 0
+	store.store.All(func(k string, obj *object.Obj) bool {
+		e.offer(k, e.score(store, obj))
+		sampled++
+		return sampled < e.samples
+	})
+}
+
+// offer adds the candidate to the pool if it is better than the worst one
+// of a full pool, in which case the worst one is dropped.
+func (e *SampledEvictionStrategy) offer(key string, score uint64) {
+	i := sort.Search(len(e.pool), func(i int) bool {
+		return e.pool[i].score >= score
+	})
+	if i == 0 && len(e.pool) == evictionPoolSize {
+		return
+	}
+	if slices.ContainsFunc(e.pool, func(c evictionCandidate) bool { return c.key == key }) {
+		return
+	}
+
+	e.pool = slices.Insert(e.pool, i, evictionCandidate{key, score})
+	if len(e.pool) > evictionPoolSize {
+		e.pool = slices.Delete(e.pool, 0, 1)
+	}
+}
+
+// NoEvictionStrategy never evicts any key, the writes growing the store being
+// rejected once it is full.
+type NoEvictionStrategy struct {
+	BaseEvictionStrategy
+	maxKeys  int
+	maxBytes int64
+}
+
+func NewNoEvictionStrategy(maxKeys int, maxBytes int64) *NoEvictionStrategy {
+	return &NoEvictionStrategy{
+		maxKeys:  maxKeys,
+		maxBytes: maxBytes,
+	}
+}
+
+func (e *NoEvictionStrategy) ShouldEvict(store *Store) int {
+	return 0
+}
+
+func (e *NoEvictionStrategy) ShouldEvictBytes(store *Store, grow int64) int {
+	return 0
+}
+
+func (e *NoEvictionStrategy) EvictVictims(store *Store, toEvict int) {
+}
+
+// RejectWrites returns true if the store is full or the key limit is reached,
+// whatever the share of the keys of the store, given that no key would ever
+// be evicted to bring the stores back within the limit.
+func (e *NoEvictionStrategy) RejectWrites(store *Store) bool {
+	return isFull(store, e.maxKeys, e.maxBytes) || store.keyLimit.full()
+}
+
+func (e *NoEvictionStrategy) OnAccess(key string, obj *object.Obj, accessType AccessType) {
+	// Nothing to do, no key is ever evicted
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package store
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/common"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSampledStore(policy string, maxKeys int) *Store {
+	return NewStore(nil, NewSampledEvictionStrategy(policy, maxKeys, 0, 5, 10, 1), 0)
+}
+
+func TestSampledEvictionLRU(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Now()}
+	utils.CurrentTime = mockTime
+	s := newSampledStore(PolicyAllKeysLRU, 4)
+
+	for i := 0; i < 4; i++ {
+		mockTime.SetTime(mockTime.GetTime().Add(time.Second))
+		s.Put("key"+strconv.Itoa(i), s.NewObj("v", -1, object.ObjTypeString))
+	}
+	// With fewer keys than samples, the least recently used key is the one evicted.
+	mockTime.SetTime(mockTime.GetTime().Add(time.Second))
+	s.Get("key0")
+	s.Put("key4", s.NewObj("v", -1, object.ObjTypeString))
+
+	assert.Equal(t, 4, s.GetKeyCount())
+	assert.NotNil(t, s.GetNoTouch("key0"))
+	assert.Nil(t, s.GetNoTouch("key1"))
+}
+
+func TestSampledEvictionLFU(t *testing.T) {
+	s := newSampledStore(PolicyAllKeysLFU, 3)
+
+	for i := 0; i < 3; i++ {
+		s.Put("key"+strconv.Itoa(i), s.NewObj("v", -1, object.ObjTypeString))
+	}
+	assert.Equal(t, uint8(LFUInitVal), GetLFUCounter(s.GetNoTouch("key0").LastAccessedAt))
+
+	// The counter carries over to the new values of the key.
+	for i := 0; i < 100; i++ {
+		s.Get("key0")
+		s.Get("key2")
+	}
+	s.Put("key0", s.NewObj("v2", -1, object.ObjTypeString))
+	assert.Greater(t, GetLFUCounter(s.GetNoTouch("key0").LastAccessedAt), uint8(LFUInitVal))
+
+	s.Put("key3", s.NewObj("v", -1, object.ObjTypeString))
+	assert.Nil(t, s.GetNoTouch("key1"))
+	assert.NotNil(t, s.GetNoTouch("key0"))
+	assert.NotNil(t, s.GetNoTouch("key2"))
+}
+
+func TestSampledEvictionLFUConcurrentReads(t *testing.T) {
+	s := newSampledStore(PolicyAllKeysLFU, 3)
+	s.Put("key", s.NewObj("v", -1, object.ObjTypeString))
+
+	// The reads, holding only the read lock of the shard, update the
+	// access counter of the key concurrently.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Get("key")
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Greater(t, GetLFUCounter(LastAccessedAt(s.GetNoTouch("key"))), uint8(LFUInitVal))
+}
+
+func TestSampledEvictionVolatile(t *testing.T) {
+	s := newSampledStore(PolicyVolatileTTL, 3)
+
+	s.Put("persistent", s.NewObj("v", -1, object.ObjTypeString))
+	s.Put("later", s.NewObj("v", 60000, object.ObjTypeString))
+	s.Put("sooner", s.NewObj("v", 1000, object.ObjTypeString))
+	s.Put("new", s.NewObj("v", -1, object.ObjTypeString))
+
+	// Only the keys with a TTL are evicted, the ones expiring first first.
+	assert.Nil(t, s.GetNoTouch("sooner"))
+	assert.NotNil(t, s.GetNoTouch("later"))
+	assert.NotNil(t, s.GetNoTouch("persistent"))
+	assert.False(t, s.RejectsWrites())
+
+	s.Put("newer", s.NewObj("v", -1, object.ObjTypeString))
+	assert.Nil(t, s.GetNoTouch("later"))
+	// The keys left have no TTL, hence nothing is left to evict.
+	assert.True(t, s.RejectsWrites())
+}
+
+func TestNoEviction(t *testing.T) {
+	s := NewStore(nil, NewNoEvictionStrategy(2, 0), 0)
+
+	s.Put("key0", s.NewObj("v", -1, object.ObjTypeString))
+	assert.False(t, s.RejectsWrites())
+	s.Put("key1", s.NewObj("v", -1, object.ObjTypeString))
+	assert.True(t, s.RejectsWrites())
+
+	s.Del("key0")
+	assert.False(t, s.RejectsWrites())
+}
+
+func TestEvictExcess(t *testing.T) {
+	s := NewStore(nil, NewSampledEvictionStrategy(PolicyAllKeysLRU, 0, 100*1024, 5, 10, 1), 0)
+	for i := 0; i < 100; i++ {
+		s.Put("key"+strconv.Itoa(i), s.NewObj(strings.Repeat("v", 900), -1, object.ObjTypeString))
+	}
+
+	// A write evicts a bounded number of keys, leaving the store over its limit.
+	s.Put("big", s.NewObj(strings.Repeat("v", 60*1024), -1, object.ObjTypeString))
+	assert.Equal(t, 100-config.EvictionStepSize+1, s.GetKeyCount())
+	assert.Greater(t, s.UsedMemory(), int64(100*1024))
+
+	// The cron evicts the keys left in excess.
+	s.EvictExcess(time.Second)
+	assert.LessOrEqual(t, s.UsedMemory(), int64(100*1024))
+}
+
+func TestSampledEvictionSwissTable(t *testing.T) {
+	s := newSampledStore(PolicyAllKeysLFU, 1000)
+	s.UseTable(TableSwiss)
+
+	for i := 0; i < 5000; i++ {
+		s.Put("key"+strconv.Itoa(i), s.NewObj("v", -1, object.ObjTypeString))
+		s.Get("key0")
+	}
+	assert.Equal(t, 1000, s.GetKeyCount())
+	assert.NotNil(t, s.GetNoTouch("key0"))
+
+	// The keyspace is held in the same kind of table once the store is reset.
+	Reset(s)
+	assert.IsType(t, &common.SwissTable[string, *object.Obj]{}, s.store)
+}
+
+// BenchmarkEvictionHitRatio reports the ratio of the reads hitting a cache
+// holding a tenth of a keyspace read along a Zipfian distribution, the keys
+// missed being written to the cache.
+func BenchmarkEvictionHitRatio(b *testing.B) {
+	const (
+		keyspace = 100000
+		maxKeys  = keyspace / 10
+	)
+	policies := []string{PolicyAllKeysLRU, PolicyAllKeysLFU, PolicyVolatileLRU, PolicyVolatileLFU, PolicyVolatileTTL}
+	for _, policy := range policies {
+		b.Run(policy, func(b *testing.B) {
+			mockTime := &utils.MockClock{CurrTime: time.Now()}
+			utils.CurrentTime = mockTime
+			s := newSampledStore(policy, maxKeys)
+			r := rand.New(rand.NewSource(1))
+			zipf := rand.NewZipf(r, 1.1, 1, keyspace-1)
+
+			hits := 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// The clock ticks every hundred reads.
+				if i%100 == 0 {
+					mockTime.SetTime(mockTime.GetTime().Add(time.Second))
+				}
+				key := "key" + strconv.FormatUint(zipf.Uint64(), 10)
+				if s.Get(key) != nil {
+					hits++
+					continue
+				}
+				s.Put(key, s.NewObj("v", int64(time.Hour/time.Millisecond)+r.Int63n(1000), object.ObjTypeString))
+			}
+			b.ReportMetric(float64(hits)/float64(b.N), "hits/op")
+		})
+	}
+}
+
+// BenchmarkEvictionLatency reports the latencies of the writes of new keys
+// to a store holding 10M keys, every write evicting a key.
+func BenchmarkEvictionLatency(b *testing.B) {
+	const maxKeys = 10000000
+	s := newSampledStore(PolicyAllKeysLRU, maxKeys)
+	for i := 0; i < maxKeys; i++ {
+		s.Put("key"+strconv.Itoa(i), s.NewObj("v", -1, object.ObjTypeString))
+	}
+
+	latencies := make([]time.Duration, b.N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := "key" + strconv.Itoa(maxKeys+i)
+		start := time.Now()
+		s.Put(key, s.NewObj("v", -1, object.ObjTypeString))
+		latencies[i] = time.Since(start)
+	}
+	b.StopTimer()
+
+	slices.Sort(latencies)
+	b.ReportMetric(float64(latencies[b.N/2].Nanoseconds()), "p50-ns")
+	b.ReportMetric(float64(latencies[b.N*99/100].Nanoseconds()), "p99-ns")
+	b.ReportMetric(float64(latencies[b.N-1].Nanoseconds()), "max-ns")
+}
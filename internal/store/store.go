@@ -4,8 +4,14 @@
 package store
 
 import (
+	"hash/maphash"
+	"log/slog"
+	"math"
 	"path"
+	"sync"
+	"time"
 
+	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/common"
 	"github.com/dicedb/dice/internal/object"
 	"github.com/dicedb/dice/internal/server/utils"
@@ -17,22 +23,22 @@ func NewStoreRegMap() common.ITable[string, *object.Obj] {
 	}
 }
 
-func NewExpireRegMap() common.ITable[*object.Obj, uint64] {
-	return &common.RegMap[*object.Obj, uint64]{
-		M: make(map[*object.Obj]uint64),
-	}
-}
-
 func NewStoreMap() common.ITable[string, *object.Obj] {
 	return NewStoreRegMap()
 }
 
-func NewExpireMap() common.ITable[*object.Obj, uint64] {
-	return NewExpireRegMap()
+func NewStoreSwissTable() common.ITable[string, *object.Obj] {
+	return common.NewSwissTable[string, *object.Obj](maphash.String)
 }
 
+// The tables the keyspace of a store may be held in, see UseTable.
+const (
+	TableMap   = "map"
+	TableSwiss = "swiss"
+)
+
 func NewDefaultEviction() EvictionStrategy {
-	return &PrimitiveEvictionStrategy{}
+	return NewPrimitiveEvictionStrategy(config.DefaultKeysLimit, 0)
 }
 
 // QueryWatchEvent represents a change in a watched key.
@@ -49,17 +55,35 @@ type CmdWatchEvent struct {
 
 type Store struct {
 	store            common.ITable[string, *object.Obj]
-	expires          common.ITable[*object.Obj, uint64] // Does not need to be thread-safe as it is only accessed by a single thread.
+	newTable         func() common.ITable[string, *object.Obj]
+	expires          *expiryIndex
 	numKeys          int
-	cmdWatchChan     chan CmdWatchEvent
+	keyLimit         *KeyLimit
+	usedMemory       int64
+	byType           map[object.ObjectType]TypeUsage
+	hotKeys          *hotKeys
+	bigKeys          map[object.ObjectType]*topKeys
+	cmdWatchChan     chan *Store
+	watchEvents      pendingWatchEvents
 	evictionStrategy EvictionStrategy
 	ShardID          int
+	freeing          lazyFreeStats
+	// evicted are the evicted values awaiting to be freed in the
+	// background, see FreeEvicted.
+	evicted []detachedValue
+	// writeKey is the key written while the store evicts for the write,
+	// which is never picked for eviction, see evictable.
+	writeKey         string
+	evictingForWrite bool
+
+	expiryStats
 }
 
-func NewStore(cmdWatchChan chan CmdWatchEvent, evictionStrategy EvictionStrategy, shardID int) *Store {
+func NewStore(cmdWatchChan chan *Store, evictionStrategy EvictionStrategy, shardID int) *Store {
 	store := &Store{
 		store:            NewStoreRegMap(),
-		expires:          NewExpireRegMap(),
+		newTable:         NewStoreMap,
+		expires:          newExpiryIndex(),
 		cmdWatchChan:     cmdWatchChan,
 		evictionStrategy: evictionStrategy,
 		ShardID:          shardID,
@@ -72,13 +96,42 @@ func NewStore(cmdWatchChan chan CmdWatchEvent, evictionStrategy EvictionStrategy
 }
 
 func Reset(store *Store) *Store {
-	store.numKeys = 0
-	store.store = NewStoreMap()
-	store.expires = NewExpireMap()
+	store.addKeys(-store.numKeys)
+	store.usedMemory = 0
+	store.byType = nil
+	store.resetKeyStats()
+	store.store = store.newTable()
+	store.expires = newExpiryIndex()
 
 	return store
 }
 
+// ResetAsync empties the store the way Reset does, the keys being detached
+// from the store at once and freed in the background.
+func ResetAsync(store *Store) *Store {
+	table, size := store.store, store.usedMemory
+	Reset(store)
+	store.lazyFree(tableFreer{t: table}, size)
+
+	return store
+}
+
+// UseTable switches the keyspace of the empty store to the table of the given
+// kind, TableMap or TableSwiss, the keyspace being held in the same kind of
+// table once the store is reset. An unknown kind falls back to TableMap.
+func (store *Store) UseTable(kind string) {
+	switch kind {
+	case TableSwiss:
+		store.newTable = NewStoreSwissTable
+	case TableMap:
+		store.newTable = NewStoreMap
+	default:
+		slog.Warn("unknown keyspace table, using "+TableMap, slog.String("table", kind))
+		store.newTable = NewStoreMap
+	}
+	store.store = store.newTable()
+}
+
 func (store *Store) NewObj(value interface{}, expDurationMs int64, oType object.ObjectType) *object.Obj {
 	obj := &object.Obj{
 		Value:          value,
@@ -92,9 +145,12 @@ func (store *Store) NewObj(value interface{}, expDurationMs int64, oType object.
 }
 
 func (store *Store) ResetStore() {
-	store.numKeys = 0
-	store.store = NewStoreMap()
-	store.expires = NewExpireMap()
+	store.addKeys(-store.numKeys)
+	store.usedMemory = 0
+	store.byType = nil
+	store.resetKeyStats()
+	store.store = store.newTable()
+	store.expires = newExpiryIndex()
 }
 
 func (store *Store) Put(k string, obj *object.Obj, opts ...PutOption) {
@@ -106,7 +162,33 @@ func (store *Store) GetKeyCount() int {
 }
 
 func (store *Store) IncrementKeyCount() {
-	store.numKeys++
+	store.addKeys(1)
+}
+
+// addKeys adds n keys to the count of the keys of the store, and to the
+// usage of its key limit.
+func (store *Store) addKeys(n int) {
+	store.numKeys += n
+	store.keyLimit.add(n)
+}
+
+// UsedMemory returns the estimated number of bytes used by the keys and the values of the store,
+// not counting the values detached from the store and not freed yet, see LazyFreeStats.
+func (store *Store) UsedMemory() int64 {
+	return store.usedMemory
+}
+
+// RejectsWrites returns true if the writes growing the store are to be rejected,
+// which is the case once the store is full and no key can be evicted.
+func (store *Store) RejectsWrites() bool {
+	r, ok := store.evictionStrategy.(WriteRejecter)
+	return ok && r.RejectWrites(store)
+}
+
+// entrySize returns the estimated size of the key and the object in the store.
+func entrySize(k string, obj *object.Obj) uint32 {
+	size := object.ObjOverhead + object.StringSize(k) + object.EstimateSize(obj)
+	return uint32(min(size, math.MaxUint32))
 }
 
 func (store *Store) PutAll(data map[string]*object.Obj) {
@@ -126,9 +208,28 @@ func (store *Store) putHelper(k string, obj *object.Obj, opts ...PutOption) {
 		optApplier(options)
 	}
 
-	obj.LastAccessedAt = getCurrentClock()
+	// The limits are enforced before the key is looked up, given that the
+	// eviction may remove the key itself. The object being put may be the
+	// one already stored, updated in place, hence its size is set only once
+	// the size of the current object is released.
+	size := entrySize(k, obj)
+	store.evictForWrite(k, size)
+
+	// An expired key left over by the reads, which never delete, is
+	// expired here rather than overwritten, for its TTL not to be kept.
 	currentObject, ok := store.store.Get(k)
+	if ok && currentObject != obj && hasExpired(currentObject, store) {
+		store.deleteKey(k, currentObject, WithDelCmd(Expired))
+		ok = false
+	}
 	if ok {
+		store.usedMemory -= int64(currentObject.Size)
+		store.accountType(currentObject, -1)
+		if currentObject.Type != obj.Type {
+			store.untrackBigKey(k, currentObject)
+		}
+		// The access counter of the key carries over to its new value.
+		obj.LastAccessedAt = currentObject.LastAccessedAt
 		v, ok1 := store.expires.Get(currentObject)
 		if ok1 && options.KeepTTL && v > 0 {
 			v1, ok2 := store.expires.Get(currentObject)
@@ -138,17 +239,18 @@ func (store *Store) putHelper(k string, obj *object.Obj, opts ...PutOption) {
 		}
 		store.expires.Delete(currentObject)
 	} else {
-		// TODO: Inform all the io-threads and shards about the eviction.
-		// TODO: Start the eviction only when all the io-thread and shards have acknowledged the eviction.
-		evictCount := store.evictionStrategy.ShouldEvict(store)
-		if evictCount > 0 {
-			store.evict(evictCount)
-		}
-		store.numKeys++
+		store.addKeys(1)
 	}
 
+	obj.Size = size
 	store.store.Put(k, obj)
+	store.expires.stored(obj, k)
+	store.usedMemory += int64(size)
+	store.accountType(obj, 1)
+	store.trackBigKey(k, obj)
+	store.hotKeys.sample(k)
 	store.evictionStrategy.OnAccess(k, obj, AccessSet)
+	touchObj(obj)
 
 	if store.cmdWatchChan != nil {
 		store.notifyWatchManager(options.PutCmd, k)
@@ -156,16 +258,20 @@ func (store *Store) putHelper(k string, obj *object.Obj, opts ...PutOption) {
 }
 
 // getHelper is a helper function to get the object from the store. It also updates the last accessed time if touch is true.
+// The reads hold only the read lock of the shard, hence an expired key is not
+// deleted but left to the shard cron, or to the next write of the key.
 func (store *Store) getHelper(k string, touch bool) *object.Obj {
 	var obj *object.Obj
 	obj, _ = store.store.Get(k)
 	if obj != nil {
 		if hasExpired(obj, store) {
-			store.deleteKey(k, obj)
 			obj = nil
 		} else if touch {
-			obj.LastAccessedAt = getCurrentClock()
+			// The strategy is told about the access before the time of the
+			// access is updated, given that it may depend on the idle time.
+			store.hotKeys.sample(k)
 			store.evictionStrategy.OnAccess(k, obj, AccessGet)
+			touchObj(obj)
 		}
 	}
 	return obj
@@ -177,10 +283,11 @@ func (store *Store) GetAll(keys []string) []*object.Obj {
 		v, _ := store.store.Get(k)
 		if v != nil {
 			if hasExpired(v, store) {
-				store.deleteKey(k, v)
 				response = append(response, nil)
 			} else {
-				v.LastAccessedAt = getCurrentClock()
+				store.hotKeys.sample(k)
+				store.evictionStrategy.OnAccess(k, v, AccessGet)
+				touchObj(v)
 				response = append(response, v)
 			}
 		} else {
@@ -192,10 +299,14 @@ func (store *Store) GetAll(keys []string) []*object.Obj {
 
 func (store *Store) Del(k string, opts ...DelOption) bool {
 	v, ok := store.store.Get(k)
-	if ok {
-		return store.deleteKey(k, v, opts...)
+	if !ok {
+		return false
 	}
-	return false
+	if hasExpired(v, store) {
+		store.deleteKey(k, v, WithDelCmd(Expired))
+		return false
+	}
+	return store.deleteKey(k, v, opts...)
 }
 
 func (store *Store) DelByPtr(ptr string, opts ...DelOption) bool {
@@ -238,21 +349,20 @@ func (store *Store) Rename(sourceKey, destKey string) bool {
 	sourceObj, _ := store.store.Get(sourceKey)
 	if sourceObj == nil || hasExpired(sourceObj, store) {
 		if sourceObj != nil {
-			store.deleteKey(sourceKey, sourceObj, WithDelCmd(Rename))
+			store.deleteKey(sourceKey, sourceObj, WithDelCmd(Expired))
 		}
 		return false
 	}
 
-	// Use putHelper to handle putting the object at the destination key
-	store.putHelper(destKey, sourceObj, WithPutCmd(Set))
-
-	// Remove the source key
+	// Remove the source key before putting the object at the destination
+	// key, for the eviction making room for it not to evict the object.
 	store.store.Delete(sourceKey)
-	store.numKeys--
+	store.addKeys(-1)
+	store.usedMemory -= int64(sourceObj.Size)
+	store.accountType(sourceObj, -1)
+	store.untrackKey(sourceKey, sourceObj)
 
-	if store.cmdWatchChan != nil {
-		store.notifyWatchManager(Rename, sourceKey)
-	}
+	store.putHelper(destKey, sourceObj, WithPutCmd(Set))
 
 	return true
 }
@@ -265,26 +375,33 @@ func (store *Store) GetDel(k string, opts ...DelOption) *object.Obj {
 	var v *object.Obj
 	v, _ = store.store.Get(k)
 	if v != nil {
-		expired := hasExpired(v, store)
-		store.deleteKey(k, v, opts...)
-		if expired {
-			v = nil
+		if hasExpired(v, store) {
+			store.deleteKey(k, v, WithDelCmd(Expired))
+			return nil
 		}
+		store.deleteKey(k, v, opts...)
 	}
 	return v
 }
 
-// SetExpiry sets the expiry time for an object.
-// This method is not thread-safe. It should be called within a lock.
+// SetExpiry sets the expiry time for an object about to be put in the
+// store, see SetKeyExpiry for the objects already stored.
+// The object is added to the expiry index once it is put.
 func (store *Store) SetExpiry(obj *object.Obj, expDurationMs int64) {
+	store.expires.setPending(obj, uint64(utils.GetCurrentTime().UnixMilli())+uint64(expDurationMs))
+}
+
+// SetKeyExpiry sets the expiry time for the object stored under the key.
+func (store *Store) SetKeyExpiry(k string, obj *object.Obj, expDurationMs int64) {
 	store.expires.Put(obj, uint64(utils.GetCurrentTime().UnixMilli())+uint64(expDurationMs))
+	store.expires.setKey(obj, k)
 }
 
-// SetUnixTimeExpiry sets the expiry time for an object.
-// This method is not thread-safe. It should be called within a lock.
-func (store *Store) SetUnixTimeExpiry(obj *object.Obj, exUnixTimeSec int64) {
+// SetUnixTimeExpiry sets the expiry time for the object stored under the key.
+func (store *Store) SetUnixTimeExpiry(k string, obj *object.Obj, exUnixTimeSec int64) {
 	// convert unix-time-seconds to unix-time-milliseconds
 	store.expires.Put(obj, uint64(exUnixTimeSec*1000))
+	store.expires.setKey(obj, k)
 }
 
 func (store *Store) deleteKey(k string, obj *object.Obj, opts ...DelOption) bool {
@@ -297,7 +414,20 @@ func (store *Store) deleteKey(k string, obj *object.Obj, opts ...DelOption) bool
 	if obj != nil {
 		store.store.Delete(k)
 		store.expires.Delete(obj)
-		store.numKeys--
+		store.addKeys(-1)
+		store.usedMemory -= int64(obj.Size)
+		store.accountType(obj, -1)
+		store.untrackKey(k, obj)
+		if options.LazyFree && options.DelCmd == Evict {
+			// The command evicting the key may still reach its value,
+			// hence the value is freed once the command is done.
+			store.evicted = append(store.evicted, detachedValue{v: obj.Value, size: int64(obj.Size)})
+		} else if options.LazyFree {
+			store.lazyFree(obj.Value, int64(obj.Size))
+		}
+		if options.DelCmd == Expired {
+			store.expiredKeys.Add(1)
+		}
 
 		store.evictionStrategy.OnAccess(k, obj, AccessDel)
 
@@ -319,14 +449,117 @@ func (store *Store) delByPtr(ptr string, opts ...DelOption) bool {
 	return false
 }
 
+// pendingWatchEvents are the changes the store made on its own that the
+// watch manager is yet to take, the last operation of every key changed.
+type pendingWatchEvents struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+// notifyWatchManager records the changes the store makes on its own, i.e. the
+// expiry and eviction of keys, for the watch manager, the changes made by the
+// commands being notified by the io-threads executing them. The changes are
+// coalesced until the watch manager takes them, for the writes never to be
+// held up by the watchers, and the store is sent to cmdWatchChan once it has
+// changes pending. The store being sent again only once the changes are
+// taken, the channel never fills up if it has room for every store.
 func (store *Store) notifyWatchManager(cmd, affectedKey string) {
-	store.cmdWatchChan <- CmdWatchEvent{cmd, affectedKey}
+	if cmd != Expired && cmd != Evict {
+		return
+	}
+
+	e := &store.watchEvents
+	e.mu.Lock()
+	first := len(e.keys) == 0
+	if e.keys == nil {
+		e.keys = make(map[string]string)
+	}
+	e.keys[affectedKey] = cmd
+	e.mu.Unlock()
+
+	if first {
+		store.cmdWatchChan <- store
+	}
+}
+
+// TakeWatchEvents returns the changes the store made on its own since they
+// were last taken, as the last operation, Expired or Evict, of every key.
+func (store *Store) TakeWatchEvents() map[string]string {
+	e := &store.watchEvents
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	keys := e.keys
+	e.keys = nil
+	return keys
+}
+
+// Scan calls f with count keys of the store or so, along with their objects,
+// starting at the cursor, and returns the cursor of the next keys, 0 once
+// all the keys are scanned. The keys held by the store all along are scanned
+// exactly once, in the order of the hashes of the keys, see common.ITable.
+// The expired keys are skipped, and left to the shard cron.
+func (store *Store) Scan(cursor uint64, count int, f func(k string, obj *object.Obj)) uint64 {
+	return store.store.Scan(cursor, count, func(k string, obj *object.Obj) {
+		if !hasExpired(obj, store) {
+			f(k, obj)
+		}
+	})
 }
 
 func (store *Store) GetStore() common.ITable[string, *object.Obj] {
 	return store.store
 }
 
+// evictForWrite evicts keys for the store to take the entry of the given size
+// for the key within its limits. At most config.EvictionStepSize keys are
+// evicted, such that a write never stalls on a large eviction, the keys left
+// to evict being evicted by the shard cron, see EvictExcess.
+func (store *Store) evictForWrite(k string, size uint32) {
+	// TODO: Inform all the io-threads and shards about the eviction.
+	// TODO: Start the eviction only when all the io-thread and shards have acknowledged the eviction.
+	grow := int64(size)
+	current, exists := store.store.Get(k)
+	if exists {
+		grow -= int64(current.Size)
+	}
+	evictCount := store.evictionStrategy.ShouldEvictBytes(store, grow)
+	if !exists {
+		evictCount = max(evictCount, store.evictionStrategy.ShouldEvict(store), store.keyLimit.excess(store.numKeys, 1))
+	}
+	if evictCount > 0 {
+		store.writeKey, store.evictingForWrite = k, true
+		store.evict(min(evictCount, config.EvictionStepSize))
+		store.writeKey, store.evictingForWrite = "", false
+	}
+}
+
+// evictable returns false for the key being written while the store evicts
+// for the write, the command writing it holding on to its value.
+func (store *Store) evictable(k string) bool {
+	return !store.evictingForWrite || k != store.writeKey
+}
+
+// EvictExcess evicts the keys in excess of the memory limit of the store, and
+// of its share of the key limit once the key limit is reached, in steps of
+// config.EvictionStepSize keys, until the store is within its limits, no key
+// is left to evict, or the budget is spent.
+func (store *Store) EvictExcess(budget time.Duration) {
+	defer store.FreeEvicted()
+
+	start := time.Now()
+	for time.Since(start) < budget {
+		evictCount := max(store.evictionStrategy.ShouldEvictBytes(store, 0), store.keyLimit.excess(store.numKeys, 0))
+		if evictCount <= 0 {
+			return
+		}
+		numKeys := store.numKeys
+		store.evict(min(evictCount, config.EvictionStepSize))
+		if store.numKeys == numKeys {
+			return
+		}
+	}
+}
+
 func (store *Store) evict(evictCount int) bool {
 	store.evictionStrategy.EvictVictims(store, evictCount)
 	return true
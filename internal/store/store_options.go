@@ -31,6 +31,8 @@ func WithPutCmd(cmd string) PutOption {
 
 type DelOptions struct {
 	DelCmd string
+	// LazyFree frees the value in the background, if it is large enough.
+	LazyFree bool
 }
 
 func getDefaultDelOptions() *DelOptions {
@@ -46,3 +48,9 @@ func WithDelCmd(cmd string) DelOption {
 		po.DelCmd = cmd
 	}
 }
+
+func WithLazyFree(value bool) DelOption {
+	return func(po *DelOptions) {
+		po.LazyFree = value
+	}
+}
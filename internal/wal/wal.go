@@ -15,6 +15,8 @@ type AbstractWAL interface {
 	Init(t time.Time) error
 	Replay(c func(*WALEntry) error) error
 	ForEachCommand(e *WALEntry, c func(*WALEntry) error) error
+	ReplayFrom(lsn uint64, c func(*WALEntry) error) error
+	Observe(f func(*WALEntry))
 }
 
 var (
@@ -7,6 +7,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"hash/crc32"
 	"io"
@@ -32,6 +33,8 @@ const (
 	WALModeUnbuffered = "unbuffered"
 )
 
+var ErrWALClosed = errors.New("wal is closed")
+
 type AOF struct {
 	logDir                 string
 	currentSegmentFile     *os.File
@@ -52,8 +55,12 @@ type AOF struct {
 	segmentRotationTicker  *time.Ticker
 	segmentRetentionTicker *time.Ticker
 	mu                     sync.Mutex
+	closed                 bool
 	ctx                    context.Context
 	cancel                 context.CancelFunc
+
+	// observer, if set, is called with every entry logged.
+	observer func(*WALEntry)
 }
 
 func NewAOFWAL(directory string) (*AOF, error) {
@@ -78,6 +85,9 @@ func NewAOFWAL(directory string) (*AOF, error) {
 }
 
 func (wal *AOF) Init(t time.Time) error {
+	wal.mu.Lock()
+	defer wal.mu.Unlock()
+
 	// TODO - Restore existing checkpoints to memory
 
 	// Create the directory if it doesn't exist
@@ -91,16 +101,31 @@ func (wal *AOF) Init(t time.Time) error {
 		return nil
 	}
 
+	wal.closed = false
+	wal.lastSequenceNo = 0
+	wal.currentSegmentIndex = 0
+	wal.oldestSegmentIndex = 0
+	wal.byteOffset = 0
+
+	// The log continues at the end of the last segment, if any, so that the
+	// log sequence numbers keep increasing across restarts and rotations.
 	if len(files) > 0 {
 		slog.Info("Found existing log segments", slog.Any("files", files))
 		// TODO - Check if we have newer WAL entries after the last checkpoint and simultaneously replay and checkpoint them
+		segments, err := wal.segmentFiles()
+		if err != nil {
+			return err
+		}
+		wal.oldestSegmentIndex = segmentIndex(segments[0])
+		wal.currentSegmentIndex = segmentIndex(segments[len(segments)-1])
+		wal.lastSequenceNo = lastSequenceNo(segments)
+		if info, err := os.Stat(segments[len(segments)-1]); err == nil {
+			wal.byteOffset = int(info.Size())
+		}
 	}
+	wal.ctx, wal.cancel = context.WithCancel(context.Background())
 
-	wal.lastSequenceNo = 0
-	wal.currentSegmentIndex = 0
-	wal.oldestSegmentIndex = 0
-	wal.byteOffset = 0
-	newFile, err := os.OpenFile(filepath.Join(wal.logDir, segmentPrefix+"0"+segmentSuffix), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	newFile, err := os.OpenFile(filepath.Join(wal.logDir, segmentPrefix+fmt.Sprintf("%d", wal.currentSegmentIndex)+segmentSuffix), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
 	}
@@ -111,14 +136,14 @@ func (wal *AOF) Init(t time.Time) error {
 	}
 	wal.bufWriter = bufio.NewWriterSize(wal.currentSegmentFile, wal.bufferSize)
 
-	go wal.keepSyncingBuffer()
+	go wal.keepSyncingBuffer(wal.ctx)
 
 	if wal.rotationMode == RotationModeTime {
-		go wal.rotateSegmentPeriodically()
+		go wal.rotateSegmentPeriodically(wal.ctx)
 	}
 
 	if wal.retentionMode == RetentionModeTime {
-		go wal.deleteSegmentPeriodically()
+		go wal.deleteSegmentPeriodically(wal.ctx)
 	}
 
 	return nil
@@ -133,6 +158,12 @@ func (wal *AOF) writeEntry(data []byte) error {
 	wal.mu.Lock()
 	defer wal.mu.Unlock()
 
+	// Writing to a closed WAL must fail so that the
+	// command is not acknowledged as durable.
+	if wal.closed {
+		return ErrWALClosed
+	}
+
 	wal.lastSequenceNo++
 	entry := &WALEntry{
 		Version:           defaultVersion,
@@ -160,9 +191,21 @@ func (wal *AOF) writeEntry(data []byte) error {
 		}
 	}
 
+	if wal.observer != nil {
+		wal.observer(entry)
+	}
 	return nil
 }
 
+// Observe sets the function called with every entry logged, in the order of
+// their log sequence numbers. It is called with the WAL locked, hence it must
+// not block nor call the WAL.
+func (wal *AOF) Observe(f func(*WALEntry)) {
+	wal.mu.Lock()
+	defer wal.mu.Unlock()
+	wal.observer = f
+}
+
 func (wal *AOF) writeEntryToBuffer(entry *WALEntry) error {
 	marshaledEntry := MustMarshal(entry)
 
@@ -201,7 +244,6 @@ func (wal *AOF) rotateLog() error {
 		if err := wal.deleteOldestSegment(); err != nil {
 			return err
 		}
-		wal.oldestSegmentIndex++
 	}
 
 	newFile, err := os.OpenFile(filepath.Join(wal.logDir, segmentPrefix+fmt.Sprintf("%d", wal.currentSegmentIndex)+segmentSuffix), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
@@ -231,7 +273,16 @@ func (wal *AOF) deleteOldestSegment() error {
 }
 
 // Close the WAL file. It also calls Sync() on the WAL.
+// Closing an already closed WAL is a no-op.
 func (wal *AOF) Close() error {
+	wal.mu.Lock()
+	defer wal.mu.Unlock()
+
+	if wal.closed {
+		return nil
+	}
+	wal.closed = true
+
 	wal.cancel()
 	if err := wal.Sync(); err != nil {
 		return err
@@ -254,7 +305,7 @@ func (wal *AOF) Sync() error {
 	return nil
 }
 
-func (wal *AOF) keepSyncingBuffer() {
+func (wal *AOF) keepSyncingBuffer(ctx context.Context) {
 	for {
 		select {
 		case <-wal.bufferSyncTicker.C:
@@ -266,13 +317,13 @@ func (wal *AOF) keepSyncingBuffer() {
 				slog.Error("failed to sync buffer", slog.String("error", err.Error()))
 			}
 
-		case <-wal.ctx.Done():
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (wal *AOF) rotateSegmentPeriodically() {
+func (wal *AOF) rotateSegmentPeriodically(ctx context.Context) {
 	for {
 		select {
 		case <-wal.segmentRotationTicker.C:
@@ -283,13 +334,13 @@ func (wal *AOF) rotateSegmentPeriodically() {
 				slog.Error("failed to rotate segment", slog.String("error", err.Error()))
 			}
 
-		case <-wal.ctx.Done():
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (wal *AOF) deleteSegmentPeriodically() {
+func (wal *AOF) deleteSegmentPeriodically(ctx context.Context) {
 	for {
 		select {
 		case <-wal.segmentRetentionTicker.C:
@@ -299,7 +350,7 @@ func (wal *AOF) deleteSegmentPeriodically() {
 			if err != nil {
 				slog.Error("failed to delete segment", slog.String("error", err.Error()))
 			}
-		case <-wal.ctx.Done():
+		case <-ctx.Done():
 			return
 		}
 	}
@@ -326,6 +377,48 @@ func (wal *AOF) segmentFiles() ([]string, error) {
 }
 
 func (wal *AOF) Replay(callback func(*WALEntry) error) error {
+	return wal.replay(0, 0, callback)
+}
+
+// ReplayFrom calls the callback with the entries retained in the log whose
+// log sequence number is greater than lsn, up to the last entry logged
+// before the call, in order.
+func (wal *AOF) ReplayFrom(lsn uint64, callback func(*WALEntry) error) error {
+	// The entries are read from the segment files, hence the
+	// buffered ones are written out first.
+	wal.mu.Lock()
+	last := wal.lastSequenceNo
+	err := wal.Sync()
+	wal.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if last <= lsn {
+		return nil
+	}
+
+	return wal.replay(lsn, last, func(entry *WALEntry) error {
+		if entry.LogSequenceNumber <= lsn {
+			return nil
+		}
+		if err := callback(entry); err != nil {
+			return err
+		}
+		if entry.LogSequenceNumber >= last {
+			return errReplayDone
+		}
+		return nil
+	})
+}
+
+// errReplayDone stops the replay before the end of the log.
+var errReplayDone = errors.New("replay done")
+
+// replay calls the callback with the entries of the segments, in order,
+// skipping the segments holding only entries up to lsn, until the callback
+// returns errReplayDone. The segments are scanned up to the last entry if
+// last is 0.
+func (wal *AOF) replay(lsn, last uint64, callback func(*WALEntry) error) error {
 	// Get list of segment files sorted by timestamp
 	segments, err := wal.segmentFiles()
 	if err != nil {
@@ -333,45 +426,108 @@ func (wal *AOF) Replay(callback func(*WALEntry) error) error {
 	}
 
 	// Process each segment file in order
-	for _, segment := range segments {
-		file, err := os.Open(segment)
-		if err != nil {
-			return fmt.Errorf("error opening wal-segment file %s: %w", segment, err)
+	for i, segment := range segments {
+		// The next segment starts at or before the first entry wanted.
+		if i+1 < len(segments) && lsn > 0 {
+			if first, ok := firstSequenceNo(segments[i+1]); ok && first <= lsn+1 {
+				continue
+			}
 		}
 
-		reader := bufio.NewReader(file)
-		for {
-			// Read entry size
-			var entrySize int32
-			if err := binary.Read(reader, binary.LittleEndian, &entrySize); err != nil {
-				if err == io.EOF {
-					break
+		err := readSegment(segment, func(entry *WALEntry) error {
+			// Call provided replay function with parsed command
+			if err := wal.ForEachCommand(entry, callback); err != nil {
+				if errors.Is(err, errReplayDone) {
+					return err
 				}
-				file.Close()
-				return fmt.Errorf("error reading wal entry size: %w", err)
+				return fmt.Errorf("error replaying command: %w", err)
 			}
-
-			// Read entry data
-			entryData := make([]byte, entrySize)
-			if _, err := io.ReadFull(reader, entryData); err != nil {
-				file.Close()
-				return fmt.Errorf("error reading wal entry data: %w", err)
+			return nil
+		})
+		if errors.Is(err, errReplayDone) {
+			return nil
+		}
+		if err != nil {
+			// The entry being written at the end of the log is incomplete.
+			if last == 0 || !errors.Is(err, io.ErrUnexpectedEOF) {
+				return err
 			}
+		}
+	}
 
-			// Unmarshal entry
-			var entry WALEntry
-			MustUnmarshal(entryData, &entry)
+	return nil
+}
 
-			// Call provided replay function with parsed command
-			if err := wal.ForEachCommand(&entry, callback); err != nil {
-				file.Close()
-				return fmt.Errorf("error replaying command: %w", err)
+// readSegment calls the callback with every entry of the segment file, in order.
+func readSegment(segment string, callback func(*WALEntry) error) error {
+	file, err := os.Open(segment)
+	if err != nil {
+		return fmt.Errorf("error opening wal-segment file %s: %w", segment, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		// Read entry size
+		var entrySize int32
+		if err := binary.Read(reader, binary.LittleEndian, &entrySize); err != nil {
+			if err == io.EOF {
+				return nil
 			}
+			return fmt.Errorf("error reading wal entry size: %w", err)
+		}
+
+		// Read entry data
+		entryData := make([]byte, entrySize)
+		if _, err := io.ReadFull(reader, entryData); err != nil {
+			return fmt.Errorf("error reading wal entry data: %w", err)
+		}
+
+		// Unmarshal entry
+		var entry WALEntry
+		MustUnmarshal(entryData, &entry)
+
+		if err := callback(&entry); err != nil {
+			return err
 		}
-		file.Close()
 	}
+}
 
-	return nil
+// firstSequenceNo returns the log sequence number of the first entry of the segment.
+func firstSequenceNo(segment string) (uint64, bool) {
+	var (
+		first uint64
+		found bool
+	)
+	_ = readSegment(segment, func(entry *WALEntry) error {
+		first, found = entry.LogSequenceNumber, true
+		return errReplayDone
+	})
+	return first, found
+}
+
+// lastSequenceNo returns the log sequence number of the last complete entry of the segments.
+func lastSequenceNo(segments []string) uint64 {
+	for i := len(segments) - 1; i >= 0; i-- {
+		var (
+			last  uint64
+			found bool
+		)
+		_ = readSegment(segments[i], func(entry *WALEntry) error {
+			last, found = entry.LogSequenceNumber, true
+			return nil
+		})
+		if found {
+			return last
+		}
+	}
+	return 0
+}
+
+// segmentIndex returns the index of the segment from the name of its file.
+func segmentIndex(segment string) int {
+	index, _ := strconv.Atoi(strings.TrimPrefix(strings.TrimSuffix(filepath.Base(segment), segmentSuffix), segmentPrefix))
+	return index
 }
 
 func (wal *AOF) ForEachCommand(entry *WALEntry, callback func(*WALEntry) error) error {
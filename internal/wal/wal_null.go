@@ -34,3 +34,10 @@ func (w *WALNull) ForEachCommand(entry *WALEntry, callback func(*WALEntry) error
 func (w *WALNull) Replay(callback func(*WALEntry) error) error {
 	return nil
 }
+
+func (w *WALNull) ReplayFrom(lsn uint64, callback func(*WALEntry) error) error {
+	return nil
+}
+
+func (w *WALNull) Observe(f func(*WALEntry)) {
+}
@@ -5,12 +5,120 @@ package wal_test
 
 import (
 	"log/slog"
+	"os"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/wal"
 )
 
+func TestMain(m *testing.M) {
+	config.ForceInit(&config.DiceDBConfig{})
+	os.Exit(m.Run())
+}
+
+func newAOF(t *testing.T, dir string) *wal.AOF {
+	t.Helper()
+	wl, err := wal.NewAOFWAL(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wl.Init(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = wl.Close() })
+	return wl
+}
+
+func logCommands(t *testing.T, wl *wal.AOF, cmds ...string) {
+	t.Helper()
+	for _, c := range cmds {
+		if err := wl.LogCommand([]byte(c)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// replayFrom returns the data of the entries replayed after the LSN, by LSN.
+func replayFrom(t *testing.T, wl *wal.AOF, lsn uint64) map[uint64]string {
+	t.Helper()
+	entries := map[uint64]string{}
+	var lsns []uint64
+	if err := wl.ReplayFrom(lsn, func(e *wal.WALEntry) error {
+		entries[e.LogSequenceNumber] = string(e.Data)
+		lsns = append(lsns, e.LogSequenceNumber)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.IsSorted(lsns) {
+		t.Fatalf("expected the entries in the order of their LSNs, got %v", lsns)
+	}
+	return entries
+}
+
+func TestAOFSequenceNumbersSurviveRestart(t *testing.T) {
+	dir := t.TempDir()
+	wl := newAOF(t, dir)
+	logCommands(t, wl, "SET k1 v1", "SET k2 v2")
+	if err := wl.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The log continues where it stopped, with the next LSN.
+	wl = newAOF(t, dir)
+	logCommands(t, wl, "SET k3 v3")
+	entries := replayFrom(t, wl, 0)
+	expected := map[uint64]string{1: "SET k1 v1", 2: "SET k2 v2", 3: "SET k3 v3"}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, entries)
+	}
+	for lsn, data := range expected {
+		if entries[lsn] != data {
+			t.Fatalf("expected %q at LSN %d, got %v", data, lsn, entries)
+		}
+	}
+
+	// A rotation by Close and Init keeps the LSN as well.
+	if err := wl.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wl.Init(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	logCommands(t, wl, "SET k4 v4")
+	if entries := replayFrom(t, wl, 3); len(entries) != 1 || entries[4] != "SET k4 v4" {
+		t.Fatalf("expected the entry with LSN 4, got %v", entries)
+	}
+}
+
+func TestAOFReplayFrom(t *testing.T) {
+	wl := newAOF(t, t.TempDir())
+	logCommands(t, wl, "SET k1 v1", "SET k2 v2", "SET k3 v3")
+
+	// The buffered entries are replayed as well.
+	if entries := replayFrom(t, wl, 1); len(entries) != 2 || entries[2] != "SET k2 v2" || entries[3] != "SET k3 v3" {
+		t.Fatalf("expected the entries after LSN 1, got %v", entries)
+	}
+	if entries := replayFrom(t, wl, 3); len(entries) != 0 {
+		t.Fatalf("expected no entries after the last one, got %v", entries)
+	}
+}
+
+func TestAOFObserve(t *testing.T) {
+	wl := newAOF(t, t.TempDir())
+	var lsns []uint64
+	wl.Observe(func(e *wal.WALEntry) {
+		lsns = append(lsns, e.LogSequenceNumber)
+	})
+	logCommands(t, wl, "SET k1 v1", "SET k2 v2", "DEL k1")
+	if !slices.Equal(lsns, []uint64{1, 2, 3}) {
+		t.Fatalf("expected the entries to be observed in order, got %v", lsns)
+	}
+}
+
 func BenchmarkLogCommandAOF(b *testing.B) {
 	wl, err := wal.NewAOFWAL("/tmp/dicedb-lt")
 	if err != nil {
@@ -14,7 +14,6 @@ import (
 	"runtime"
 	"runtime/pprof"
 	"runtime/trace"
-	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -23,7 +22,9 @@ import (
 	"github.com/dicedb/dice/internal/cmd"
 	"github.com/dicedb/dice/internal/server/ironhawk"
 	"github.com/dicedb/dice/internal/shardmanager"
+	dstore "github.com/dicedb/dice/internal/store"
 	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/dicedb/dice/internal/wal"
 
@@ -121,7 +122,8 @@ func Start() {
 	// improving concurrency performance across multiple goroutines.
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
-	shardManager := shardmanager.NewShardManager(numShards, serverErrCh)
+	cmdWatchChan := make(chan *dstore.Store, config.WatchChanBufSize)
+	shardManager := shardmanager.NewShardManager(numShards, cmdWatchChan, serverErrCh)
 	watchManager := ironhawk.NewWatchManager()
 
 	wg := sync.WaitGroup{}
@@ -132,6 +134,14 @@ func Start() {
 		shardManager.Run(ctx)
 	}()
 
+	// The watch manager is started before the WAL replay
+	// given that the replayed commands also change the keys.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		watchManager.Run(ctx, cmdWatchChan, shardManager)
+	}()
+
 	var serverWg sync.WaitGroup
 
 	if config.EnableProfile {
@@ -143,22 +153,18 @@ func Start() {
 		defer stopProfiling()
 	}
 
-	ioThreadManager := ironhawk.NewIOThreadManager()
-	ironhawkServer := ironhawk.NewServer(shardManager, ioThreadManager, watchManager)
-
-	serverWg.Add(1)
-	go runServer(ctx, &serverWg, ironhawkServer, serverErrCh)
-
 	// Recovery from WAL logs
+	// The database is restored before the server starts accepting
+	// connections so that clients never observe a partially restored state.
 	if config.Config.EnableWAL {
 		slog.Info("restoring database from WAL")
 		callback := func(entry *wal.WALEntry) error {
-			command := strings.Split(string(entry.Data), " ")
+			c := &wire.Command{}
+			if err := proto.Unmarshal(entry.Data, c); err != nil {
+				return fmt.Errorf("error decoding WAL entry: %w", err)
+			}
 			cmdTemp := cmd.Cmd{
-				C: &wire.Command{
-					Cmd:  command[0],
-					Args: command[1:],
-				},
+				C:        c,
 				IsReplay: true,
 			}
 			_, err := cmdTemp.Execute(shardManager)
@@ -171,8 +177,17 @@ func Start() {
 			slog.Error("error restoring from WAL", slog.Any("error", err))
 		}
 		slog.Info("database restored from WAL")
+
+		// The change stream starts after the restored commands.
+		watchManager.StartCDC(ctx, wl, shardManager)
 	}
 
+	ioThreadManager := ironhawk.NewIOThreadManager()
+	ironhawkServer := ironhawk.NewServer(shardManager, ioThreadManager, watchManager, wl)
+
+	serverWg.Add(1)
+	go runServer(ctx, &serverWg, ironhawkServer, serverErrCh)
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
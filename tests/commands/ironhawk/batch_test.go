@@ -0,0 +1,217 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func batchValue(v *structpb.Value) *structpb.Value {
+	return structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{"value": v}})
+}
+
+func batchErr(err string) *structpb.Value {
+	return structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{"err": structpb.NewStringValue(err)}})
+}
+
+func TestBATCH(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	client.Fire(&wire.Command{Cmd: "FLUSHDB"})
+
+	testCases := []struct {
+		name     string
+		cmd      string
+		expected []*structpb.Value
+		err      string
+	}{
+		{
+			name: "Batch with results in order",
+			cmd:  "BATCH 2 SET k1 v1 2 SET k2 5 1 GET k1 1 INCR k2",
+			expected: []*structpb.Value{
+				batchValue(structpb.NewStringValue("OK")),
+				batchValue(structpb.NewStringValue("OK")),
+				batchValue(structpb.NewStringValue("v1")),
+				batchValue(structpb.NewNumberValue(6)),
+			},
+		},
+		{
+			name: "Batch with a failing command",
+			cmd:  "BATCH 1 GET k1 0 GET 1 GET k2",
+			expected: []*structpb.Value{
+				batchValue(structpb.NewStringValue("v1")),
+				batchErr("wrong number of arguments for 'GET' command"),
+				batchValue(structpb.NewNumberValue(6)),
+			},
+		},
+		{
+			name: "Batch with a cross shard command",
+			cmd:  "BATCH 2 DEL k1 k2 1 GET k1 2 EXISTS k1 k2",
+			expected: []*structpb.Value{
+				batchValue(structpb.NewNumberValue(2)),
+				batchValue(structpb.NewNullValue()),
+				batchValue(structpb.NewNumberValue(0)),
+			},
+		},
+		{
+			name: "Atomic batch",
+			cmd:  "BATCH ATOMIC 2 SET k3 1 1 INCR k3",
+			expected: []*structpb.Value{
+				batchValue(structpb.NewStringValue("OK")),
+				batchValue(structpb.NewNumberValue(2)),
+			},
+		},
+		{
+			name: "Atomic batch with an invalid command",
+			cmd:  "BATCH ATOMIC 1 INCR k3 1 GET.WATCH k3",
+			err:  "'GET.WATCH' command cannot be executed in a batch",
+		},
+		{
+			name: "Atomic batch with invalid arguments",
+			cmd:  "BATCH ATOMIC 2 SET k3 5 2 INCRBY k3 one",
+			err:  "value is not an integer or out of range",
+		},
+		{
+			name: "Batch with commands handled along with the connection",
+			cmd:  "BATCH 2 PUBLISH ch m 0 WATCH.STATS 2 DEBUG SLEEP 0 1 MEMORY STATS",
+			expected: []*structpb.Value{
+				batchErr("'PUBLISH' command cannot be executed in a batch"),
+				batchErr("'WATCH.STATS' command cannot be executed in a batch"),
+				batchErr("'DEBUG' command cannot be executed in a batch"),
+				batchErr("'MEMORY' command cannot be executed in a batch"),
+			},
+		},
+
+		{
+			name: "Atomic batch with a pub/sub command",
+			cmd:  "BATCH ATOMIC 1 INCR k3 1 SUBSCRIBE ch",
+			err:  "'SUBSCRIBE' command cannot be executed in a batch",
+		},
+		{
+			name: "Batch with malformed arguments",
+			cmd:  "BATCH 3 GET k1",
+			err:  "invalid syntax for 'BATCH' command",
+		},
+		{
+			name: "Batch without commands",
+			cmd:  "BATCH ATOMIC",
+			err:  "wrong number of arguments for 'BATCH' command",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens := strings.Split(tc.cmd, " ")
+			res := client.Fire(&wire.Command{Cmd: tokens[0], Args: tokens[1:]})
+			if res.Err != tc.err {
+				t.Fatalf("expected error %q, got %q", tc.err, res.Err)
+			}
+			if len(res.GetVList()) != len(tc.expected) {
+				t.Fatalf("expected %d results, got %d", len(tc.expected), len(res.GetVList()))
+			}
+			for i, v := range res.GetVList() {
+				if !proto.Equal(tc.expected[i], v) {
+					t.Errorf("result %d: expected %v, got %v", i, tc.expected[i], v)
+				}
+			}
+		})
+	}
+
+	// The value of k3 is incremented atomically only if the
+	// batch was not rejected.
+	if r := client.Fire(&wire.Command{Cmd: "GET", Args: []string{"k3"}}); r.GetVInt() != 2 {
+		t.Errorf("expected k3 to be 2, got %v", r)
+	}
+}
+
+func TestBATCHAllShards(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	// The commands operating on all the shards are executed under the locks
+	// of all the shards, the ones the atomic batch holds included.
+	for _, args := range [][]string{
+		{"2", "SET", "k1", "v1", "1", "INFO", "keyspace", "0", "BIGKEYS", "0", "FLUSHDB"},
+		{"ATOMIC", "2", "SET", "k1", "v1", "1", "INFO", "keyspace", "0", "BIGKEYS", "0", "FLUSHDB"},
+	} {
+		res := client.Fire(&wire.Command{Cmd: "BATCH", Args: args})
+		if res.Err != "" || len(res.GetVList()) != 4 {
+			t.Fatalf("expected 4 results, got %v", res)
+		}
+		for i, v := range res.GetVList() {
+			if e := v.GetStructValue().GetFields()["err"]; e != nil {
+				t.Errorf("result %d: unexpected error %v", i, e)
+			}
+		}
+	}
+	if r := client.Fire(&wire.Command{Cmd: "GET", Args: []string{"k1"}}); !r.GetVNil() {
+		t.Errorf("expected k1 to be flushed, got %v", r)
+	}
+}
+
+func TestBATCHUnlinkAcrossShards(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	// The keys are spread across the shards, all of which are locked for
+	// UNLINK whether the batch is atomic or not.
+	for _, atomic := range []bool{false, true} {
+		var keys []string
+		set := []string{}
+		for i := 0; i < 8; i++ {
+			key := "batch-unlink-k" + strconv.Itoa(i)
+			keys = append(keys, key)
+			set = append(set, "2", "SET", key, "v")
+		}
+		args := append(set, strconv.Itoa(len(keys)), "UNLINK")
+		args = append(append(args, keys...), "1", "GET", keys[0])
+		if atomic {
+			args = append([]string{"ATOMIC"}, args...)
+		}
+
+		res := client.Fire(&wire.Command{Cmd: "BATCH", Args: args})
+		if res.Err != "" || len(res.GetVList()) != len(keys)+2 {
+			t.Fatalf("expected %d results, got %v", len(keys)+2, res)
+		}
+		results := res.GetVList()
+		if v := results[len(keys)]; !proto.Equal(v, batchValue(structpb.NewNumberValue(float64(len(keys))))) {
+			t.Errorf("expected UNLINK to delete %d keys, got %v", len(keys), v)
+		}
+		if v := results[len(keys)+1]; !proto.Equal(v, batchValue(structpb.NewNullValue())) {
+			t.Errorf("expected the keys to be unlinked, got %v", v)
+		}
+	}
+}
+
+func TestBATCHObjectEncoding(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	// OBJECT is grouped under the shard of its key, the second argument,
+	// whichever shard the key falls in.
+	for _, atomic := range []bool{false, true} {
+		for i := 0; i < 4; i++ {
+			key := "batch-object-k" + strconv.Itoa(i)
+			args := []string{"3", "HSET", key, "f1", "v1", "2", "OBJECT", "ENCODING", key, "1", "DEL", key}
+			if atomic {
+				args = append([]string{"ATOMIC"}, args...)
+			}
+
+			res := client.Fire(&wire.Command{Cmd: "BATCH", Args: args})
+			if res.Err != "" || len(res.GetVList()) != 3 {
+				t.Fatalf("expected 3 results, got %v", res)
+			}
+			if v := res.GetVList()[1]; !proto.Equal(v, batchValue(structpb.NewStringValue("listpack"))) {
+				t.Errorf("expected the encoding of %s to be listpack, got %v", key, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+func TestBIGKEYS(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "BIGKEYS with wrong arguments",
+			commands: []string{"BIGKEYS COUNT 1 2", "BIGKEYS COUNT -1"},
+			expected: []interface{}{
+				errors.New("wrong number of arguments for 'BIGKEYS' command"),
+				errors.New("invalid value for a parameter in 'BIGKEYS' command for COUNT parameter"),
+			},
+		},
+	})
+
+	client.Fire(&wire.Command{Cmd: "FLUSHDB"})
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"bk:small", "v"}})
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"bk:big", strings.Repeat("v", 1000)}})
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"bk:deleted", strings.Repeat("v", 2000)}})
+	client.Fire(&wire.Command{Cmd: "DEL", Args: []string{"bk:deleted"}})
+	client.Fire(&wire.Command{Cmd: "HSET", Args: []string{"bk:hash", "f1", "v1"}})
+
+	r := client.Fire(&wire.Command{Cmd: "BIGKEYS"})
+	if r.Err != "" {
+		t.Fatalf("BIGKEYS failed: %s", r.Err)
+	}
+	var got []string
+	for _, v := range r.GetVList() {
+		fields := v.GetStructValue().GetFields()
+		if fields["size"].GetNumberValue() <= 0 {
+			t.Fatalf("expected the size of the key, got %v", fields)
+		}
+		got = append(got, fields["type"].GetStringValue()+" "+fields["key"].GetStringValue())
+	}
+	if strings.Join(got, ",") != "ssmap bk:hash,string bk:big" {
+		t.Fatalf("expected the largest key of every type, got %v", got)
+	}
+
+	if n := len(client.Fire(&wire.Command{Cmd: "BIGKEYS", Args: []string{"COUNT", "5"}}).GetVList()); n != 3 {
+		t.Fatalf("expected 3 keys, got %d", n)
+	}
+}
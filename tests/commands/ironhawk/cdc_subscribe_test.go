@@ -0,0 +1,31 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCDCSUBSCRIBE(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "CDC.SUBSCRIBE on a command connection",
+			commands: []string{"CDC.SUBSCRIBE", "CDC.SUBSCRIBE FROM 42 POSTIMAGE"},
+			expected: []interface{}{
+				errors.New("this command requires a connection in watch mode"),
+				errors.New("this command requires a connection in watch mode"),
+			},
+		},
+	})
+
+	// The test server runs without the WAL.
+	conn := newWatchConn(t, "cdc-subscribe")
+	if r := conn.Fire("CDC.SUBSCRIBE", "FROM", "42"); r.Err != "CDC requires the WAL, see enable-wal" {
+		t.Fatalf("expected CDC.SUBSCRIBE to require the WAL, got %v", r)
+	}
+}
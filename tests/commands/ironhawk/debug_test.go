@@ -0,0 +1,99 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+func TestDEBUGOBJECT(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "DEBUG with wrong arguments",
+			commands: []string{"DEBUG", "DEBUG JMAP", "DEBUG OBJECT", "DEBUG OBJECT k1 k2", "DEBUG SLEEP", "DEBUG SLEEP -1", "DEBUG RELOAD now"},
+			expected: []interface{}{
+				errors.New("wrong number of arguments for 'DEBUG' command"),
+				errors.New("invalid syntax for 'DEBUG' command"),
+				errors.New("wrong number of arguments for 'DEBUG OBJECT' command"),
+				errors.New("wrong number of arguments for 'DEBUG OBJECT' command"),
+				errors.New("wrong number of arguments for 'DEBUG SLEEP' command"),
+				errors.New("invalid value for a parameter in 'DEBUG SLEEP' command for SECONDS parameter"),
+				errors.New("wrong number of arguments for 'DEBUG RELOAD' command"),
+			},
+		},
+		{
+			name:     "DEBUG OBJECT for non-existent key",
+			commands: []string{"DEBUG OBJECT dbg:none"},
+			expected: []interface{}{nil},
+		},
+		{
+			name:     "DEBUG OBJECT of a string",
+			commands: []string{"SET dbg:s hello", "DEBUG OBJECT dbg:s"},
+			// The version and the type, the length and the bytes of the
+			// string, the end marker and the checksum.
+			expected: []interface{}{"OK", "type:string encoding:raw serializedlength:20 lru_seconds_idle:0 ttl:-1"},
+		},
+		{
+			name:     "DEBUG OBJECT of a hash",
+			commands: []string{"HSET dbg:h f1 v1", "DEBUG OBJECT dbg:h"},
+			expected: []interface{}{1, "type:ssmap encoding:listpack serializedlength:31 lru_seconds_idle:0 ttl:-1"},
+		},
+	})
+
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"dbg:t", "v", "EX", "100"}})
+	r := client.Fire(&wire.Command{Cmd: "DEBUG", Args: []string{"OBJECT", "dbg:t"}})
+	if v := r.GetVStr(); !strings.HasSuffix(v, " ttl:99") && !strings.HasSuffix(v, " ttl:100") {
+		t.Fatalf("expected a TTL of 100 seconds, got %s", v)
+	}
+}
+
+func TestDEBUGSLEEP(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	start := time.Now()
+	if r := client.Fire(&wire.Command{Cmd: "DEBUG", Args: []string{"SLEEP", "0.2"}}); r.Err != "" {
+		t.Fatalf("DEBUG SLEEP failed: %s", r.Err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("expected DEBUG SLEEP to block for 200ms, got %s", elapsed)
+	}
+}
+
+func TestDEBUGRELOAD(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name: "DEBUG RELOAD keeps the values, their encodings and their TTLs",
+			commands: []string{
+				"SET dbg:s hello", "SET dbg:i 42", "SET dbg:f 1.5", "SET dbg:t v EX 100",
+				"HSET dbg:h f1 v1 f2 v2", "HSET dbg:big f1 " + strings.Repeat("v", 100),
+				"DEBUG RELOAD",
+				"GET dbg:s", "GET dbg:i", "TYPE dbg:f", "TTL dbg:s",
+				"HGET dbg:h f2", "OBJECT ENCODING dbg:h", "OBJECT ENCODING dbg:big", "INCR dbg:i",
+			},
+			expected: []interface{}{
+				"OK", "OK", "OK", "OK",
+				2, 1,
+				"OK",
+				"hello", 42, "float", -1,
+				"v2", "listpack", "hashtable", 43,
+			},
+		},
+	})
+
+	if ttl := client.Fire(&wire.Command{Cmd: "TTL", Args: []string{"dbg:t"}}).GetVInt(); ttl < 99 || ttl > 100 {
+		t.Fatalf("expected the TTL to be kept, got %d", ttl)
+	}
+}
@@ -4,7 +4,11 @@
 package ironhawk
 
 import (
+	"errors"
+	"strconv"
 	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
 )
 
 func TestFLUSHDB(t *testing.T) {
@@ -25,7 +29,55 @@ func TestFLUSHDB(t *testing.T) {
 			},
 			expected: []interface{}{"OK", "OK", "OK", "OK", nil, nil, nil},
 		},
+		{
+			name:     "FLUSHDB ASYNC",
+			commands: []string{"SET k1 v1", "HSET k2 f1 v1", "FLUSHDB ASYNC", "GET k1", "HGET k2 f1"},
+			expected: []interface{}{"OK", 1, "OK", nil, nil},
+		},
+		{
+			name:     "FLUSHDB SYNC",
+			commands: []string{"SET k1 v1", "FLUSHDB sync", "GET k1"},
+			expected: []interface{}{"OK", "OK", nil},
+		},
+		{
+			name:     "FLUSHDB with invalid arguments",
+			commands: []string{"FLUSHDB NOW", "FLUSHDB ASYNC SYNC"},
+			expected: []interface{}{
+				errors.New("invalid syntax for 'FLUSHDB' command"),
+				errors.New("wrong number of arguments for 'FLUSHDB' command"),
+			},
+		},
 	}
 
 	runTestcases(t, client, testCases)
 }
+
+func TestFLUSHDBConcurrentWrites(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+	writer := getLocalConnection()
+	defer writer.Close()
+
+	// FLUSHDB holds the write locks of all the shards, such that the
+	// writes to any shard wait for it rather than interleave with it.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			writer.Fire(&wire.Command{Cmd: "SET", Args: []string{"flush:k" + strconv.Itoa(i), "v"}})
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		client.Fire(&wire.Command{Cmd: "BATCH", Args: []string{"0", "FLUSHDB"}})
+		client.Fire(&wire.Command{Cmd: "FLUSHDB", Args: []string{"ASYNC"}})
+	}
+	<-done
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "FLUSHDB after concurrent writes",
+			commands: []string{"GET flush:k0", "GET flush:k199"},
+			expected: []interface{}{nil, nil},
+		},
+	})
+}
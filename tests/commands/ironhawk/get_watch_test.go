@@ -5,6 +5,7 @@ package ironhawk
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -20,7 +21,178 @@ func TestGETWATCH(t *testing.T) {
 				errors.New("wrong number of arguments for 'GET.WATCH' command"),
 			},
 		},
+		{
+			name: "Get watch subscription with invalid delivery options",
+			commands: []string{
+				"GET.WATCH k DEBOUNCE 1x", "GET.WATCH k THROTTLE 0/s", "GET.WATCH k DEBOUNCE",
+				"GET.WATCH k WHERE ~ 10", "GET.WATCH k WHERE $[ == 10", "GET.WATCH k LEVEL",
+				"GET.WATCH k1 k2",
+			},
+			expected: []interface{}{
+				errors.New("invalid value for a parameter in 'GET.WATCH' command for DEBOUNCE parameter"),
+				errors.New("invalid value for a parameter in 'GET.WATCH' command for THROTTLE parameter"),
+				errors.New("invalid syntax for 'GET.WATCH' command"),
+				errors.New("invalid value for a parameter in 'GET.WATCH' command for WHERE parameter"),
+				errors.New("invalid value for a parameter in 'GET.WATCH' command for WHERE parameter"),
+				errors.New("invalid syntax for 'GET.WATCH' command"),
+				errors.New("invalid syntax for 'GET.WATCH' command"),
+			},
+		},
 	}
 
 	runTestcases(t, client, testCases)
 }
+
+func TestGETWATCHExpiry(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+	client.FireString("FLUSHDB")
+	client.FireString("SET gw:k1 v1 EX 1")
+
+	conn := newWatchConn(t, "get-watch-expiry")
+	assertEqual(t, "v1", conn.Fire("GET.WATCH", "gw:k1"))
+	assertEqual(t, "v1", conn.Read())
+
+	// The key is deleted by the shard cron, without any command accessing it.
+	if r := conn.Read(); !r.GetVNil() {
+		t.Fatalf("expected the expiry to be notified with nil, got %v", r)
+	}
+}
+
+func TestGETWATCHDeliveryOptions(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+	client.FireString("FLUSHDB")
+
+	set := func(key string, values ...string) {
+		for _, v := range values {
+			client.FireString(fmt.Sprintf("SET %s %s", key, v))
+		}
+	}
+	values := make([]string, 20)
+	for i := range values {
+		values[i] = fmt.Sprintf("v%d", i)
+	}
+
+	t.Run("THROTTLE", func(t *testing.T) {
+		conn := newWatchConn(t, "get-watch-throttle")
+		conn.Fire("GET.WATCH", "gd:t", "THROTTLE", "1/s")
+		assertEqual(t, nil, conn.Read())
+
+		// The first modification is sent right away, the
+		// others are coalesced until the end of the interval.
+		set("gd:t", values...)
+		assertEqual(t, "v0", conn.Read())
+		assertEqual(t, "v19", conn.Read())
+		conn.ExpectNone()
+	})
+
+	t.Run("DEBOUNCE", func(t *testing.T) {
+		conn := newWatchConn(t, "get-watch-debounce")
+		conn.Fire("GET.WATCH", "gd:d", "DEBOUNCE", "200ms")
+		assertEqual(t, nil, conn.Read())
+
+		set("gd:d", values...)
+		assertEqual(t, "v19", conn.Read())
+		conn.ExpectNone()
+	})
+
+	t.Run("WHERE EDGE", func(t *testing.T) {
+		conn := newWatchConn(t, "get-watch-where-edge")
+		conn.Fire("GET.WATCH", "gd:w", "WHERE", "<", "10")
+		assertEqual(t, nil, conn.Read())
+
+		// Only the updates making the value drop below 10 are sent.
+		set("gd:w", "20", "5", "3", "15", "2")
+		assertEqual(t, 5, conn.Read())
+		assertEqual(t, 2, conn.Read())
+		conn.ExpectNone()
+	})
+
+	t.Run("WHERE LEVEL", func(t *testing.T) {
+		conn := newWatchConn(t, "get-watch-where-level")
+		conn.Fire("GET.WATCH", "gd:l", "WHERE", "==", "shipped", "LEVEL")
+		assertEqual(t, nil, conn.Read())
+
+		set("gd:l", "pending", "shipped", "shipped", "lost")
+		assertEqual(t, "shipped", conn.Read())
+		assertEqual(t, "shipped", conn.Read())
+		conn.ExpectNone()
+	})
+
+	t.Run("WHERE JSON path", func(t *testing.T) {
+		conn := newWatchConn(t, "get-watch-where-path")
+		conn.Fire("GET.WATCH", "gd:j", "WHERE", "$.stock.count", "<=", "1")
+		assertEqual(t, nil, conn.Read())
+
+		set("gd:j", `{"stock":{"count":4}}`, `{"stock":{"count":1}}`)
+		assertEqual(t, `{"stock":{"count":1}}`, conn.Read())
+		conn.ExpectNone()
+	})
+
+	t.Run("ONLYCHANGED", func(t *testing.T) {
+		conn := newWatchConn(t, "get-watch-onlychanged")
+		conn.Fire("GET.WATCH", "gd:o", "ONLYCHANGED")
+		assertEqual(t, nil, conn.Read())
+
+		set("gd:o", "v1", "v1", "v2", "v2")
+		assertEqual(t, "v1", conn.Read())
+		assertEqual(t, "v2", conn.Read())
+		conn.ExpectNone()
+	})
+}
+
+func TestGETWATCHKeysNamedAfterOptions(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+	client.FireString("DEL edge h onlychanged")
+
+	// The keys and the fields named after the delivery options are
+	// arguments of the command, not options.
+	conn := newWatchConn(t, "get-watch-option-keys")
+	var fps []string
+	watch := func(cmd string, args ...string) {
+		t.Helper()
+		r := conn.Fire(cmd, args...)
+		assertEqual(t, nil, r)
+		assertEqual(t, nil, conn.Read())
+		fps = append(fps, r.GetAttrs().GetFields()["fingerprint"].GetStringValue())
+	}
+	// The subscriptions are removed for the test to start afresh when rerun.
+	t.Cleanup(func() {
+		for _, fp := range fps {
+			conn.Fire("UNWATCH", fp)
+		}
+		client.FireString("DEL edge h onlychanged")
+	})
+	watch("GET.WATCH", "edge")
+	watch("HGET.WATCH", "h", "level")
+	watch("HGET.WATCH", "onlychanged", "f", "ONLYCHANGED")
+
+	client.FireString("SET edge v1")
+	assertEqual(t, "v1", conn.Read())
+	client.FireString("HSET h level high")
+	assertEqual(t, "high", conn.Read())
+	client.FireString("HSET onlychanged f v2")
+	client.FireString("HSET onlychanged f v2")
+	assertEqual(t, "v2", conn.Read())
+	conn.ExpectNone()
+}
+
+func TestMGETWATCHDeliveryOptions(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+	client.FireString("FLUSHDB")
+
+	// The options of the commands taking any number of keys follow --.
+	conn := newWatchConn(t, "mget-watch-options")
+	conn.Fire("MGET.WATCH", "mw:k1", "onlychanged", "--", "ONLYCHANGED")
+	conn.Read()
+
+	client.FireString("SET mw:k1 v1")
+	if r := conn.Read(); len(r.GetVList()) != 2 || r.GetVList()[0].GetStringValue() != "v1" {
+		t.Fatalf("expected the values of both keys, got %v", r)
+	}
+	client.FireString("SET mw:k1 v1")
+	conn.ExpectNone()
+}
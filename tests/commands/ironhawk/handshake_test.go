@@ -0,0 +1,91 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+func attr(r *wire.Response, name string) string {
+	return r.GetAttrs().GetFields()[name].GetStringValue()
+}
+
+func seq(t *testing.T, r *wire.Response) uint64 {
+	t.Helper()
+	n, err := strconv.ParseUint(attr(r, "seq"), 10, 64)
+	if err != nil {
+		t.Fatalf("expected a sequence number, got %v", r)
+	}
+	return n
+}
+
+func TestHANDSHAKEResume(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+	client.Fire(&wire.Command{Cmd: "DEL", Args: []string{"resume:k"}})
+
+	conn := newWatchConn(t, "resume")
+	fp := attr(conn.Fire("GET.WATCH", "resume:k"), "fingerprint")
+	last := seq(t, conn.Read())
+
+	client.FireString("SET resume:k v1")
+	if r := conn.Read(); r.GetVStr() != "v1" || seq(t, r) != last+1 {
+		t.Fatalf("expected v1 with seq %d, got %v", last+1, r)
+	}
+	last++
+
+	// The updates made while the client is disconnected are replayed in order.
+	conn.conn.Close()
+	client.FireString("SET resume:k v2")
+	client.FireString("SET resume:k v3")
+
+	conn = newWatchConn(t, "resume", "RESUME", fp+":"+strconv.FormatUint(last, 10))
+	for _, v := range []string{"v2", "v3"} {
+		last++
+		r := conn.Read()
+		if r.GetVStr() != v || seq(t, r) != last || attr(r, "fingerprint") != fp {
+			t.Fatalf("expected %s with seq %d, got %v", v, last, r)
+		}
+	}
+
+	// The resumed subscription gets the new updates.
+	client.FireString("SET resume:k v4")
+	if r := conn.Read(); r.GetVStr() != "v4" || seq(t, r) != last+1 {
+		t.Fatalf("expected v4 with seq %d, got %v", last+1, r)
+	}
+	last++
+
+	// Resuming from updates not retained sends the current value to refresh.
+	refresh := newWatchConn(t, "resume-refresh", "RESUME", fp+":"+strconv.FormatUint(last+100, 10))
+	r := refresh.Read()
+	if r.GetVStr() != "v4" || seq(t, r) != last || !r.GetAttrs().GetFields()["refresh"].GetBoolValue() {
+		t.Fatalf("expected a refresh with v4 and seq %d, got %v", last, r)
+	}
+
+	// Resuming an unknown subscription asks the client to re-issue the .WATCH command.
+	unknown := newWatchConn(t, "resume-unknown", "RESUME", "1:0")
+	if r := unknown.Read(); r.Err == "" || !r.GetAttrs().GetFields()["refresh"].GetBoolValue() {
+		t.Fatalf("expected an error to refresh, got %v", r)
+	}
+}
+
+func TestHANDSHAKEResumeSyntax(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	for _, args := range [][]string{
+		{"c1", "command", "RESUME", "1:0"},
+		{"c1", "watch", "RESUME"},
+		{"c1", "watch", "RESUME", "1"},
+		{"c1", "watch", "RESUME", "x:0"},
+		{"c1", "watch", "FRAMED", "FRAMED"},
+	} {
+		if r := client.Fire(&wire.Command{Cmd: "HANDSHAKE", Args: args}); r.Err == "" {
+			t.Errorf("expected an error for HANDSHAKE %v, got %v", args, r)
+		}
+	}
+}
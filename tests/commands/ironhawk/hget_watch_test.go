@@ -31,3 +31,20 @@ func TestHGETWATCH(t *testing.T) {
 
 	runTestcases(t, client, testCases)
 }
+
+func TestHGETALLWATCHWhere(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+	client.FireString("DEL hw:order")
+
+	conn := newWatchConn(t, "hgetall-watch-where")
+	conn.Fire("HGETALL.WATCH", "hw:order", "WHERE", "$.status", "==", "shipped")
+	conn.Read()
+
+	client.FireString("HSET hw:order status pending")
+	client.FireString("HSET hw:order status shipped")
+	if r := conn.Read(); r.VSsMap["status"] != "shipped" {
+		t.Fatalf("expected the order to be shipped, got %v", r)
+	}
+	conn.ExpectNone()
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+func TestHOTKEYS(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "HOTKEYS with wrong arguments",
+			commands: []string{"HOTKEYS COUNT", "HOTKEYS LIMIT 1", "HOTKEYS COUNT 0", "HOTKEYS COUNT one"},
+			expected: []interface{}{
+				errors.New("wrong number of arguments for 'HOTKEYS' command"),
+				errors.New("invalid syntax for 'HOTKEYS' command"),
+				errors.New("invalid value for a parameter in 'HOTKEYS' command for COUNT parameter"),
+				errors.New("invalid value for a parameter in 'HOTKEYS' command for COUNT parameter"),
+			},
+		},
+	})
+
+	client.Fire(&wire.Command{Cmd: "FLUSHDB"})
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"hk:hot", "v"}})
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"hk:cold", "v"}})
+	for i := 0; i < 2000; i++ {
+		client.Fire(&wire.Command{Cmd: "GET", Args: []string{"hk:hot"}})
+	}
+
+	// The accesses being sampled, the frequency of the key is an estimate.
+	r := client.Fire(&wire.Command{Cmd: "HOTKEYS", Args: []string{"COUNT", "1"}})
+	if r.Err != "" {
+		t.Fatalf("HOTKEYS failed: %s", r.Err)
+	}
+	found := false
+	for _, v := range r.GetVList() {
+		fields := v.GetStructValue().GetFields()
+		if fields["key"].GetStringValue() == "hk:hot" {
+			found = true
+			if f := fields["frequency"].GetNumberValue(); f < 500 {
+				t.Fatalf("expected a frequency close to 2000, got %v", f)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected hk:hot to be a hot key, got %v", r.GetVList())
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+func TestINFO(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "INFO with wrong arguments",
+			commands: []string{"INFO keyspace memory", "INFO nosection"},
+			expected: []interface{}{
+				errors.New("wrong number of arguments for 'INFO' command"),
+				errors.New("invalid value for a parameter in 'INFO' command for SECTION parameter"),
+			},
+		},
+	})
+
+	keyspace := func() map[string]string {
+		t.Helper()
+		r := client.Fire(&wire.Command{Cmd: "INFO", Args: []string{"keyspace"}})
+		if r.Err != "" {
+			t.Fatalf("INFO failed: %s", r.Err)
+		}
+		return r.VSsMap
+	}
+	expires, _ := strconv.Atoi(keyspace()["expires"])
+
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"info:k1", "v1", "EX", "100"}})
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"info:k2", "v2"}})
+	stats := keyspace()
+	if n, _ := strconv.Atoi(stats["expires"]); n != expires+1 {
+		t.Fatalf("expected %d keys with a TTL, got %v", expires+1, stats)
+	}
+	if ttl, _ := strconv.Atoi(stats["avg_ttl"]); ttl <= 0 {
+		t.Fatalf("expected a positive average TTL, got %v", stats)
+	}
+	if _, ok := client.Fire(&wire.Command{Cmd: "INFO"}).VSsMap["expired_per_sec"]; !ok {
+		t.Fatalf("expected the fields of all the sections")
+	}
+	client.Fire(&wire.Command{Cmd: "DEL", Args: []string{"info:k1", "info:k2"}})
+}
+
+func TestINFOLimits(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	limits := func() map[string]string {
+		t.Helper()
+		r := client.Fire(&wire.Command{Cmd: "INFO", Args: []string{"limits"}})
+		if r.Err != "" {
+			t.Fatalf("INFO failed: %s", r.Err)
+		}
+		return r.VSsMap
+	}
+	stats := limits()
+	keys, _ := strconv.Atoi(stats["keys"])
+	for _, field := range []string{"max_keys", "shard_0_keys", "shard_0_max_keys", "shard_0_used_memory", "shard_0_max_memory"} {
+		if _, ok := stats[field]; !ok {
+			t.Fatalf("expected the field %s, got %v", field, stats)
+		}
+	}
+
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"info:limits", "v"}})
+	if n, _ := strconv.Atoi(limits()["keys"]); n != keys+1 {
+		t.Fatalf("expected %d keys, got %d", keys+1, n)
+	}
+	client.Fire(&wire.Command{Cmd: "DEL", Args: []string{"info:limits"}})
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+func assertKeyUpdate(t *testing.T, r *wire.Response, fp, key, operation string) {
+	t.Helper()
+	fields := r.GetAttrs().GetFields()
+	if fields["fingerprint"].GetStringValue() != fp || fields["key"].GetStringValue() != key ||
+		fields["operation"].GetStringValue() != operation {
+		t.Fatalf("expected an update for %s on %s, got %v", operation, key, r)
+	}
+}
+
+func TestKEYSWATCH(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "KEYS.WATCH without pattern",
+			commands: []string{"KEYS.WATCH"},
+			expected: []interface{}{errors.New("wrong number of arguments for 'KEYS.WATCH' command")},
+		},
+	})
+
+	conn := newWatchConn(t, "keys-watch")
+	r := conn.Fire("KEYS.WATCH", "user:*:profile")
+	fp := r.GetAttrs().GetFields()["fingerprint"].GetStringValue()
+	if r.GetVStr() != "OK" || fp == "" {
+		t.Fatalf("expected OK with a fingerprint, got %v", r)
+	}
+
+	client.FireString("SET user:1:profile alice")
+	r = conn.Read()
+	assertKeyUpdate(t, r, fp, "user:1:profile", "SET")
+	assertEqual(t, "alice", r)
+
+	// Keys not matching the pattern and reads are not sent.
+	client.FireString("SET user:1:settings dark")
+	client.FireString("GET user:1:profile")
+
+	client.FireString("HSET user:2:profile name bob")
+	r = conn.Read()
+	assertKeyUpdate(t, r, fp, "user:2:profile", "HSET")
+	if r.GetVSsMap()["name"] != "bob" {
+		t.Fatalf("expected the map value, got %v", r)
+	}
+
+	client.FireString("DEL user:1:settings user:1:profile user:2:profile")
+	for _, key := range []string{"user:1:profile", "user:2:profile"} {
+		r = conn.Read()
+		assertKeyUpdate(t, r, fp, key, "DEL")
+		assertEqual(t, nil, r)
+	}
+
+	assertEqual(t, "OK", conn.Fire("UNWATCH", fp))
+	client.FireString("SET user:3:profile carol")
+	conn.ExpectNone()
+}
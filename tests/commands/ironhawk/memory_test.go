@@ -0,0 +1,146 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+func TestMEMORYUSAGE(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "MEMORY with wrong arguments",
+			commands: []string{"MEMORY", "MEMORY STATUS", "MEMORY USAGE", "MEMORY USAGE k1 k2", "MEMORY USAGE k1 COUNT 5", "MEMORY USAGE k1 SAMPLES -1", "MEMORY STATS k1"},
+			expected: []interface{}{
+				errors.New("wrong number of arguments for 'MEMORY' command"),
+				errors.New("invalid syntax for 'MEMORY' command"),
+				errors.New("wrong number of arguments for 'MEMORY USAGE' command"),
+				errors.New("wrong number of arguments for 'MEMORY USAGE' command"),
+				errors.New("invalid syntax for 'MEMORY USAGE' command"),
+				errors.New("invalid value for a parameter in 'MEMORY USAGE' command for SAMPLES parameter"),
+				errors.New("wrong number of arguments for 'MEMORY STATS' command"),
+			},
+		},
+		{
+			name:     "MEMORY USAGE for non-existent key",
+			commands: []string{"MEMORY USAGE mem:none"},
+			expected: []interface{}{nil},
+		},
+	})
+
+	usage := func(key string) int64 {
+		t.Helper()
+		r := client.Fire(&wire.Command{Cmd: "MEMORY", Args: []string{"USAGE", key}})
+		if r.Err != "" {
+			t.Fatalf("MEMORY USAGE failed: %s", r.Err)
+		}
+		return r.GetVInt()
+	}
+
+	// The usage grows with the size of the value.
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"mem:k", "v"}})
+	small := usage("mem:k")
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"mem:k", strings.Repeat("v", 1001)}})
+	if large := usage("mem:k"); small <= 0 || large-small != 1000 {
+		t.Fatalf("expected the usage to grow by 1000 bytes from %d, got %d", small, large)
+	}
+
+	// The fields of a hash are accounted for once they are set.
+	client.Fire(&wire.Command{Cmd: "HSET", Args: []string{"mem:h", "f1", "v1"}})
+	before := usage("mem:h")
+	client.Fire(&wire.Command{Cmd: "HSET", Args: []string{"mem:h", "f2", strings.Repeat("v", 100)}})
+	if after := usage("mem:h"); after-before < 100 {
+		t.Fatalf("expected the usage of the hash to grow by at least 100 bytes from %d, got %d", before, after)
+	}
+	client.Fire(&wire.Command{Cmd: "DEL", Args: []string{"mem:k", "mem:h"}})
+}
+
+func TestMEMORYUSAGESAMPLES(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+	client.Fire(&wire.Command{Cmd: "FLUSHDB"})
+
+	usage := func(args ...string) int64 {
+		t.Helper()
+		r := client.Fire(&wire.Command{Cmd: "MEMORY", Args: append([]string{"USAGE", "mem:h"}, args...)})
+		if r.Err != "" {
+			t.Fatalf("MEMORY USAGE failed: %s", r.Err)
+		}
+		return r.GetVInt()
+	}
+
+	// The usage estimated from all the fields of a hash is the one accounted
+	// for, and the one estimated from a few of them is close to it.
+	for i := 0; i < 200; i++ {
+		f := strconv.Itoa(i)
+		client.Fire(&wire.Command{Cmd: "HSET", Args: []string{"mem:h", "field" + f, "value" + f}})
+	}
+	accounted := usage()
+	if all := usage("SAMPLES", "0"); all != accounted {
+		t.Fatalf("expected the usage from all the fields to be %d, got %d", accounted, all)
+	}
+	if sampled := usage("SAMPLES", "5"); sampled < accounted*9/10 || sampled > accounted*11/10 {
+		t.Fatalf("expected the sampled usage to be within 10%% of %d, got %d", accounted, sampled)
+	}
+}
+
+func TestMEMORYSTATS(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+	client.Fire(&wire.Command{Cmd: "FLUSHDB"})
+
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"mem:s1", "v1"}})
+	client.Fire(&wire.Command{Cmd: "SET", Args: []string{"mem:s2", "v2"}})
+	client.Fire(&wire.Command{Cmd: "HSET", Args: []string{"mem:h", "f1", "v1"}})
+
+	r := client.Fire(&wire.Command{Cmd: "MEMORY", Args: []string{"STATS"}})
+	if r.Err != "" {
+		t.Fatalf("MEMORY STATS failed: %s", r.Err)
+	}
+	stats := r.VSsMap
+	if stats["keys.count"] != "3" || stats["type.string.keys"] != "2" || stats["type.ssmap.keys"] != "1" {
+		t.Fatalf("expected 3 keys, 2 strings and a hash, got %v", stats)
+	}
+
+	// The usage of the shards and of the types adds up to the dataset.
+	var shards, types int
+	for k, v := range stats {
+		n, _ := strconv.Atoi(v)
+		switch {
+		case strings.HasPrefix(k, "shard.") && strings.HasSuffix(k, ".dataset.bytes"):
+			shards += n
+		case strings.HasPrefix(k, "type.") && strings.HasSuffix(k, ".bytes"):
+			types += n
+		}
+	}
+	if dataset, _ := strconv.Atoi(stats["dataset.bytes"]); dataset == 0 || shards != dataset || types != dataset {
+		t.Fatalf("expected the shards and the types to add up to the dataset, got %v", stats)
+	}
+}
+
+func TestMEMORYDOCTOR(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "MEMORY DOCTOR for an empty dataset",
+			commands: []string{"MEMORY DOCTOR"},
+			expected: []interface{}{"the dataset is empty, no memory issue found"},
+		},
+		{
+			name:     "MEMORY DOCTOR for a small dataset",
+			commands: []string{"SET mem:k v", "MEMORY DOCTOR", "MEMORY DOCTOR now"},
+			expected: []interface{}{"OK", "no memory issue found", errors.New("wrong number of arguments for 'MEMORY DOCTOR' command")},
+		},
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func assertList(t *testing.T, expected []*structpb.Value, actual *wire.Response) {
+	t.Helper()
+	if actual.Err != "" {
+		t.Fatalf("expected %v, got error %s", expected, actual.Err)
+	}
+	if !proto.Equal(&structpb.ListValue{Values: expected}, &structpb.ListValue{Values: actual.VList}) {
+		t.Errorf("expected %v, got %v", expected, actual.VList)
+	}
+}
+
+func TestMGET(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "MGET without keys",
+			commands: []string{"MGET"},
+			expected: []interface{}{errors.New("wrong number of arguments for 'MGET' command")},
+		},
+	})
+
+	client.FireString("SET k1 v1")
+	client.FireString("SET k2 2")
+	client.FireString("HSET k3 f1 v1")
+	assertList(t, []*structpb.Value{
+		structpb.NewStringValue("v1"),
+		structpb.NewNumberValue(2),
+		structpb.NewNullValue(),
+		structpb.NewNullValue(),
+		structpb.NewStringValue("v1"),
+	}, client.FireString("MGET k1 k2 k3 k4 k1"))
+}
+
+func TestMGETWATCH(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+	client.Fire(&wire.Command{Cmd: "FLUSHDB"})
+
+	// The keys are spread over the shards so that the
+	// subscription spans the keys owned by multiple shards.
+	keys := []string{"mw:1", "mw:2", "mw:3", "mw:4"}
+
+	conn := newWatchConn(t, "mget-watch")
+
+	values := []*structpb.Value{
+		structpb.NewNullValue(), structpb.NewNullValue(),
+		structpb.NewNullValue(), structpb.NewNullValue(),
+	}
+	r := conn.Fire("MGET.WATCH", keys...)
+	fp := r.GetAttrs().GetFields()["fingerprint"].GetStringValue()
+	if fp == "" {
+		t.Fatalf("expected a fingerprint, got %v", r)
+	}
+	assertList(t, values, r)
+	// The initial output is also sent as a watch update.
+	assertList(t, values, conn.Read())
+
+	for i, key := range keys {
+		client.FireString(fmt.Sprintf("SET %s v%d", key, i))
+		values[i] = structpb.NewStringValue(fmt.Sprintf("v%d", i))
+
+		r := conn.Read()
+		if got := r.GetAttrs().GetFields()["fingerprint"].GetStringValue(); got != fp {
+			t.Fatalf("expected fingerprint %s, got %s", fp, got)
+		}
+		assertList(t, values, r)
+	}
+
+	// Deleting multiple watched keys at once notifies the subscription once.
+	client.FireString("DEL " + strings.Join(keys, " "))
+	for i := range values {
+		values[i] = structpb.NewNullValue()
+	}
+	assertList(t, values, conn.Read())
+	conn.ExpectNone()
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+func TestOBJECTENCODING(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "OBJECT with wrong arguments",
+			commands: []string{"OBJECT", "OBJECT FREQ k1", "OBJECT ENCODING", "OBJECT ENCODING k1 k2"},
+			expected: []interface{}{
+				errors.New("wrong number of arguments for 'OBJECT' command"),
+				errors.New("invalid syntax for 'OBJECT' command"),
+				errors.New("wrong number of arguments for 'OBJECT ENCODING' command"),
+				errors.New("wrong number of arguments for 'OBJECT ENCODING' command"),
+			},
+		},
+		{
+			name:     "OBJECT ENCODING for non-existent key",
+			commands: []string{"OBJECT ENCODING obj:none"},
+			expected: []interface{}{nil},
+		},
+		{
+			name:     "OBJECT ENCODING of a string",
+			commands: []string{"SET obj:s v", "OBJECT ENCODING obj:s"},
+			expected: []interface{}{"OK", "raw"},
+		},
+		{
+			name:     "OBJECT ENCODING of a hash with a long value",
+			commands: []string{"HSET obj:h f1 v1", "OBJECT ENCODING obj:h", "HSET obj:h f2 " + strings.Repeat("v", 65), "OBJECT ENCODING obj:h", "HGET obj:h f1"},
+			expected: []interface{}{1, "listpack", 1, "hashtable", "v1"},
+		},
+	})
+
+	// A hash is stored as a listpack up to 128 fields.
+	for i := 1; i <= 129; i++ {
+		f := strconv.Itoa(i)
+		client.Fire(&wire.Command{Cmd: "HSET", Args: []string{"obj:big", "f" + f, "v" + f}})
+		want := "listpack"
+		if i > 128 {
+			want = "hashtable"
+		}
+		if enc := client.Fire(&wire.Command{Cmd: "OBJECT", Args: []string{"ENCODING", "obj:big"}}).GetVStr(); enc != want {
+			t.Fatalf("expected a %s at %d fields, got %s", want, i, enc)
+		}
+	}
+	if v := client.Fire(&wire.Command{Cmd: "HGET", Args: []string{"obj:big", "f7"}}).GetVStr(); v != "v7" {
+		t.Fatalf("expected v7, got %s", v)
+	}
+	client.Fire(&wire.Command{Cmd: "DEL", Args: []string{"obj:h", "obj:big"}})
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+func TestPUBLISH(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "pub/sub commands with wrong arguments",
+			commands: []string{"PUBLISH ps:chat", "SUBSCRIBE", "PSUBSCRIBE", "PUBSUB", "PUBSUB LIST"},
+			expected: []interface{}{
+				errors.New("wrong number of arguments for 'PUBLISH' command"),
+				errors.New("wrong number of arguments for 'SUBSCRIBE' command"),
+				errors.New("wrong number of arguments for 'PSUBSCRIBE' command"),
+				errors.New("wrong number of arguments for 'PUBSUB' command"),
+				errors.New("invalid syntax for 'PUBSUB' command"),
+			},
+		},
+		{
+			name:     "PUBLISH without subscribers",
+			commands: []string{"PUBLISH ps:nobody hello"},
+			expected: []interface{}{0},
+		},
+	})
+
+	conn := newWatchConn(t, "pubsub")
+	if r := conn.Fire("SUBSCRIBE", "ps:chat"); r.GetVInt() != 1 {
+		t.Fatalf("expected 1 subscription, got %v", r)
+	}
+	if r := conn.Fire("PSUBSCRIBE", "ps:news.*"); r.GetVInt() != 2 {
+		t.Fatalf("expected 2 subscriptions, got %v", r)
+	}
+
+	if r := client.Fire(&wire.Command{Cmd: "PUBSUB", Args: []string{"NUMSUB", "ps:chat"}}); r.VSsMap["ps:chat"] != "1" {
+		t.Fatalf("expected 1 subscriber to ps:chat, got %v", r)
+	}
+
+	// The messages are sent to the watch connection, in order.
+	for _, m := range [][2]string{{"ps:chat", "hello"}, {"ps:news.today", "extra"}, {"ps:chat", "bye"}} {
+		if r := client.Fire(&wire.Command{Cmd: "PUBLISH", Args: []string{m[0], m[1]}}); r.GetVInt() != 1 {
+			t.Fatalf("expected the message to be sent to 1 client, got %v", r)
+		}
+	}
+	for _, m := range [][2]string{{"ps:chat", "hello"}, {"ps:news.today", "extra"}, {"ps:chat", "bye"}} {
+		r := conn.Read()
+		if r.GetVStr() != m[1] || attr(r, "channel") != m[0] {
+			t.Fatalf("expected the message %s on %s, got %v", m[1], m[0], r)
+		}
+	}
+
+	if r := conn.Fire("UNSUBSCRIBE"); r.GetVInt() != 1 {
+		t.Fatalf("expected 1 subscription left, got %v", r)
+	}
+	if r := client.Fire(&wire.Command{Cmd: "PUBLISH", Args: []string{"ps:chat", "gone"}}); r.GetVInt() != 0 {
+		t.Fatalf("expected no subscribers to ps:chat, got %v", r)
+	}
+}
@@ -4,21 +4,30 @@
 package ironhawk
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"os"
 	"sync"
+	"testing"
 	"time"
 
 	"github.com/dicedb/dice/internal/server/ironhawk"
 	"github.com/dicedb/dice/internal/shardmanager"
+	"github.com/dicedb/dice/internal/wal"
 
 	"github.com/dicedb/dice/config"
 	derrors "github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dicedb-go"
+	diceio "github.com/dicedb/dicedb-go/ironhawk"
+	"github.com/dicedb/dicedb-go/wire"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
 )
 
 //nolint:unused
@@ -105,14 +114,78 @@ func getLocalSdk() *dicedb.Client {
 // 	return nil
 // }
 
+// watchConn is a framed connection in the watch mode, on which the
+// updates sent back to back by the server can be told apart.
+type watchConn struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// newWatchConn connects in the watch mode, passing the
+// arguments, if any, to the HANDSHAKE after FRAMED.
+func newWatchConn(t *testing.T, clientID string, args ...string) *watchConn {
+	t.Helper()
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", config.Config.Port))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if err := diceio.Write(conn, &wire.Command{Cmd: "HANDSHAKE", Args: append([]string{clientID, "watch", "FRAMED"}, args...)}); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	// The unframed HANDSHAKE response may be followed right away
+	// by the frames of the resumed updates, hence only its bytes are read.
+	ok, _ := proto.Marshal(&wire.Response{Value: &wire.Response_VStr{VStr: "OK"}})
+	r := bufio.NewReader(conn)
+	buf := make([]byte, len(ok))
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	if _, err := io.ReadFull(r, buf); err != nil || !bytes.Equal(buf, ok) {
+		t.Fatalf("handshake failed: %q %v", buf, err)
+	}
+	return &watchConn{t: t, conn: conn, r: r}
+}
+
+// Fire sends the command and returns its response.
+func (w *watchConn) Fire(cmd string, args ...string) *wire.Response {
+	w.t.Helper()
+	if _, err := protodelim.MarshalTo(w.conn, &wire.Command{Cmd: cmd, Args: args}); err != nil {
+		w.t.Fatalf("failed to write: %v", err)
+	}
+	return w.Read()
+}
+
+// Read returns the next response or update.
+func (w *watchConn) Read() *wire.Response {
+	w.t.Helper()
+	_ = w.conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	r := &wire.Response{}
+	if err := protodelim.UnmarshalFrom(w.r, r); err != nil {
+		w.t.Fatalf("failed to read: %v", err)
+	}
+	return r
+}
+
+// ExpectNone fails the test if an update is received within a short while.
+func (w *watchConn) ExpectNone() {
+	w.t.Helper()
+	_ = w.conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if r := (&wire.Response{}); protodelim.UnmarshalFrom(w.r, r) == nil {
+		w.t.Fatalf("expected no update, got %v", r)
+	}
+}
+
 func RunTestServer(wg *sync.WaitGroup) {
 	// #1261: Added here to prevent resp integration tests from failing on lower-spec machines
 	gec := make(chan error)
-	shardManager := shardmanager.NewShardManager(1, gec)
+	shardManager := shardmanager.NewShardManager(1, nil, gec)
 	ioThreadManager := ironhawk.NewIOThreadManager()
 	watchManager := &ironhawk.WatchManager{}
 
-	testServer := ironhawk.NewServer(shardManager, ioThreadManager, watchManager)
+	wl, _ := wal.NewNullWAL()
+	testServer := ironhawk.NewServer(shardManager, ioThreadManager, watchManager, wl)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	fmt.Println("Starting the test server on port", config.Config.Port)
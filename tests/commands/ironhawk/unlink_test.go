@@ -0,0 +1,60 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+func TestUNLINK(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "UNLINK with set key",
+			commands: []string{"SET k1 v1", "UNLINK k1", "GET k1"},
+			expected: []interface{}{"OK", 1, nil},
+		},
+		{
+			name:     "UNLINK multiple keys",
+			commands: []string{"SET k1 v1", "HSET k2 f1 v1", "UNLINK k1 k2 k3", "GET k1", "HGET k2 f1"},
+			expected: []interface{}{"OK", 1, 2, nil, nil},
+		},
+		{
+			name:     "UNLINK with no keys",
+			commands: []string{"UNLINK"},
+			expected: []interface{}{errors.New("wrong number of arguments for 'UNLINK' command")},
+		},
+	})
+
+	// A large hash is freed in the background, as reported by INFO memory.
+	freed := func() int {
+		t.Helper()
+		r := client.Fire(&wire.Command{Cmd: "INFO", Args: []string{"memory"}})
+		n, _ := strconv.Atoi(r.VSsMap["lazyfreed_objects"])
+		return n
+	}
+	before := freed()
+	args := []string{"unlink:h"}
+	for i := 0; i < 1000; i++ {
+		args = append(args, "f"+strconv.Itoa(i), "v")
+	}
+	client.Fire(&wire.Command{Cmd: "HSET", Args: args})
+	if n := client.Fire(&wire.Command{Cmd: "UNLINK", Args: []string{"unlink:h"}}).GetVInt(); n != 1 {
+		t.Fatalf("expected a key to be unlinked, got %d", n)
+	}
+	deadline := time.Now().Add(time.Second)
+	for freed() != before+1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the hash to be freed in the background")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package ironhawk
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+// listed returns the subscription with the fingerprint listed by WATCH.LIST, nil if none.
+func listed(t *testing.T, r *wire.Response, fp string) map[string]interface{} {
+	t.Helper()
+	if r.Err != "" {
+		t.Fatalf("WATCH.LIST failed: %s", r.Err)
+	}
+	for _, v := range r.VList {
+		if s := v.GetStructValue().AsMap(); s["fingerprint"] == fp {
+			return s
+		}
+	}
+	return nil
+}
+
+func TestWATCHLIST(t *testing.T) {
+	client := getLocalConnection()
+	defer client.Close()
+
+	runTestcases(t, client, []TestCase{
+		{
+			name:     "WATCH.LIST and WATCH.STATS with arguments",
+			commands: []string{"WATCH.LIST x", "WATCH.STATS x"},
+			expected: []interface{}{
+				errors.New("wrong number of arguments for 'WATCH.LIST' command"),
+				errors.New("wrong number of arguments for 'WATCH.STATS' command"),
+			},
+		},
+	})
+
+	conn := newWatchConn(t, "watch-list")
+	fp := attr(conn.Fire("GET.WATCH", "wl:k"), "fingerprint")
+	conn.Read()
+
+	s := listed(t, client.Fire(&wire.Command{Cmd: "WATCH.LIST"}), fp)
+	if s == nil || s["command"] != "GET.WATCH wl:k" || s["orphaned"] != false {
+		t.Fatalf("expected the subscription to be listed, got %v", s)
+	}
+	if clients, _ := s["clients"].([]interface{}); len(clients) != 1 || clients[0] != "watch-list" {
+		t.Fatalf("expected the client to be listed, got %v", s["clients"])
+	}
+
+	stats := client.Fire(&wire.Command{Cmd: "WATCH.STATS"}).VSsMap
+	if n, err := strconv.Atoi(stats["subscriptions"]); err != nil || n < 1 || stats["keys"] == "0" {
+		t.Fatalf("expected the subscription to be counted, got %v", stats)
+	}
+
+	// The subscription is gone once unwatched.
+	assertEqual(t, "OK", conn.Fire("UNWATCH", fp))
+	if s := listed(t, client.Fire(&wire.Command{Cmd: "WATCH.LIST"}), fp); s != nil {
+		t.Fatalf("expected the subscription to be removed, got %v", s)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2022-present, DiceDB contributors
+// All rights reserved. Licensed under the BSD 3-Clause License. See LICENSE file in the project root for full license information.
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dicedb-go"
+	"github.com/dicedb/dicedb-go/wire"
+)
+
+const shutdownTestPort = 8741
+
+// buildServer builds the dicedb binary into a temporary directory.
+func buildServer(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "dicedb")
+	out, err := exec.Command("go", "build", "-o", bin, "github.com/dicedb/dice").CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to build the server: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// startServer starts the server with the WAL enabled and
+// waits until it accepts connections.
+func startServer(t *testing.T, bin, walDir string) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command(bin,
+		"--port", strconv.Itoa(shutdownTestPort),
+		"--num-shards", "2",
+		"--enable-wal",
+		"--wal-dir", walDir,
+		"--log-level", "warn",
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start the server: %v", err)
+	}
+
+	addr := fmt.Sprintf("localhost:%d", shutdownTestPort)
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			return cmd
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	_ = cmd.Process.Kill()
+	t.Fatalf("server did not start listening on %s", addr)
+	return nil
+}
+
+// stopServer sends SIGTERM to the server and waits for it to exit.
+func stopServer(t *testing.T, cmd *exec.Cmd) {
+	t.Helper()
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to send SIGTERM: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("server exited with error: %v", err)
+		}
+	case <-time.After(30 * time.Second):
+		_ = cmd.Process.Kill()
+		t.Fatalf("server did not exit after SIGTERM")
+	}
+}
+
+func TestGracefulShutdownUnderLoad(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the graceful shutdown test in short mode")
+	}
+
+	bin := buildServer(t)
+	walDir := t.TempDir()
+	srv := startServer(t, bin, walDir)
+
+	const numWriters = 8
+	var (
+		mu    sync.Mutex
+		acked = map[string]string{}
+		wg    sync.WaitGroup
+		stop  = make(chan struct{})
+	)
+
+	for w := 0; w < numWriters; w++ {
+		client, err := dicedb.NewClient("localhost", shutdownTestPort)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		defer client.Close()
+
+		wg.Add(1)
+		go func(w int, client *dicedb.Client) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				k, v := fmt.Sprintf("k:%d:%d", w, i), fmt.Sprintf("v:%d", i)
+				res := client.Fire(&wire.Command{Cmd: "SET", Args: []string{k, v}})
+				if res.Err != "" || res.GetVStr() != "OK" {
+					// The server is going away, nothing after this is acknowledged.
+					return
+				}
+				mu.Lock()
+				acked[k] = v
+				mu.Unlock()
+			}
+		}(w, client)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	stopServer(t, srv)
+	close(stop)
+	wg.Wait()
+
+	if len(acked) == 0 {
+		t.Fatalf("no writes were acknowledged before the shutdown")
+	}
+
+	srv = startServer(t, bin, walDir)
+	defer stopServer(t, srv)
+
+	client, err := dicedb.NewClient("localhost", shutdownTestPort)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	lost := 0
+	for k, v := range acked {
+		if res := client.Fire(&wire.Command{Cmd: "GET", Args: []string{k}}); res.GetVStr() != v {
+			lost++
+		}
+	}
+	if lost > 0 {
+		t.Errorf("%d of %d acknowledged writes were lost", lost, len(acked))
+	}
+}